@@ -0,0 +1,1165 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath es el archivo de configuración que se usa si no se indica -config ni GOSOL_CONFIG.
+const DefaultPath = "config.yaml"
+
+// Config agrupa toda la configuración de la aplicación. Se construye una sola vez
+// en main y se inyecta en cada componente, de forma que no haya estado global.
+type Config struct {
+	Solana       SolanaConfig       `yaml:"solana"`
+	RPC          RPCConfig          `yaml:"rpc"`
+	Report       ReportConfig       `yaml:"report"`
+	Scoring      ScoringConfig      `yaml:"scoring"`
+	PumpFun      PumpFunConfig      `yaml:"pumpfun"`
+	DEX          DEXConfig          `yaml:"dex"`
+	Transactions TransactionsConfig `yaml:"transactions"`
+	Tracking     TrackingConfig     `yaml:"tracking"`
+	Backfill     BackfillConfig     `yaml:"backfill"`
+	Dedupe       DedupeConfig       `yaml:"dedupe"`
+	Ingest       IngestConfig       `yaml:"ingest"`
+	Rescan       RescanConfig       `yaml:"rescan"`
+	Storage      StorageConfig      `yaml:"storage"`
+	Telegram     TelegramConfig     `yaml:"telegram"`
+}
+
+type SolanaConfig struct {
+	WebsocketURL string `yaml:"websocket_url"`
+	APIKey       string `yaml:"api_key"`
+	RayFeePubkey string `yaml:"ray_fee_pubkey"`
+	// Commitment es el nivel por defecto de las suscripciones del websocket.
+	Commitment string          `yaml:"commitment"`
+	Reconnect  ReconnectConfig `yaml:"reconnect"`
+}
+
+// ReconnectConfig controla la supervisión de la conexión del websocket.
+type ReconnectConfig struct {
+	BackoffBase time.Duration `yaml:"backoff_base"`
+	BackoffMax  time.Duration `yaml:"backoff_max"`
+	// DegradedAfter es cuánto sin mensajes se tolera antes de marcar la conexión como degradada.
+	// También es el mínimo para dar por sordo un stream de logs que calla mientras el heartbeat
+	// sigue llegando (además debe llevar 10 veces su intervalo habitual sin mensajes).
+	DegradedAfter time.Duration `yaml:"degraded_after"`
+	// StaleAfter es cuánto sin ningún mensaje de la conexión (heartbeat incluido) se tolera
+	// antes de reconectar (0 = no se vigila y no se suscribe al heartbeat de slots). Un stream
+	// sordo con la conexión viva solo la marca degradada.
+	StaleAfter time.Duration `yaml:"stale_after"`
+}
+
+// Niveles de commitment de Solana.
+const (
+	CommitmentProcessed = "processed"
+	CommitmentConfirmed = "confirmed"
+	CommitmentFinalized = "finalized"
+	// CommitmentDropped no es un nivel de Solana: marca las detecciones cuya transacción no
+	// llegó al nivel siguiente (p. ej. se perdió en un fork).
+	CommitmentDropped = "dropped"
+)
+
+// RPCConfig define el pool de endpoints HTTP usados para las consultas RPC.
+// Si no se configura ningún endpoint se usa Helius con solana.api_key, como antes.
+type RPCConfig struct {
+	Timeout   time.Duration       `yaml:"timeout"`
+	Cooldown  time.Duration       `yaml:"cooldown"`
+	Endpoints []RPCEndpointConfig `yaml:"endpoints"`
+}
+
+// Estilos de autenticación soportados por un endpoint RPC.
+const (
+	AuthNone   = "none"
+	AuthQuery  = "query"
+	AuthHeader = "header"
+)
+
+type RPCEndpointConfig struct {
+	Name    string            `yaml:"name"`
+	URL     string            `yaml:"url"`
+	Weight  int               `yaml:"weight"`
+	Timeout time.Duration     `yaml:"timeout"`
+	Headers map[string]string `yaml:"headers"`
+	// Auth es "none", "query" (la key va en el parámetro AuthParam, por defecto "api-key")
+	// o "header" (la key va en el header AuthParam, por defecto "Authorization: Bearer <key>").
+	Auth      string `yaml:"auth"`
+	AuthParam string `yaml:"auth_param"`
+	// APIKey usa solana.api_key si queda vacío.
+	APIKey string `yaml:"api_key"`
+}
+
+// DefaultRPCEndpoint es el endpoint que se usaba antes de que el RPC fuera configurable.
+const DefaultRPCEndpoint = "https://mainnet.helius-rpc.com/"
+
+type ReportConfig struct {
+	APIBaseURL string `yaml:"api_base_url"`
+
+	// Providers son las fuentes de reportes y Strategy cómo se combinan:
+	// "first_success" (en orden), "merge_all" o "quorum" (al menos Quorum fuentes).
+	// Sin providers se usa la API HTTP de api_base_url.
+	Providers []ReportProviderConfig `yaml:"providers"`
+	Strategy  string                 `yaml:"strategy"`
+	Quorum    int                    `yaml:"quorum"`
+
+	// Workers es la cantidad de reportes que se piden en paralelo y QueueSize cuántos
+	// pueden quedar esperando; si la cola se llena los pedidos nuevos se descartan.
+	Workers   int `yaml:"workers"`
+	QueueSize int `yaml:"queue_size"`
+
+	MaxRetries  int           `yaml:"max_retries"`
+	BackoffBase time.Duration `yaml:"backoff_base"`
+	BackoffMax  time.Duration `yaml:"backoff_max"`
+
+	// RateLimit se comparte entre todos los pedidos; EndpointLimits agrega un límite
+	// adicional por host.
+	RateLimit      RateLimitConfig            `yaml:"rate_limit"`
+	EndpointLimits map[string]RateLimitConfig `yaml:"endpoint_limits"`
+}
+
+// Tipos de proveedor de reportes.
+const (
+	ProviderHTTP    = "http"
+	ProviderFixture = "fixture"
+	// ProviderOnChain arma el reporte leyendo directamente las cuentas del mint por RPC.
+	ProviderOnChain = "onchain"
+)
+
+// Estrategias para combinar proveedores de reportes.
+const (
+	StrategyFirstSuccess = "first_success"
+	StrategyMergeAll     = "merge_all"
+	StrategyQuorum       = "quorum"
+)
+
+type ReportProviderConfig struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	// BaseURL es la URL de la API (type http); por defecto report.api_base_url.
+	BaseURL string `yaml:"base_url"`
+	// Path es el directorio con archivos <mint>.json (type fixture).
+	Path string `yaml:"path"`
+}
+
+type RateLimitConfig struct {
+	RPS   float64 `yaml:"rps"`
+	Burst int     `yaml:"burst"`
+}
+
+// ScoringConfig define cómo se puntúa un reporte y qué se hace con él (ver paquete rules).
+// El score final es ReportWeight × el score del proveedor más el de cada regla que aplique;
+// el nivel que le corresponde a ese score da el veredicto y el color.
+type ScoringConfig struct {
+	// ReportWeight multiplica el score que trae el proveedor (0 = usar solo las reglas).
+	ReportWeight float64        `yaml:"report_weight"`
+	Rules        []ScoringRule  `yaml:"rules"`
+	Levels       []ScoringLevel `yaml:"levels"`
+}
+
+// Veredictos posibles para un reporte.
+const (
+	// VerdictIgnore descarta el token (no se muestra ni se sigue).
+	VerdictIgnore = "ignore"
+	// VerdictWatch lo muestra en la tabla.
+	VerdictWatch = "watch"
+	// VerdictAlert lo muestra y lo destaca como oportunidad.
+	VerdictAlert = "alert"
+)
+
+// ScoringRule suma Score cuando se cumplen todas las condiciones de When. Si define un
+// Verdict, la primera regla que aplique con veredicto reemplaza al del nivel.
+type ScoringRule struct {
+	Name    string    `yaml:"name"`
+	When    RuleMatch `yaml:"when"`
+	Score   int       `yaml:"score"`
+	Verdict string    `yaml:"verdict"`
+}
+
+// RuleMatch son las condiciones de una regla; las que no se definen no se evalúan.
+type RuleMatch struct {
+	// Risks aplica si el reporte tiene alguno de estos riesgos (sin distinguir mayúsculas).
+	Risks []string `yaml:"risks"`
+	// RiskLevel aplica si algún riesgo tiene este nivel (danger, warn...).
+	RiskLevel       string `yaml:"risk_level"`
+	Rugged          *bool  `yaml:"rugged"`
+	FreezeAuthority *bool  `yaml:"freeze_authority"`
+	MintAuthority   *bool  `yaml:"mint_authority"`
+	// LiquidityBelow aplica si TotalMarketLiquidity (USD) es menor.
+	LiquidityBelow *float64 `yaml:"liquidity_below"`
+	// TopHolderPctAbove y Top10PctAbove comparan el porcentaje del mayor holder y de los 10 mayores.
+	TopHolderPctAbove *float64 `yaml:"top_holder_pct_above"`
+	Top10PctAbove     *float64 `yaml:"top10_pct_above"`
+	// Insiders aplica si hay (o no hay) holders marcados como insider.
+	Insiders *bool `yaml:"insiders"`
+	// SourcesAbove y SourceWeightAbove comparan cuántos canales de Telegram mencionaron el
+	// mint y la suma de sus pesos (telegram.channels[].weight).
+	SourcesAbove      *int     `yaml:"sources_above"`
+	SourceWeightAbove *float64 `yaml:"source_weight_above"`
+}
+
+// ScoringLevel cubre los scores hasta MaxScore inclusive. Van de menor a mayor y el último
+// puede dejar MaxScore en 0 para no tener tope.
+type ScoringLevel struct {
+	MaxScore int    `yaml:"max_score"`
+	Verdict  string `yaml:"verdict"`
+	Color    string `yaml:"color"`
+}
+
+// PumpFunConfig controla el seguimiento de lanzamientos en pump.fun desde los logs del programa.
+type PumpFunConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	ProgramID string `yaml:"program_id"`
+	// ReportOnCreate pide el reporte apenas se crea el token; si no, recién cuando completa la curva.
+	ReportOnCreate bool `yaml:"report_on_create"`
+	// TrackFor es cuánto se sigue la curva de un token que no se completa.
+	TrackFor time.Duration `yaml:"track_for"`
+}
+
+// DEXConfig elige los DEX cuyos pools nuevos se detectan. Los nombres válidos son los
+// de los detectores del paquete dex (raydium-amm-v4, raydium-cpmm, raydium-clmm,
+// meteora-dlmm, meteora-dynamic, orca-whirlpool).
+type DEXConfig struct {
+	Enabled []string `yaml:"enabled"`
+}
+
+// TransactionsConfig controla cómo se piden por RPC las transacciones que pueden crear un
+// pool (ver monitor.TransactionManager).
+type TransactionsConfig struct {
+	// Workers es la cantidad de transacciones que se piden en paralelo y QueueSize cuántas
+	// pueden quedar esperando; con la cola llena se descartan las nuevas.
+	Workers   int `yaml:"workers"`
+	QueueSize int `yaml:"queue_size"`
+	// Timeout es el tiempo máximo por transacción, reintentos incluidos.
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxRetries son los reintentos de los errores transitorios (rate limit, red, o la
+	// transacción todavía no disponible en el nodo).
+	MaxRetries  int           `yaml:"max_retries"`
+	BackoffBase time.Duration `yaml:"backoff_base"`
+	BackoffMax  time.Duration `yaml:"backoff_max"`
+	// Priority son los DEX cuyas transacciones se piden antes que las demás.
+	Priority []string `yaml:"priority"`
+}
+
+// TrackingConfig controla el seguimiento de cada detección por los niveles de commitment
+// (ver monitor.CommitmentTracker).
+type TrackingConfig struct {
+	// Detect es el commitment de las suscripciones a los logs de los DEX: processed detecta
+	// antes, a cambio de ver transacciones que después se pierden en un fork.
+	Detect string `yaml:"detect"`
+	// DropAfter es cuánto se espera que una detección pase al nivel siguiente antes de
+	// consultar su estado y, si el nodo no la conoce, marcarla como dropped.
+	DropAfter time.Duration `yaml:"drop_after"`
+	// Alerts son los niveles que generan un aviso: processed, confirmed, finalized o dropped.
+	Alerts []string `yaml:"alerts"`
+}
+
+// BackfillConfig controla la recuperación de las transacciones perdidas durante una caída
+// del websocket (ver monitor.Backfiller).
+type BackfillConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// PageSize es el límite de cada llamada a getSignaturesForAddress (máximo 1000).
+	PageSize int `yaml:"page_size"`
+	// MaxSignatures es el máximo de firmas a recuperar por cuenta; en los programas de los
+	// DEX cada swap es una firma, así que una caída larga no se recupera entera.
+	MaxSignatures int    `yaml:"max_signatures"`
+	Commitment    string `yaml:"commitment"`
+}
+
+// DedupeConfig dimensiona la deduplicación compartida de firmas y mints (ver monitor.Deduper).
+type DedupeConfig struct {
+	Signatures DedupeCacheConfig `yaml:"signatures"`
+	Mints      DedupeCacheConfig `yaml:"mints"`
+}
+
+// DedupeCacheConfig es un LRU con vencimiento: se recuerdan hasta Size claves por TTL.
+type DedupeCacheConfig struct {
+	Size int           `yaml:"size"`
+	TTL  time.Duration `yaml:"ttl"`
+}
+
+// Políticas de desborde de la cola de ingesta.
+const (
+	OverflowDropOldest = "drop_oldest"
+	OverflowBlock      = "block"
+	OverflowSpill      = "spill"
+)
+
+// IngestConfig dimensiona las colas entre el websocket y el procesamiento de los logs.
+type IngestConfig struct {
+	QueueSize int `yaml:"queue_size"`
+	// Overflow es qué hacer con la cola llena: drop_oldest, block o spill (a disco, en SpillDir).
+	Overflow string `yaml:"overflow"`
+	SpillDir string `yaml:"spill_dir"`
+}
+
+// RescanConfig define cada cuánto se vuelve a pedir el reporte de un mint según su edad.
+// Las etapas se recorren en orden: se usa la primera cuyo Until supera la edad del mint,
+// y cuando el mint supera la última deja de re-escanearse.
+type RescanConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	Stages        []RescanStage `yaml:"stages"`
+	Jitter        float64       `yaml:"jitter"`
+	MaxConcurrent int           `yaml:"max_concurrent"`
+}
+
+type RescanStage struct {
+	Until time.Duration `yaml:"until"`
+	Every time.Duration `yaml:"every"`
+}
+
+// StorageConfig controla la persistencia del estado entre sesiones.
+type StorageConfig struct {
+	Enabled         bool          `yaml:"enabled"`
+	Path            string        `yaml:"path"`
+	RetentionDays   int           `yaml:"retention_days"`
+	CompactInterval time.Duration `yaml:"compact_interval"`
+}
+
+// Retention devuelve cuánto tiempo se conservan los datos (0 = sin límite).
+func (c StorageConfig) Retention() time.Duration {
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+type TelegramConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	APIID           int    `yaml:"api_id"`
+	APIHash         string `yaml:"api_hash"`
+	ChannelID       int64  `yaml:"channel_id"`
+	PlatformKeyword string `yaml:"platform_keyword"`
+	// Parser es el nombre del parser (de Parsers o uno incluido) de los canales que no
+	// definen el suyo.
+	Parser  string                 `yaml:"parser"`
+	Parsers []TelegramParserConfig `yaml:"parsers"`
+	// Channels son los canales y grupos a seguir; ChannelID agrega uno más con los valores
+	// por defecto (ver Sources).
+	Channels []TelegramChannelConfig `yaml:"channels"`
+	// Phone es el número de la cuenta; si está vacío `gosol telegram login` lo pregunta.
+	Phone string `yaml:"phone"`
+	// SessionFile guarda la sesión iniciada con `gosol telegram login`, cifrada con SessionKey.
+	SessionFile string `yaml:"session_file"`
+	SessionKey  string `yaml:"session_key"`
+	// Bot publica y responde con un bot de la Bot API; no depende de Enabled.
+	Bot TelegramBotConfig `yaml:"bot"`
+}
+
+// TelegramBotConfig es el bot que publica una tarjeta por cada token que pasa los filtros y
+// la edita a medida que los re-escaneos cambian el score.
+type TelegramBotConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Token   string `yaml:"token"`
+	APIURL  string `yaml:"api_url"`
+	// ChatIDs son los chats donde se publican las tarjetas (ids de la Bot API).
+	ChatIDs []int64 `yaml:"chat_ids"`
+	// Verdicts son los veredictos que publican una tarjeta nueva; las ya publicadas se
+	// editan con cualquier veredicto.
+	Verdicts []string `yaml:"verdicts"`
+	// RateLimit se aplica por chat (Telegram permite unos 20 mensajes por minuto en un grupo).
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	// Commands habilita los comandos (/scan, /list, /status...) para los usuarios de AllowedUsers.
+	Commands     bool    `yaml:"commands"`
+	AllowedUsers []int64 `yaml:"allowed_users"`
+}
+
+// TelegramChannelConfig es un canal o grupo del que se leen alertas.
+type TelegramChannelConfig struct {
+	// ID acepta el id del canal o el de la Bot API (-100...).
+	ID     int64  `yaml:"id"`
+	Label  string `yaml:"label"`
+	Parser string `yaml:"parser"`
+	// Weight es cuánto se confía en el canal al sumar menciones (0 = 1).
+	Weight float64 `yaml:"weight"`
+}
+
+// PeerID convierte un id en formato de la Bot API (-100... para canales y supergrupos,
+// negativo para grupos) al id del canal o chat; los demás ids quedan igual.
+func PeerID(id int64) int64 {
+	const botAPIChannelOffset = 1_000_000_000_000
+	if id < -botAPIChannelOffset {
+		return -id - botAPIChannelOffset
+	}
+	if id < 0 {
+		return -id
+	}
+	return id
+}
+
+// Sources devuelve los canales a seguir con los valores por defecto completos: Channels más
+// ChannelID si está definido.
+func (c TelegramConfig) Sources() []TelegramChannelConfig {
+	channels := slices.Clone(c.Channels)
+	if c.ChannelID != 0 {
+		channels = append(channels, TelegramChannelConfig{ID: c.ChannelID})
+	}
+	for i, ch := range channels {
+		if ch.Label == "" {
+			channels[i].Label = strconv.FormatInt(ch.ID, 10)
+		}
+		if ch.Parser == "" {
+			channels[i].Parser = c.Parser
+		}
+		if ch.Weight == 0 {
+			channels[i].Weight = 1
+		}
+	}
+	return channels
+}
+
+// Tipos de parser de mensajes de Telegram.
+const (
+	// ParserKindRegex extrae los datos con los grupos con nombre de una o más expresiones
+	// regulares: mint, platform, pool y cualquier otro como métrica.
+	ParserKindRegex = "regex"
+	// ParserKindBase58 toma como mint la primera dirección de Solana válida del mensaje.
+	ParserKindBase58 = "base58"
+)
+
+// Parsers incluidos, disponibles sin definirlos en telegram.parsers.
+const (
+	// ParserDefault lee el formato "Platform: X" ... "Base: <mint>".
+	ParserDefault = "default"
+	ParserBase58  = "base58"
+)
+
+// TelegramParserConfig define cómo extraer el token de los mensajes de un canal.
+type TelegramParserConfig struct {
+	Name string `yaml:"name"`
+	Kind string `yaml:"kind"`
+	// Patterns se aplican todos sobre el mensaje (solo regex); el grupo mint es obligatorio.
+	Patterns []string `yaml:"patterns"`
+}
+
+// ParserDefinitions devuelve los parsers incluidos seguidos de los configurados.
+func (c TelegramConfig) ParserDefinitions() []TelegramParserConfig {
+	return append([]TelegramParserConfig{
+		{Name: ParserDefault, Kind: ParserKindRegex, Patterns: []string{`Platform: (?P<platform>[^\n]+)`, `Base: (?P<mint>\S+)`}},
+		{Name: ParserBase58, Kind: ParserKindBase58},
+	}, c.Parsers...)
+}
+
+// minSessionKeyLength es el largo mínimo de la clave que cifra la sesión de Telegram.
+const minSessionKeyLength = 16
+
+// WebsocketEndpoint devuelve la URL completa del websocket (la api key va concatenada al final).
+func (c SolanaConfig) WebsocketEndpoint() string {
+	return c.WebsocketURL + c.APIKey
+}
+
+// Default devuelve la configuración base sobre la que se aplican archivo, entorno y flags.
+func Default() *Config {
+	return &Config{
+		Solana: SolanaConfig{
+			Commitment: CommitmentConfirmed,
+			Reconnect: ReconnectConfig{
+				BackoffBase:   1 * time.Second,
+				BackoffMax:    30 * time.Second,
+				DegradedAfter: 10 * time.Second,
+				StaleAfter:    30 * time.Second,
+			},
+		},
+		RPC: RPCConfig{
+			Timeout:  10 * time.Second,
+			Cooldown: 30 * time.Second,
+		},
+		Report: ReportConfig{
+			Strategy:    StrategyFirstSuccess,
+			Workers:     4,
+			QueueSize:   100,
+			MaxRetries:  3,
+			BackoffBase: 500 * time.Millisecond,
+			BackoffMax:  30 * time.Second,
+			RateLimit:   RateLimitConfig{RPS: 2, Burst: 4},
+		},
+		// mismos umbrales que antes: colores a 2000/3000/4000 y descarte por encima de 8000
+		Scoring: ScoringConfig{
+			ReportWeight: 1,
+			Levels: []ScoringLevel{
+				{MaxScore: 2000, Verdict: VerdictAlert, Color: "🟢"},
+				{MaxScore: 3000, Verdict: VerdictWatch, Color: "🟡"},
+				{MaxScore: 4000, Verdict: VerdictWatch, Color: "🟠"},
+				{MaxScore: 8000, Verdict: VerdictWatch, Color: "🔴"},
+				{Verdict: VerdictIgnore, Color: "💩"},
+			},
+		},
+		PumpFun: PumpFunConfig{
+			ProgramID: "6EF8rrecthR5Dkzon8Nwu5hjzD3a1GwWHMAx3drFkjAu",
+			TrackFor:  24 * time.Hour,
+		},
+		DEX: DEXConfig{
+			Enabled: []string{"raydium-amm-v4"},
+		},
+		Transactions: TransactionsConfig{
+			Workers:     8,
+			QueueSize:   1000,
+			Timeout:     30 * time.Second,
+			MaxRetries:  3,
+			BackoffBase: 250 * time.Millisecond,
+			BackoffMax:  5 * time.Second,
+			Priority:    []string{"raydium-amm-v4", "raydium-cpmm"},
+		},
+		Tracking: TrackingConfig{
+			Detect:    CommitmentProcessed,
+			DropAfter: 90 * time.Second,
+			Alerts:    []string{CommitmentConfirmed, CommitmentDropped},
+		},
+		Backfill: BackfillConfig{
+			Enabled:       true,
+			PageSize:      1000,
+			MaxSignatures: 5000,
+			Commitment:    CommitmentConfirmed,
+		},
+		Dedupe: DedupeConfig{
+			Signatures: DedupeCacheConfig{Size: 50000, TTL: 30 * time.Minute},
+			Mints:      DedupeCacheConfig{Size: 10000, TTL: 10 * time.Minute},
+		},
+		Ingest: IngestConfig{
+			QueueSize: 10000,
+			Overflow:  OverflowDropOldest,
+			SpillDir:  "gosol-spill",
+		},
+		Rescan: RescanConfig{
+			Enabled: true,
+			Stages: []RescanStage{
+				{Until: 5 * time.Minute, Every: 30 * time.Second},
+				{Until: 2 * time.Hour, Every: 5 * time.Minute},
+			},
+			Jitter:        0.2,
+			MaxConcurrent: 4,
+		},
+		Storage: StorageConfig{
+			Enabled:         true,
+			Path:            "gosol.db",
+			RetentionDays:   7,
+			CompactInterval: 6 * time.Hour,
+		},
+		Telegram: TelegramConfig{
+			Enabled:         true,
+			PlatformKeyword: "Raydium",
+			Parser:          ParserDefault,
+			SessionFile:     "gosol-telegram.session",
+			Bot: TelegramBotConfig{
+				APIURL:    "https://api.telegram.org",
+				Verdicts:  []string{VerdictAlert},
+				RateLimit: RateLimitConfig{RPS: 0.3, Burst: 3},
+			},
+		},
+	}
+}
+
+// Load construye la configuración en este orden de prioridad (de menor a mayor):
+// valores por defecto, archivo YAML, variables de entorno (incluido .env) y flags de la línea de comandos.
+// Todos los problemas encontrados se devuelven juntos en un único error.
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("gosol", flag.ContinueOnError)
+	configPath := fs.String("config", "", "ruta al archivo de configuración YAML")
+	overrides := registerFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+
+	path, explicit := *configPath, *configPath != ""
+	if !explicit {
+		if envPath, ok := os.LookupEnv("GOSOL_CONFIG"); ok {
+			path, explicit = envPath, true
+		} else {
+			path = DefaultPath
+		}
+	}
+	if err := cfg.loadFile(path, explicit); err != nil {
+		return nil, err
+	}
+
+	// .env es opcional: si no existe se usan solo las variables del proceso
+	_ = godotenv.Load()
+
+	var errs []error
+	errs = append(errs, cfg.applyEnv()...)
+
+	fs.Visit(func(f *flag.Flag) {
+		if b, ok := overrides[f.Name]; ok {
+			if err := b.apply(cfg, f.Value.String()); err != nil {
+				errs = append(errs, fmt.Errorf("flag -%s: %w", f.Name, err))
+			}
+		}
+	})
+
+	cfg.applyDefaults()
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) loadFile(path string, required bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return nil
+		}
+		return fmt.Errorf("opening config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyDefaults completa los valores que dependen de otras secciones.
+func (cfg *Config) applyDefaults() {
+	if len(cfg.Report.Providers) == 0 {
+		cfg.Report.Providers = []ReportProviderConfig{{Name: "rugcheck", Type: ProviderHTTP}}
+	}
+	for i := range cfg.Report.Providers {
+		p := &cfg.Report.Providers[i]
+		if p.Name == "" {
+			p.Name = p.Type
+		}
+		if p.Type == ProviderHTTP && p.BaseURL == "" {
+			p.BaseURL = cfg.Report.APIBaseURL
+		}
+	}
+
+	if len(cfg.RPC.Endpoints) == 0 {
+		cfg.RPC.Endpoints = []RPCEndpointConfig{{Name: "helius", URL: DefaultRPCEndpoint, Auth: AuthQuery}}
+	}
+	for i := range cfg.RPC.Endpoints {
+		ep := &cfg.RPC.Endpoints[i]
+		if ep.Name == "" {
+			ep.Name = fmt.Sprintf("rpc-%d", i)
+		}
+		if ep.Weight == 0 {
+			ep.Weight = 1
+		}
+		if ep.Timeout == 0 {
+			ep.Timeout = cfg.RPC.Timeout
+		}
+		if ep.Auth == "" {
+			ep.Auth = AuthNone
+		}
+		if ep.Auth != AuthNone && ep.APIKey == "" {
+			ep.APIKey = cfg.Solana.APIKey
+		}
+	}
+}
+
+// binding asocia una variable de entorno o un flag con el campo que sobrescribe.
+type binding struct {
+	name  string
+	usage string
+	apply func(cfg *Config, value string) error
+}
+
+func envBindings() []binding {
+	return []binding{
+		{"WEBSOCKET_URL", "", func(c *Config, v string) error { c.Solana.WebsocketURL = v; return nil }},
+		{"API_KEY", "", func(c *Config, v string) error { c.Solana.APIKey = v; return nil }},
+		{"RAY_FEE_PUBKEY", "", func(c *Config, v string) error { c.Solana.RayFeePubkey = v; return nil }},
+		{"API_BASE_URL", "", func(c *Config, v string) error { c.Report.APIBaseURL = v; return nil }},
+		{"RPC_URL", "", func(c *Config, v string) error { return c.setSingleRPC(v) }},
+		{"RESCAN_ENABLED", "", func(c *Config, v string) error { return parseBool(v, &c.Rescan.Enabled) }},
+		{"PUMPFUN_ENABLED", "", func(c *Config, v string) error { return parseBool(v, &c.PumpFun.Enabled) }},
+		{"STORAGE_PATH", "", func(c *Config, v string) error { c.Storage.Path = v; return nil }},
+		{"STORAGE_RETENTION_DAYS", "", func(c *Config, v string) error { return parseInt(v, &c.Storage.RetentionDays) }},
+		{"TELEGRAM_ENABLED", "", func(c *Config, v string) error { return parseBool(v, &c.Telegram.Enabled) }},
+		{"API_ID", "", func(c *Config, v string) error { return parseInt(v, &c.Telegram.APIID) }},
+		{"API_HASH", "", func(c *Config, v string) error { c.Telegram.APIHash = v; return nil }},
+		{"TELEGRAM_CHANNEL_ID", "", func(c *Config, v string) error { return parseInt64(v, &c.Telegram.ChannelID) }},
+		{"PLATFORM_KEYWORD", "", func(c *Config, v string) error { c.Telegram.PlatformKeyword = v; return nil }},
+		{"TELEGRAM_PHONE", "", func(c *Config, v string) error { c.Telegram.Phone = v; return nil }},
+		{"TELEGRAM_SESSION_FILE", "", func(c *Config, v string) error { c.Telegram.SessionFile = v; return nil }},
+		{"TELEGRAM_SESSION_KEY", "", func(c *Config, v string) error { c.Telegram.SessionKey = v; return nil }},
+		{"TELEGRAM_BOT_ENABLED", "", func(c *Config, v string) error { return parseBool(v, &c.Telegram.Bot.Enabled) }},
+		{"TELEGRAM_BOT_TOKEN", "", func(c *Config, v string) error { c.Telegram.Bot.Token = v; return nil }},
+	}
+}
+
+func flagBindings() []binding {
+	return []binding{
+		{"websocket-url", "URL del websocket de Solana", func(c *Config, v string) error { c.Solana.WebsocketURL = v; return nil }},
+		{"api-key", "API key del proveedor RPC", func(c *Config, v string) error { c.Solana.APIKey = v; return nil }},
+		{"ray-fee-pubkey", "cuenta a monitorear en los logs", func(c *Config, v string) error { c.Solana.RayFeePubkey = v; return nil }},
+		{"api-base-url", "URL base de la API de reportes", func(c *Config, v string) error { c.Report.APIBaseURL = v; return nil }},
+		{"rpc-url", "usar un único endpoint RPC sin autenticación (ej. un validador local)", func(c *Config, v string) error { return c.setSingleRPC(v) }},
+		{"rescan", "re-escanear periódicamente los mints detectados (true/false)", func(c *Config, v string) error { return parseBool(v, &c.Rescan.Enabled) }},
+		{"pumpfun", "seguir los lanzamientos de pump.fun (true/false)", func(c *Config, v string) error { return parseBool(v, &c.PumpFun.Enabled) }},
+		{"storage", "habilitar la persistencia del estado (true/false)", func(c *Config, v string) error { return parseBool(v, &c.Storage.Enabled) }},
+		{"storage-path", "archivo donde se persiste el estado", func(c *Config, v string) error { c.Storage.Path = v; return nil }},
+		{"telegram", "habilitar el adaptador de Telegram (true/false)", func(c *Config, v string) error { return parseBool(v, &c.Telegram.Enabled) }},
+		{"telegram-bot", "habilitar el bot de Telegram (true/false)", func(c *Config, v string) error { return parseBool(v, &c.Telegram.Bot.Enabled) }},
+		{"platform-keyword", "plataforma a filtrar en los mensajes de Telegram", func(c *Config, v string) error { c.Telegram.PlatformKeyword = v; return nil }},
+	}
+}
+
+func (cfg *Config) applyEnv() []error {
+	var errs []error
+	for _, b := range envBindings() {
+		value, ok := os.LookupEnv(b.name)
+		if !ok || value == "" {
+			continue
+		}
+		if err := b.apply(cfg, value); err != nil {
+			errs = append(errs, fmt.Errorf("env %s: %w", b.name, err))
+		}
+	}
+	return errs
+}
+
+// registerFlags declara los flags de sobrescritura. Se aplican recién después de
+// cargar archivo y entorno, y solo los que realmente se pasaron (ver fs.Visit en Load).
+func registerFlags(fs *flag.FlagSet) map[string]binding {
+	bindings := make(map[string]binding)
+	for _, b := range flagBindings() {
+		fs.String(b.name, "", b.usage)
+		bindings[b.name] = b
+	}
+	return bindings
+}
+
+// setSingleRPC reemplaza el pool por un único endpoint, útil para apuntar a un validador local.
+func (cfg *Config) setSingleRPC(url string) error {
+	cfg.RPC.Endpoints = []RPCEndpointConfig{{Name: "rpc", URL: url, Auth: AuthNone}}
+	return nil
+}
+
+func parseBool(v string, dst *bool) error {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q", v)
+	}
+	*dst = b
+	return nil
+}
+
+func parseInt(v string, dst *int) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("invalid integer %q", v)
+	}
+	*dst = n
+	return nil
+}
+
+func parseInt64(v string, dst *int64) error {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %q", v)
+	}
+	*dst = n
+	return nil
+}
+
+// Validate revisa la configuración completa y devuelve todos los errores juntos.
+func (cfg *Config) Validate() error {
+	var errs []error
+	required := func(name, value string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", name))
+		}
+	}
+
+	required("solana.websocket_url", cfg.Solana.WebsocketURL)
+	required("solana.api_key", cfg.Solana.APIKey)
+	required("solana.ray_fee_pubkey", cfg.Solana.RayFeePubkey)
+
+	if cfg.Solana.WebsocketURL != "" {
+		if err := validateURL(cfg.Solana.WebsocketURL, "ws", "wss"); err != nil {
+			errs = append(errs, fmt.Errorf("solana.websocket_url: %w", err))
+		}
+	}
+	if cfg.Solana.RayFeePubkey != "" {
+		if _, err := solana.PublicKeyFromBase58(cfg.Solana.RayFeePubkey); err != nil {
+			errs = append(errs, fmt.Errorf("solana.ray_fee_pubkey: invalid public key: %w", err))
+		}
+	}
+	switch cfg.Solana.Commitment {
+	case CommitmentProcessed, CommitmentConfirmed, CommitmentFinalized:
+	default:
+		errs = append(errs, fmt.Errorf("solana.commitment: unknown commitment %q", cfg.Solana.Commitment))
+	}
+	if rc := cfg.Solana.Reconnect; rc.BackoffBase <= 0 || rc.BackoffMax < rc.BackoffBase {
+		errs = append(errs, errors.New("solana.reconnect.backoff_base must be positive and not greater than solana.reconnect.backoff_max"))
+	}
+	if rc := cfg.Solana.Reconnect; rc.StaleAfter > 0 && (rc.DegradedAfter <= 0 || rc.DegradedAfter >= rc.StaleAfter) {
+		errs = append(errs, errors.New("solana.reconnect.degraded_after must be positive and less than solana.reconnect.stale_after"))
+	}
+	if cfg.Report.APIBaseURL != "" {
+		if err := validateURL(cfg.Report.APIBaseURL, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("report.api_base_url: %w", err))
+		}
+	}
+
+	for _, ep := range cfg.RPC.Endpoints {
+		prefix := fmt.Sprintf("rpc.endpoints[%s]", ep.Name)
+		if err := validateURL(ep.URL, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("%s.url: %w", prefix, err))
+		}
+		if ep.Weight < 0 {
+			errs = append(errs, fmt.Errorf("%s.weight must be positive", prefix))
+		}
+		switch ep.Auth {
+		case AuthNone:
+		case AuthQuery, AuthHeader:
+			if ep.APIKey == "" {
+				errs = append(errs, fmt.Errorf("%s: auth %q needs an api_key", prefix, ep.Auth))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%s.auth: unknown style %q (expected none, query or header)", prefix, ep.Auth))
+		}
+	}
+
+	for _, p := range cfg.Report.Providers {
+		prefix := fmt.Sprintf("report.providers[%s]", p.Name)
+		switch p.Type {
+		case ProviderHTTP:
+			if p.BaseURL == "" {
+				errs = append(errs, fmt.Errorf("report.api_base_url is required by provider %s (or set its base_url)", p.Name))
+			} else if p.BaseURL != cfg.Report.APIBaseURL {
+				if err := validateURL(p.BaseURL, "http", "https"); err != nil {
+					errs = append(errs, fmt.Errorf("%s.base_url: %w", prefix, err))
+				}
+			}
+		case ProviderFixture:
+			required(prefix+".path", p.Path)
+		case ProviderOnChain:
+		default:
+			errs = append(errs, fmt.Errorf("%s.type: unknown provider type %q", prefix, p.Type))
+		}
+	}
+	switch cfg.Report.Strategy {
+	case StrategyFirstSuccess, StrategyMergeAll:
+	case StrategyQuorum:
+		if cfg.Report.Quorum < 1 || cfg.Report.Quorum > len(cfg.Report.Providers) {
+			errs = append(errs, fmt.Errorf("report.quorum must be between 1 and the number of providers (%d)", len(cfg.Report.Providers)))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("report.strategy: unknown strategy %q", cfg.Report.Strategy))
+	}
+
+	if cfg.Report.Workers <= 0 {
+		errs = append(errs, errors.New("report.workers must be positive"))
+	}
+	if cfg.Report.QueueSize <= 0 {
+		errs = append(errs, errors.New("report.queue_size must be positive"))
+	}
+	if cfg.Report.MaxRetries < 0 {
+		errs = append(errs, errors.New("report.max_retries must not be negative"))
+	}
+	if cfg.Report.BackoffBase <= 0 || cfg.Report.BackoffMax < cfg.Report.BackoffBase {
+		errs = append(errs, errors.New("report.backoff_base must be positive and not greater than report.backoff_max"))
+	}
+	errs = append(errs, validateRateLimit("report.rate_limit", cfg.Report.RateLimit)...)
+	for host, limit := range cfg.Report.EndpointLimits {
+		errs = append(errs, validateRateLimit(fmt.Sprintf("report.endpoint_limits[%s]", host), limit)...)
+	}
+
+	errs = append(errs, validateScoring(cfg.Scoring)...)
+
+	if cfg.PumpFun.Enabled {
+		if _, err := solana.PublicKeyFromBase58(cfg.PumpFun.ProgramID); err != nil {
+			errs = append(errs, fmt.Errorf("pumpfun.program_id: invalid public key: %w", err))
+		}
+		if cfg.PumpFun.TrackFor <= 0 {
+			errs = append(errs, errors.New("pumpfun.track_for must be positive"))
+		}
+	}
+
+	seenDEX := make(map[string]bool)
+	for i, name := range cfg.DEX.Enabled {
+		if seenDEX[name] {
+			errs = append(errs, fmt.Errorf("dex.enabled[%d]: duplicated dex %q", i, name))
+		}
+		seenDEX[name] = true
+	}
+
+	if cfg.Transactions.Workers < 1 {
+		errs = append(errs, errors.New("transactions.workers must be positive"))
+	}
+	if cfg.Transactions.QueueSize < 1 {
+		errs = append(errs, errors.New("transactions.queue_size must be positive"))
+	}
+	if cfg.Transactions.Timeout <= 0 {
+		errs = append(errs, errors.New("transactions.timeout must be positive"))
+	}
+	if cfg.Transactions.MaxRetries < 0 {
+		errs = append(errs, errors.New("transactions.max_retries must not be negative"))
+	}
+	if cfg.Transactions.BackoffBase <= 0 || cfg.Transactions.BackoffBase > cfg.Transactions.BackoffMax {
+		errs = append(errs, errors.New("transactions.backoff_base must be positive and not greater than transactions.backoff_max"))
+	}
+
+	if cfg.Tracking.Detect != CommitmentProcessed && cfg.Tracking.Detect != CommitmentConfirmed {
+		errs = append(errs, fmt.Errorf("tracking.detect: must be processed or confirmed, got %q", cfg.Tracking.Detect))
+	}
+	if cfg.Tracking.DropAfter <= 0 {
+		errs = append(errs, errors.New("tracking.drop_after must be positive"))
+	}
+	seenAlert := make(map[string]bool)
+	for i, level := range cfg.Tracking.Alerts {
+		switch level {
+		case CommitmentProcessed, CommitmentConfirmed, CommitmentFinalized, CommitmentDropped:
+		default:
+			errs = append(errs, fmt.Errorf("tracking.alerts[%d]: unknown level %q", i, level))
+		}
+		if seenAlert[level] {
+			errs = append(errs, fmt.Errorf("tracking.alerts[%d]: duplicated level %q", i, level))
+		}
+		seenAlert[level] = true
+	}
+
+	if cfg.Backfill.Enabled {
+		if cfg.Backfill.PageSize < 1 || cfg.Backfill.PageSize > 1000 {
+			errs = append(errs, errors.New("backfill.page_size must be between 1 and 1000"))
+		}
+		if cfg.Backfill.MaxSignatures < 1 {
+			errs = append(errs, errors.New("backfill.max_signatures must be positive"))
+		}
+		if cfg.Backfill.Commitment != CommitmentConfirmed && cfg.Backfill.Commitment != CommitmentFinalized {
+			errs = append(errs, fmt.Errorf("backfill.commitment: must be confirmed or finalized, got %q", cfg.Backfill.Commitment))
+		}
+	}
+
+	for name, cache := range map[string]DedupeCacheConfig{"signatures": cfg.Dedupe.Signatures, "mints": cfg.Dedupe.Mints} {
+		if cache.Size < 1 || cache.TTL <= 0 {
+			errs = append(errs, fmt.Errorf("dedupe.%s: size and ttl must be positive", name))
+		}
+	}
+
+	if cfg.Ingest.QueueSize < 1 {
+		errs = append(errs, errors.New("ingest.queue_size must be positive"))
+	}
+	switch cfg.Ingest.Overflow {
+	case OverflowDropOldest, OverflowBlock:
+	case OverflowSpill:
+		if cfg.Ingest.SpillDir == "" {
+			errs = append(errs, errors.New("ingest.spill_dir is required with overflow spill"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("ingest.overflow: unknown policy %q", cfg.Ingest.Overflow))
+	}
+
+	if cfg.Rescan.Enabled {
+		var prev time.Duration
+		for i, stage := range cfg.Rescan.Stages {
+			if stage.Every <= 0 {
+				errs = append(errs, fmt.Errorf("rescan.stages[%d].every must be positive", i))
+			}
+			if stage.Until <= prev {
+				errs = append(errs, fmt.Errorf("rescan.stages[%d].until must be greater than the previous stage", i))
+			}
+			prev = stage.Until
+		}
+		if cfg.Rescan.Jitter < 0 || cfg.Rescan.Jitter >= 1 {
+			errs = append(errs, errors.New("rescan.jitter must be in [0, 1)"))
+		}
+		if cfg.Rescan.MaxConcurrent <= 0 {
+			errs = append(errs, errors.New("rescan.max_concurrent must be positive"))
+		}
+	}
+
+	if cfg.Storage.Enabled {
+		required("storage.path", cfg.Storage.Path)
+		if cfg.Storage.RetentionDays < 0 {
+			errs = append(errs, errors.New("storage.retention_days must be zero (keep forever) or positive"))
+		}
+		if cfg.Storage.CompactInterval < 0 {
+			errs = append(errs, errors.New("storage.compact_interval must not be negative"))
+		}
+	}
+
+	if cfg.Telegram.Bot.Enabled {
+		errs = append(errs, validateTelegramBot(cfg.Telegram.Bot)...)
+	}
+
+	if cfg.Telegram.Enabled {
+		if cfg.Telegram.APIID <= 0 {
+			errs = append(errs, errors.New("telegram.api_id is required when telegram is enabled"))
+		}
+		required("telegram.api_hash", cfg.Telegram.APIHash)
+		if len(cfg.Telegram.Sources()) == 0 {
+			errs = append(errs, errors.New("telegram.channels (or telegram.channel_id) is required when telegram is enabled"))
+		}
+		errs = append(errs, validateTelegramSources(cfg.Telegram)...)
+		required("telegram.session_file", cfg.Telegram.SessionFile)
+		if len(cfg.Telegram.SessionKey) < minSessionKeyLength {
+			errs = append(errs, fmt.Errorf("telegram.session_key must be at least %d characters (TELEGRAM_SESSION_KEY)", minSessionKeyLength))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateScoring(cfg ScoringConfig) []error {
+	var errs []error
+	validVerdict := func(v string) bool {
+		return v == VerdictIgnore || v == VerdictWatch || v == VerdictAlert
+	}
+
+	if cfg.ReportWeight < 0 {
+		errs = append(errs, errors.New("scoring.report_weight must not be negative"))
+	}
+	for i, rule := range cfg.Rules {
+		prefix := fmt.Sprintf("scoring.rules[%d]", i)
+		if rule.Name == "" {
+			errs = append(errs, fmt.Errorf("%s.name is required", prefix))
+		}
+		if rule.Verdict != "" && !validVerdict(rule.Verdict) {
+			errs = append(errs, fmt.Errorf("%s.verdict: unknown verdict %q (expected ignore, watch or alert)", prefix, rule.Verdict))
+		}
+		w := rule.When
+		if len(w.Risks) == 0 && w.RiskLevel == "" && w.Rugged == nil && w.FreezeAuthority == nil && w.MintAuthority == nil &&
+			w.LiquidityBelow == nil && w.TopHolderPctAbove == nil && w.Top10PctAbove == nil && w.Insiders == nil &&
+			w.SourcesAbove == nil && w.SourceWeightAbove == nil {
+			errs = append(errs, fmt.Errorf("%s.when must set at least one condition", prefix))
+		}
+	}
+
+	if len(cfg.Levels) == 0 {
+		errs = append(errs, errors.New("scoring.levels must define at least one level"))
+	}
+	prev := math.MinInt
+	for i, level := range cfg.Levels {
+		prefix := fmt.Sprintf("scoring.levels[%d]", i)
+		if !validVerdict(level.Verdict) {
+			errs = append(errs, fmt.Errorf("%s.verdict: unknown verdict %q (expected ignore, watch or alert)", prefix, level.Verdict))
+		}
+		if level.MaxScore == 0 && i == len(cfg.Levels)-1 {
+			continue
+		}
+		if level.MaxScore <= prev {
+			errs = append(errs, fmt.Errorf("%s.max_score must be greater than the previous level (only the last one may be 0 = unbounded)", prefix))
+		}
+		prev = level.MaxScore
+	}
+	return errs
+}
+
+func validateTelegramSources(cfg TelegramConfig) []error {
+	var errs []error
+	names := make(map[string]bool)
+	for _, parser := range cfg.ParserDefinitions()[:len(cfg.ParserDefinitions())-len(cfg.Parsers)] {
+		names[parser.Name] = true
+	}
+	for i, parser := range cfg.Parsers {
+		prefix := fmt.Sprintf("telegram.parsers[%d]", i)
+		if parser.Name == "" {
+			errs = append(errs, fmt.Errorf("%s.name is required", prefix))
+		} else if names[parser.Name] {
+			errs = append(errs, fmt.Errorf("%s.name: duplicate parser %q", prefix, parser.Name))
+		}
+		names[parser.Name] = true
+
+		switch parser.Kind {
+		case ParserKindBase58:
+		case ParserKindRegex:
+			if len(parser.Patterns) == 0 {
+				errs = append(errs, fmt.Errorf("%s.patterns must define at least one pattern", prefix))
+				continue
+			}
+			mint := false
+			for j, pattern := range parser.Patterns {
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s.patterns[%d]: %w", prefix, j, err))
+					continue
+				}
+				mint = mint || re.SubexpIndex("mint") >= 0
+			}
+			if !mint {
+				errs = append(errs, fmt.Errorf("%s.patterns must capture a (?P<mint>...) group", prefix))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%s.kind: unknown kind %q (expected regex or base58)", prefix, parser.Kind))
+		}
+	}
+	if !names[cfg.Parser] {
+		errs = append(errs, fmt.Errorf("telegram.parser: unknown parser %q", cfg.Parser))
+	}
+
+	// -1001234567890 (Bot API) y 1234567890 (MTProto) son el mismo canal
+	ids := make(map[int64]bool)
+	for i, ch := range cfg.Channels {
+		prefix := fmt.Sprintf("telegram.channels[%d]", i)
+		if ch.ID == 0 {
+			errs = append(errs, fmt.Errorf("%s.id is required", prefix))
+		} else if ids[PeerID(ch.ID)] {
+			errs = append(errs, fmt.Errorf("%s.id: duplicate channel %d", prefix, ch.ID))
+		}
+		ids[PeerID(ch.ID)] = true
+		if ch.Parser != "" && !names[ch.Parser] {
+			errs = append(errs, fmt.Errorf("%s.parser: unknown parser %q", prefix, ch.Parser))
+		}
+		if ch.Weight < 0 {
+			errs = append(errs, fmt.Errorf("%s.weight must not be negative", prefix))
+		}
+	}
+	if cfg.ChannelID != 0 && ids[PeerID(cfg.ChannelID)] {
+		errs = append(errs, fmt.Errorf("telegram.channel_id: channel %d is already in telegram.channels", cfg.ChannelID))
+	}
+	return errs
+}
+
+func validateTelegramBot(cfg TelegramBotConfig) []error {
+	var errs []error
+	if cfg.Token == "" {
+		errs = append(errs, errors.New("telegram.bot.token is required when the bot is enabled (TELEGRAM_BOT_TOKEN)"))
+	}
+	if err := validateURL(cfg.APIURL, "http", "https"); err != nil {
+		errs = append(errs, fmt.Errorf("telegram.bot.api_url: %w", err))
+	}
+	if len(cfg.ChatIDs) == 0 && !cfg.Commands {
+		errs = append(errs, errors.New("telegram.bot.chat_ids must list at least one chat"))
+	}
+	if cfg.Commands && len(cfg.AllowedUsers) == 0 {
+		errs = append(errs, errors.New("telegram.bot.allowed_users must list at least one user id when commands are enabled"))
+	}
+	for _, verdict := range cfg.Verdicts {
+		if verdict != VerdictIgnore && verdict != VerdictWatch && verdict != VerdictAlert {
+			errs = append(errs, fmt.Errorf("telegram.bot.verdicts: unknown verdict %q (expected ignore, watch or alert)", verdict))
+		}
+	}
+	return append(errs, validateRateLimit("telegram.bot.rate_limit", cfg.RateLimit)...)
+}
+
+func validateRateLimit(name string, limit RateLimitConfig) []error {
+	var errs []error
+	if limit.RPS <= 0 {
+		errs = append(errs, fmt.Errorf("%s.rps must be positive", name))
+	}
+	if limit.Burst <= 0 {
+		errs = append(errs, fmt.Errorf("%s.burst must be positive", name))
+	}
+	return errs
+}
+
+func validateURL(raw string, schemes ...string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	for _, s := range schemes {
+		if u.Scheme == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported scheme %q (expected %v)", u.Scheme, schemes)
+}
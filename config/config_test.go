@@ -0,0 +1,140 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gosol/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearEnv evita que variables del entorno del proceso interfieran con el test.
+func clearEnv(t *testing.T) {
+	for _, name := range []string{"GOSOL_CONFIG", "WEBSOCKET_URL", "API_KEY", "RAY_FEE_PUBKEY", "API_BASE_URL",
+		"RPC_URL", "RESCAN_ENABLED", "PUMPFUN_ENABLED", "STORAGE_PATH", "STORAGE_RETENTION_DAYS",
+		"TELEGRAM_ENABLED", "API_ID", "API_HASH", "TELEGRAM_CHANNEL_ID", "PLATFORM_KEYWORD",
+		"TELEGRAM_PHONE", "TELEGRAM_SESSION_FILE", "TELEGRAM_SESSION_KEY",
+		"TELEGRAM_BOT_ENABLED", "TELEGRAM_BOT_TOKEN"} {
+		t.Setenv(name, "")
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, `
+solana:
+  websocket_url: wss://file.example/?api-key=
+  api_key: file-key
+  ray_fee_pubkey: 7YttLkHDoNj9wyDur5pM1ejNaAvT9X4eqaYcHQqtj2G5
+report:
+  api_base_url: https://file.example
+telegram:
+  enabled: false
+`)
+	t.Setenv("API_KEY", "env-key")
+	t.Setenv("API_BASE_URL", "https://env.example")
+
+	cfg, err := config.Load([]string{"-config", path, "-api-base-url", "https://flag.example"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "wss://file.example/?api-key=", cfg.Solana.WebsocketURL)
+	assert.Equal(t, "env-key", cfg.Solana.APIKey)
+	assert.Equal(t, "https://flag.example", cfg.Report.APIBaseURL)
+	assert.Equal(t, "wss://file.example/?api-key=env-key", cfg.Solana.WebsocketEndpoint())
+	assert.False(t, cfg.Telegram.Enabled)
+}
+
+func TestLoadReportsAllErrors(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, `
+solana:
+  websocket_url: https://not-a-websocket
+  ray_fee_pubkey: not-a-pubkey
+scoring:
+  rules:
+    - name: empty
+  levels:
+    - { max_score: 3000, verdict: watch }
+    - { max_score: 2000, verdict: maybe }
+telegram:
+  parser: missing
+  parsers:
+    - { name: default, kind: base58 }
+    - { name: no-mint, kind: regex, patterns: ['Pool: (?P<pool>\w+)'] }
+    - { name: other, kind: html }
+  channels:
+    - { id: -1001, parser: nope }
+    - { id: -1001, weight: -1 }
+    - { id: -1001234567890 }
+    - { id: 1234567890 }
+  bot:
+    enabled: true
+    verdicts: [loud]
+`)
+	t.Setenv("API_ID", "abc")
+
+	_, err := config.Load([]string{"-config", path})
+	require.Error(t, err)
+
+	msg := err.Error()
+	assert.Contains(t, msg, "env API_ID: invalid integer")
+	assert.Contains(t, msg, "solana.api_key is required")
+	assert.Contains(t, msg, "report.api_base_url is required")
+	assert.Contains(t, msg, "solana.websocket_url: unsupported scheme")
+	assert.Contains(t, msg, "solana.ray_fee_pubkey: invalid public key")
+	assert.Contains(t, msg, "telegram.api_hash is required")
+	assert.Contains(t, msg, "scoring.rules[0].when must set at least one condition")
+	assert.Contains(t, msg, `scoring.levels[1].verdict: unknown verdict "maybe"`)
+	assert.Contains(t, msg, "scoring.levels[1].max_score must be greater than the previous level")
+	assert.Contains(t, msg, `telegram.parsers[0].name: duplicate parser "default"`)
+	assert.Contains(t, msg, "telegram.parsers[1].patterns must capture a (?P<mint>...) group")
+	assert.Contains(t, msg, `telegram.parsers[2].kind: unknown kind "html"`)
+	assert.Contains(t, msg, `telegram.parser: unknown parser "missing"`)
+	assert.Contains(t, msg, `telegram.channels[0].parser: unknown parser "nope"`)
+	assert.Contains(t, msg, "telegram.channels[1].id: duplicate channel -1001")
+	assert.Contains(t, msg, "telegram.channels[1].weight must not be negative")
+	assert.Contains(t, msg, "telegram.channels[3].id: duplicate channel 1234567890")
+	assert.Contains(t, msg, "telegram.bot.token is required")
+	assert.Contains(t, msg, "telegram.bot.chat_ids must list at least one chat")
+	assert.Contains(t, msg, `telegram.bot.verdicts: unknown verdict "loud"`)
+}
+
+func TestLoadBotCommandsRequireAllowedUsers(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, `
+telegram:
+  bot:
+    enabled: true
+    token: bot-token
+    commands: true
+`)
+
+	_, err := config.Load([]string{"-config", path})
+	require.Error(t, err)
+
+	// con comandos no hace falta un chat para las tarjetas, pero sí usuarios autorizados
+	msg := err.Error()
+	assert.Contains(t, msg, "telegram.bot.allowed_users must list at least one user id")
+	assert.NotContains(t, msg, "telegram.bot.chat_ids")
+}
+
+func TestLoadMissingExplicitFile(t *testing.T) {
+	clearEnv(t)
+	_, err := config.Load([]string{"-config", filepath.Join(t.TempDir(), "missing.yaml")})
+	assert.ErrorContains(t, err, "opening config file")
+}
+
+func TestPeerID(t *testing.T) {
+	assert.Equal(t, int64(1234567890), config.PeerID(-1001234567890))
+	assert.Equal(t, int64(4567890), config.PeerID(-4567890))
+	assert.Equal(t, int64(1234567890), config.PeerID(1234567890))
+}
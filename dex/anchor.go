@@ -0,0 +1,136 @@
+package dex
+
+import (
+	"encoding/binary"
+	"fmt"
+	"gosol/types"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// anchorDetector reconoce las instrucciones de creación de pool de un programa Anchor por su
+// discriminador (sha256("global:<instrucción>")[:8]) y mapea sus cuentas a types.PoolCreated.
+type anchorDetector struct {
+	name         string
+	programID    solana.PublicKey
+	instructions map[[8]byte]anchorPoolInstruction
+	// logLines son los "Instruction: <Nombre>" que Anchor loguea al entrar a cada instrucción.
+	logLines []string
+}
+
+// anchorPoolInstruction describe una instrucción que crea un pool: el nombre del IDL (snake
+// case), la posición de cada cuenta (-1 si la instrucción no la tiene) y cómo leer los
+// argumentos que nos interesan.
+type anchorPoolInstruction struct {
+	name     string
+	accounts poolAccounts
+	// args lee montos iniciales y open time de los argumentos (sin el discriminador).
+	args func(args []byte, pool *types.PoolCreated) error
+}
+
+type poolAccounts struct {
+	pool, baseMint, quoteMint, lpMint, baseVault, quoteVault, creator int
+}
+
+func newAnchorDetector(name string, programID solana.PublicKey, instructions ...anchorPoolInstruction) *anchorDetector {
+	d := &anchorDetector{
+		name:         name,
+		programID:    programID,
+		instructions: make(map[[8]byte]anchorPoolInstruction, len(instructions)),
+	}
+	for _, ix := range instructions {
+		d.instructions[anchorDiscriminator("global:"+ix.name)] = ix
+		d.logLines = append(d.logLines, "Instruction: "+pascalCase(ix.name))
+	}
+	return d
+}
+
+func (d *anchorDetector) Name() string                { return d.name }
+func (d *anchorDetector) ProgramID() solana.PublicKey { return d.programID }
+
+// MatchesLogs busca la línea exacta de la instrucción dentro de los logs del programa: las
+// instrucciones de SPL Token (InitializeAccount3, InitializeMint2...) no cuentan.
+func (d *anchorDetector) MatchesLogs(logs []string) bool {
+	for _, msg := range programLogs(logs, d.programID) {
+		if slices.Contains(d.logLines, msg) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *anchorDetector) DecodePoolCreated(ix Instruction) (types.PoolCreated, error) {
+	if !ix.ProgramID.Equals(d.programID) || len(ix.Data) < 8 {
+		return types.PoolCreated{}, ErrNotPoolCreation
+	}
+	spec, ok := d.instructions[[8]byte(ix.Data[:8])]
+	if !ok {
+		return types.PoolCreated{}, ErrNotPoolCreation
+	}
+
+	acc := spec.accounts
+	needed := max(acc.pool, acc.baseMint, acc.quoteMint, acc.lpMint, acc.baseVault, acc.quoteVault, acc.creator) + 1
+	if len(ix.Accounts) < needed {
+		return types.PoolCreated{}, fmt.Errorf("%s: expected at least %d accounts, got %d", spec.name, needed, len(ix.Accounts))
+	}
+	account := func(i int) string {
+		if i < 0 {
+			return ""
+		}
+		return ix.Accounts[i].String()
+	}
+
+	pool := types.PoolCreated{
+		DEX:        d.name,
+		Pool:       account(acc.pool),
+		BaseMint:   account(acc.baseMint),
+		QuoteMint:  account(acc.quoteMint),
+		LPMint:     account(acc.lpMint),
+		BaseVault:  account(acc.baseVault),
+		QuoteVault: account(acc.quoteVault),
+		Creator:    account(acc.creator),
+	}
+	if spec.args != nil {
+		if err := spec.args(ix.Data[8:], &pool); err != nil {
+			return types.PoolCreated{}, fmt.Errorf("%s: %w", spec.name, err)
+		}
+	}
+	return pool, nil
+}
+
+// amountsArgs lee los montos iniciales (u64 base, u64 quote) que empiezan en offset y,
+// si openTimeAt >= 0, el open time (u64, unix) en esa posición.
+func amountsArgs(offset, openTimeAt int) func([]byte, *types.PoolCreated) error {
+	return func(args []byte, pool *types.PoolCreated) error {
+		need := max(offset+16, openTimeAt+8)
+		if offset < 0 {
+			need = openTimeAt + 8
+		}
+		if len(args) < need {
+			return fmt.Errorf("args too short (%d bytes, need %d)", len(args), need)
+		}
+		if offset >= 0 {
+			pool.InitBaseAmount = binary.LittleEndian.Uint64(args[offset:])
+			pool.InitQuoteAmount = binary.LittleEndian.Uint64(args[offset+8:])
+		}
+		if openTimeAt >= 0 {
+			pool.OpenTime = time.Unix(int64(binary.LittleEndian.Uint64(args[openTimeAt:])), 0)
+		}
+		return nil
+	}
+}
+
+// pascalCase convierte "initialize_lb_pair" en "InitializeLbPair".
+func pascalCase(snake string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(snake, "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
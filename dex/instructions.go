@@ -0,0 +1,147 @@
+// Package dex decodifica instrucciones de los programas de DEX de Solana para detectar
+// la creación de pools directamente desde las transacciones.
+package dex
+
+import (
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Instruction es una instrucción con sus cuentas ya resueltas a direcciones.
+type Instruction struct {
+	ProgramID solana.PublicKey
+	Accounts  []solana.PublicKey
+	// Signer y Writable indican, para cada cuenta de Accounts, si firma la transacción y si
+	// es modificable.
+	Signer   []bool
+	Writable []bool
+	Data     []byte
+	// Index es la posición de la instrucción de primer nivel; Inner indica que es una CPI
+	// invocada por esa instrucción.
+	Index int
+	Inner bool
+}
+
+// AccountKeys devuelve las cuentas de la transacción en el orden en que las indexan las
+// instrucciones: las del mensaje y, en transacciones v0, las cargadas de lookup tables
+// (primero las writable y después las readonly). Las cargadas salen de meta.LoadedAddresses
+// o, si el nodo no las informó, de las tablas cargadas con tx.Message.SetAddressTables.
+func AccountKeys(tx *solana.Transaction, meta *rpc.TransactionMeta) (solana.PublicKeySlice, error) {
+	keys, _, err := accountKeys(tx, meta)
+	return keys, err
+}
+
+// accountKeys es AccountKeys más la cantidad de cuentas cargadas writable.
+func accountKeys(tx *solana.Transaction, meta *rpc.TransactionMeta) (solana.PublicKeySlice, int, error) {
+	keys := append(solana.PublicKeySlice{}, tx.Message.AccountKeys...)
+	if !NeedsLookupTables(tx, meta) {
+		if meta != nil {
+			keys = append(keys, meta.LoadedAddresses.Writable...)
+			keys = append(keys, meta.LoadedAddresses.ReadOnly...)
+			return keys, len(meta.LoadedAddresses.Writable), nil
+		}
+		return keys, 0, nil
+	}
+	loaded, err := tx.Message.GetAddressTableLookupAccounts()
+	if err != nil {
+		return nil, 0, fmt.Errorf("resolving address lookup tables: %w", err)
+	}
+	return append(keys, loaded...), tx.Message.NumWritableLookups(), nil
+}
+
+// NeedsLookupTables indica si para resolver las cuentas de la transacción hay que cargar
+// sus lookup tables (con tx.Message.SetAddressTables): es v0, usa tablas y meta no trae
+// las direcciones cargadas.
+func NeedsLookupTables(tx *solana.Transaction, meta *rpc.TransactionMeta) bool {
+	if !tx.Message.IsVersioned() || tx.Message.NumLookups() == 0 {
+		return false
+	}
+	return meta == nil || len(meta.LoadedAddresses.Writable)+len(meta.LoadedAddresses.ReadOnly) == 0
+}
+
+// accountRoles calcula si cada cuenta firma y si es writable según el header del mensaje;
+// de las cargadas de lookup tables las primeras loadedWritable son writable.
+func accountRoles(tx *solana.Transaction, total, loadedWritable int) (signer, writable []bool) {
+	header := tx.Message.Header
+	static := len(tx.Message.AccountKeys)
+	signers := int(header.NumRequiredSignatures)
+	signer = make([]bool, total)
+	writable = make([]bool, total)
+	for i := range total {
+		switch {
+		case i < signers:
+			signer[i] = true
+			writable[i] = i < signers-int(header.NumReadonlySignedAccounts)
+		case i < static:
+			writable[i] = i < static-int(header.NumReadonlyUnsignedAccounts)
+		default:
+			writable[i] = i < static+loadedWritable
+		}
+	}
+	return signer, writable
+}
+
+// Instructions aplana las instrucciones de la transacción: cada instrucción de primer nivel
+// seguida de sus instrucciones internas.
+func Instructions(tx *solana.Transaction, meta *rpc.TransactionMeta) ([]Instruction, error) {
+	keys, loadedWritable, err := accountKeys(tx, meta)
+	if err != nil {
+		return nil, err
+	}
+	signer, writable := accountRoles(tx, len(keys), loadedWritable)
+	roles := func(ix *Instruction, ci solana.CompiledInstruction) {
+		ix.Signer = make([]bool, len(ci.Accounts))
+		ix.Writable = make([]bool, len(ci.Accounts))
+		for j, idx := range ci.Accounts {
+			ix.Signer[j], ix.Writable[j] = signer[idx], writable[idx]
+		}
+	}
+
+	inner := make(map[int][]solana.CompiledInstruction)
+	if meta != nil {
+		for _, ii := range meta.InnerInstructions {
+			inner[int(ii.Index)] = append(inner[int(ii.Index)], ii.Instructions...)
+		}
+	}
+
+	var out []Instruction
+	for i, ci := range tx.Message.Instructions {
+		ix, err := resolve(keys, ci, i, false)
+		if err != nil {
+			return nil, err
+		}
+		roles(&ix, ci)
+		out = append(out, ix)
+		for _, ci := range inner[i] {
+			ix, err := resolve(keys, ci, i, true)
+			if err != nil {
+				return nil, err
+			}
+			roles(&ix, ci)
+			out = append(out, ix)
+		}
+	}
+	return out, nil
+}
+
+func resolve(keys solana.PublicKeySlice, ci solana.CompiledInstruction, index int, isInner bool) (Instruction, error) {
+	if int(ci.ProgramIDIndex) >= len(keys) {
+		return Instruction{}, fmt.Errorf("instruction %d: program index %d out of range (%d accounts)", index, ci.ProgramIDIndex, len(keys))
+	}
+	ix := Instruction{
+		ProgramID: keys[ci.ProgramIDIndex],
+		Accounts:  make([]solana.PublicKey, len(ci.Accounts)),
+		Data:      ci.Data,
+		Index:     index,
+		Inner:     isInner,
+	}
+	for j, idx := range ci.Accounts {
+		if int(idx) >= len(keys) {
+			return Instruction{}, fmt.Errorf("instruction %d: account index %d out of range (%d accounts)", index, idx, len(keys))
+		}
+		ix.Accounts[j] = keys[idx]
+	}
+	return ix, nil
+}
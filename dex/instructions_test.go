@@ -0,0 +1,70 @@
+package dex_test
+
+import (
+	"testing"
+
+	"gosol/dex"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// v0Transaction arma una transacción v0 (ida y vuelta por binario, como llega del RPC) con
+// un pagador, un programa y una instrucción que usa dos cuentas de una lookup table.
+func v0Transaction(t *testing.T, payer, program, table solana.PublicKey) *solana.Transaction {
+	msg := solana.Message{
+		Header:      solana.MessageHeader{NumRequiredSignatures: 1, NumReadonlyUnsignedAccounts: 1},
+		AccountKeys: solana.PublicKeySlice{payer, program},
+		Instructions: []solana.CompiledInstruction{
+			// 2 es la writable cargada (tabla[2]) y 3 la readonly (tabla[0])
+			{ProgramIDIndex: 1, Accounts: []uint16{0, 2, 3}, Data: []byte{1, 2, 3}},
+		},
+		AddressTableLookups: solana.MessageAddressTableLookupSlice{
+			{AccountKey: table, WritableIndexes: []uint8{2}, ReadonlyIndexes: []uint8{0}},
+		},
+	}
+	msg.SetVersion(solana.MessageVersionV0)
+	data, err := (&solana.Transaction{Signatures: []solana.Signature{{1}}, Message: msg}).MarshalBinary()
+	require.NoError(t, err)
+	tx, err := solana.TransactionFromBytes(data)
+	require.NoError(t, err)
+	return tx
+}
+
+func TestInstructionsResolveLookupTables(t *testing.T) {
+	keys := randomKeys(6)
+	payer, program, table := keys[0], keys[1], keys[2]
+	tableAddresses := solana.PublicKeySlice{keys[3], solana.NewWallet().PublicKey(), keys[5]}
+	want := dex.Instruction{
+		ProgramID: program,
+		Accounts:  []solana.PublicKey{payer, keys[5], keys[3]},
+		Signer:    []bool{true, false, false},
+		Writable:  []bool{true, true, false},
+		Data:      []byte{1, 2, 3},
+	}
+
+	// el nodo informa las direcciones cargadas
+	tx := v0Transaction(t, payer, program, table)
+	meta := &rpc.TransactionMeta{LoadedAddresses: rpc.LoadedAddresses{
+		Writable: solana.PublicKeySlice{keys[5]},
+		ReadOnly: solana.PublicKeySlice{keys[3]},
+	}}
+	assert.False(t, dex.NeedsLookupTables(tx, meta))
+	instructions, err := dex.Instructions(tx, meta)
+	require.NoError(t, err)
+	assert.Equal(t, []dex.Instruction{want}, instructions)
+
+	// no las informa: hay que cargar la tabla
+	tx = v0Transaction(t, payer, program, table)
+	meta = &rpc.TransactionMeta{}
+	require.True(t, dex.NeedsLookupTables(tx, meta))
+	_, err = dex.Instructions(tx, meta)
+	assert.ErrorContains(t, err, "address lookup tables")
+
+	require.NoError(t, tx.Message.SetAddressTables(map[solana.PublicKey]solana.PublicKeySlice{table: tableAddresses}))
+	instructions, err = dex.Instructions(tx, meta)
+	require.NoError(t, err)
+	assert.Equal(t, []dex.Instruction{want}, instructions)
+}
@@ -0,0 +1,55 @@
+package dex
+
+import (
+	"gosol/types"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Programas de Meteora.
+var (
+	MeteoraDLMMProgramID    = solana.MustPublicKeyFromBase58("LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo")
+	MeteoraDynamicProgramID = solana.MustPublicKeyFromBase58("Eo7WjKq67rjJQSZxS6z3YkapzY3eMj6Xy8X5EQVn5UaB")
+)
+
+const (
+	DEXMeteoraDLMM    = "meteora-dlmm"
+	DEXMeteoraDynamic = "meteora-dynamic"
+)
+
+// MeteoraDLMM detecta los pares creados con initialize_lb_pair.
+// Cuentas: 0 lb_pair, 2/3 token_mint_x/y, 4/5 reserve_x/y, 8 funder.
+// Argumentos: active_id (i32), bin_step (u16); no hay montos iniciales.
+func MeteoraDLMM() Detector {
+	return newAnchorDetector(DEXMeteoraDLMM, MeteoraDLMMProgramID, anchorPoolInstruction{
+		name:     "initialize_lb_pair",
+		accounts: poolAccounts{pool: 0, baseMint: 2, quoteMint: 3, lpMint: -1, baseVault: 4, quoteVault: 5, creator: 8},
+	})
+}
+
+// MeteoraDynamic detecta los pools dinámicos (AMM sobre vaults de Meteora).
+//   - initialize_permissionless_pool: 0 pool, 1 lp_mint, 2/3 token_a/b_mint, 4/5 a/b_vault,
+//     15 payer. Argumentos: curve_type (enum) y los montos; solo se leen con curva de
+//     producto constante (tag 0), porque la curva estable tiene largo variable.
+//   - initialize_permissionless_constant_product_pool_with_config: 0 pool, 2 lp_mint,
+//     3/4 token_a/b_mint, 5/6 a/b_vault, 18 payer. Argumentos: token_a_amount, token_b_amount.
+func MeteoraDynamic() Detector {
+	constantProductAmounts := amountsArgs(1, -1)
+	return newAnchorDetector(DEXMeteoraDynamic, MeteoraDynamicProgramID,
+		anchorPoolInstruction{
+			name:     "initialize_permissionless_pool",
+			accounts: poolAccounts{pool: 0, baseMint: 2, quoteMint: 3, lpMint: 1, baseVault: 4, quoteVault: 5, creator: 15},
+			args: func(args []byte, pool *types.PoolCreated) error {
+				if len(args) > 0 && args[0] == 0 {
+					return constantProductAmounts(args, pool)
+				}
+				return nil
+			},
+		},
+		anchorPoolInstruction{
+			name:     "initialize_permissionless_constant_product_pool_with_config",
+			accounts: poolAccounts{pool: 0, baseMint: 3, quoteMint: 4, lpMint: 2, baseVault: 5, quoteVault: 6, creator: 18},
+			args:     amountsArgs(0, -1),
+		},
+	)
+}
@@ -0,0 +1,27 @@
+package dex
+
+import "github.com/gagliardetto/solana-go"
+
+// OrcaWhirlpoolProgramID es el programa de los Whirlpools de Orca.
+var OrcaWhirlpoolProgramID = solana.MustPublicKeyFromBase58("whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc")
+
+const DEXOrcaWhirlpool = "orca-whirlpool"
+
+// OrcaWhirlpool detecta los whirlpools creados con initialize_pool o initialize_pool_v2
+// (este último agrega los token badges y un token program por lado).
+//   - initialize_pool: 1/2 token_mint_a/b, 3 funder, 4 whirlpool, 5/6 token_vault_a/b.
+//   - initialize_pool_v2: 1/2 token_mint_a/b, 5 funder, 6 whirlpool, 7/8 token_vault_a/b.
+//
+// Los argumentos son tick_spacing y el precio inicial; no hay montos iniciales.
+func OrcaWhirlpool() Detector {
+	return newAnchorDetector(DEXOrcaWhirlpool, OrcaWhirlpoolProgramID,
+		anchorPoolInstruction{
+			name:     "initialize_pool",
+			accounts: poolAccounts{pool: 4, baseMint: 1, quoteMint: 2, lpMint: -1, baseVault: 5, quoteVault: 6, creator: 3},
+		},
+		anchorPoolInstruction{
+			name:     "initialize_pool_v2",
+			accounts: poolAccounts{pool: 6, baseMint: 1, quoteMint: 2, lpMint: -1, baseVault: 7, quoteVault: 8, creator: 5},
+		},
+	)
+}
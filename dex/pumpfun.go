@@ -0,0 +1,200 @@
+package dex
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// PumpFunProgramID es el programa de bonding curves de pump.fun.
+var PumpFunProgramID = solana.MustPublicKeyFromBase58("6EF8rrecthR5Dkzon8Nwu5hjzD3a1GwWHMAx3drFkjAu")
+
+// Parámetros de la bonding curve de pump.fun (en unidades mínimas, 6 decimales). La curva
+// arranca con 1073M tokens virtuales y se completa cuando se vendieron los 793.1M reales,
+// es decir cuando quedan 279.9M virtuales.
+const (
+	PumpFunInitialVirtualTokenReserves = 1_073_000_000_000_000
+	PumpFunFinalVirtualTokenReserves   = 279_900_000_000_000
+)
+
+// Eventos de Anchor que emite pump.fun en los logs ("Program data: <base64>"). Cada uno
+// empieza con sha256("event:<Nombre>")[:8] seguido de los campos en borsh. Versiones
+// nuevas del programa agregan campos al final, que se ignoran.
+var (
+	pumpFunCreateDiscriminator   = anchorDiscriminator("event:CreateEvent")
+	pumpFunTradeDiscriminator    = anchorDiscriminator("event:TradeEvent")
+	pumpFunCompleteDiscriminator = anchorDiscriminator("event:CompleteEvent")
+)
+
+func anchorDiscriminator(name string) [8]byte {
+	sum := sha256.Sum256([]byte(name))
+	var d [8]byte
+	copy(d[:], sum[:8])
+	return d
+}
+
+// PumpFunEvent es alguno de PumpFunCreate, PumpFunTrade o PumpFunComplete.
+type PumpFunEvent interface {
+	EventMint() solana.PublicKey
+}
+
+// PumpFunCreate es la creación de un token con su bonding curve.
+type PumpFunCreate struct {
+	Name         string
+	Symbol       string
+	URI          string
+	Mint         solana.PublicKey
+	BondingCurve solana.PublicKey
+	User         solana.PublicKey
+}
+
+// PumpFunTrade es una compra o venta contra la bonding curve.
+type PumpFunTrade struct {
+	Mint                 solana.PublicKey
+	SolAmount            uint64
+	TokenAmount          uint64
+	IsBuy                bool
+	User                 solana.PublicKey
+	Timestamp            int64
+	VirtualSolReserves   uint64
+	VirtualTokenReserves uint64
+}
+
+// PumpFunComplete indica que la curva se completó y el token queda listo para migrar a Raydium.
+type PumpFunComplete struct {
+	User         solana.PublicKey
+	Mint         solana.PublicKey
+	BondingCurve solana.PublicKey
+	Timestamp    int64
+}
+
+func (e PumpFunCreate) EventMint() solana.PublicKey   { return e.Mint }
+func (e PumpFunTrade) EventMint() solana.PublicKey    { return e.Mint }
+func (e PumpFunComplete) EventMint() solana.PublicKey { return e.Mint }
+
+// Progress devuelve el avance de la bonding curve (0 a 100) según las reservas virtuales
+// después del trade.
+func (e PumpFunTrade) Progress() float64 {
+	return CurveProgress(e.VirtualTokenReserves)
+}
+
+// CurveProgress calcula el avance de la bonding curve (0 a 100) a partir de las reservas
+// virtuales de tokens.
+func CurveProgress(virtualTokenReserves uint64) float64 {
+	sold := float64(PumpFunInitialVirtualTokenReserves) - float64(virtualTokenReserves)
+	progress := sold / float64(PumpFunInitialVirtualTokenReserves-PumpFunFinalVirtualTokenReserves) * 100
+	return min(max(progress, 0), 100)
+}
+
+// ErrUnknownEvent indica que el dato no es un evento de pump.fun conocido.
+var ErrUnknownEvent = errors.New("unknown event")
+
+const programDataPrefix = "Program data: "
+
+// PumpFunEvents decodifica los eventos de pump.fun presentes en los logs de una transacción.
+// Los logs "Program data:" de otros programas se ignoran.
+func PumpFunEvents(logs []string) ([]PumpFunEvent, error) {
+	var events []PumpFunEvent
+	var errs []error
+	for _, line := range logs {
+		encoded, ok := strings.CutPrefix(line, programDataPrefix)
+		if !ok {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+		event, err := DecodePumpFunEvent(data)
+		if errors.Is(err, ErrUnknownEvent) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, errors.Join(errs...)
+}
+
+// DecodePumpFunEvent decodifica el dato de un log "Program data:" de pump.fun.
+func DecodePumpFunEvent(data []byte) (PumpFunEvent, error) {
+	if len(data) < 8 {
+		return nil, ErrUnknownEvent
+	}
+	r := reader{data: data[8:]}
+
+	switch [8]byte(data[:8]) {
+	case pumpFunCreateDiscriminator:
+		e := PumpFunCreate{
+			Name:         r.string(),
+			Symbol:       r.string(),
+			URI:          r.string(),
+			Mint:         r.pubkey(),
+			BondingCurve: r.pubkey(),
+			User:         r.pubkey(),
+		}
+		return e, r.wrap("pump.fun CreateEvent")
+	case pumpFunTradeDiscriminator:
+		e := PumpFunTrade{
+			Mint:                 r.pubkey(),
+			SolAmount:            r.u64(),
+			TokenAmount:          r.u64(),
+			IsBuy:                r.bool(),
+			User:                 r.pubkey(),
+			Timestamp:            int64(r.u64()),
+			VirtualSolReserves:   r.u64(),
+			VirtualTokenReserves: r.u64(),
+		}
+		return e, r.wrap("pump.fun TradeEvent")
+	case pumpFunCompleteDiscriminator:
+		e := PumpFunComplete{
+			User:         r.pubkey(),
+			Mint:         r.pubkey(),
+			BondingCurve: r.pubkey(),
+			Timestamp:    int64(r.u64()),
+		}
+		return e, r.wrap("pump.fun CompleteEvent")
+	}
+	return nil, ErrUnknownEvent
+}
+
+// reader lee valores borsh secuencialmente; el primer error corta el resto de las lecturas.
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err == nil && (n < 0 || r.pos+n > len(r.data)) {
+		r.err = fmt.Errorf("unexpected end of data at offset %d (need %d bytes)", r.pos, n)
+	}
+	if r.err != nil {
+		return make([]byte, 32)
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) u64() uint64              { return binary.LittleEndian.Uint64(r.next(8)) }
+func (r *reader) bool() bool               { return r.next(1)[0] != 0 }
+func (r *reader) pubkey() solana.PublicKey { return solana.PublicKeyFromBytes(r.next(32)) }
+func (r *reader) string() string {
+	n := binary.LittleEndian.Uint32(r.next(4))
+	return string(r.next(int(n)))
+}
+
+func (r *reader) wrap(what string) error {
+	if r.err != nil {
+		return fmt.Errorf("%s: %w", what, r.err)
+	}
+	return nil
+}
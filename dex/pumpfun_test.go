@@ -0,0 +1,79 @@
+package dex_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"gosol/dex"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func eventLog(name string, fields ...[]byte) string {
+	sum := sha256.Sum256([]byte("event:" + name))
+	data := append([]byte{}, sum[:8]...)
+	for _, f := range fields {
+		data = append(data, f...)
+	}
+	return "Program data: " + base64.StdEncoding.EncodeToString(data)
+}
+
+func borshString(s string) []byte {
+	return append(binary.LittleEndian.AppendUint32(nil, uint32(len(s))), s...)
+}
+
+func u64(v uint64) []byte { return binary.LittleEndian.AppendUint64(nil, v) }
+
+func TestPumpFunEvents(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	curve := solana.NewWallet().PublicKey()
+	user := solana.NewWallet().PublicKey()
+
+	// a mitad de camino quedan 1073M - 793.1M/2 tokens virtuales
+	halfway := uint64(dex.PumpFunInitialVirtualTokenReserves - (dex.PumpFunInitialVirtualTokenReserves-dex.PumpFunFinalVirtualTokenReserves)/2)
+
+	logs := []string{
+		"Program 6EF8rrecthR5Dkzon8Nwu5hjzD3a1GwWHMAx3drFkjAu invoke [1]",
+		"Program log: Instruction: Create",
+		eventLog("CreateEvent", borshString("Test"), borshString("TST"), borshString("https://x/y.json"), mint[:], curve[:], user[:]),
+		eventLog("TradeEvent", mint[:], u64(1_000_000_000), u64(30_000_000_000), []byte{1}, user[:], u64(1700000000), u64(45_000_000_000), u64(halfway),
+			u64(0), u64(0)), // campos nuevos al final: se ignoran
+		eventLog("SomethingElse", []byte{1, 2, 3}),
+		eventLog("CompleteEvent", user[:], mint[:], curve[:], u64(1700000100)),
+	}
+
+	events, err := dex.PumpFunEvents(logs)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	create, ok := events[0].(dex.PumpFunCreate)
+	require.True(t, ok)
+	assert.Equal(t, "TST", create.Symbol)
+	assert.Equal(t, "https://x/y.json", create.URI)
+	assert.Equal(t, curve, create.BondingCurve)
+
+	trade, ok := events[1].(dex.PumpFunTrade)
+	require.True(t, ok)
+	assert.True(t, trade.IsBuy)
+	assert.Equal(t, mint, trade.EventMint())
+	assert.InDelta(t, 50.0, trade.Progress(), 0.001)
+
+	complete, ok := events[2].(dex.PumpFunComplete)
+	require.True(t, ok)
+	assert.Equal(t, int64(1700000100), complete.Timestamp)
+}
+
+func TestCurveProgressBounds(t *testing.T) {
+	assert.Equal(t, 0.0, dex.CurveProgress(dex.PumpFunInitialVirtualTokenReserves))
+	assert.Equal(t, 100.0, dex.CurveProgress(dex.PumpFunFinalVirtualTokenReserves))
+	assert.Equal(t, 100.0, dex.CurveProgress(0))
+}
+
+func TestPumpFunEventsReportsTruncatedData(t *testing.T) {
+	_, err := dex.PumpFunEvents([]string{eventLog("CompleteEvent", make([]byte, 10))})
+	assert.ErrorContains(t, err, "pump.fun CompleteEvent")
+}
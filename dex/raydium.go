@@ -0,0 +1,125 @@
+package dex
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"gosol/types"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// RaydiumAMMv4ProgramID es el programa de los pools AMM v4 (liquidity pool v4) de Raydium.
+var RaydiumAMMv4ProgramID = solana.MustPublicKeyFromBase58("675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8")
+
+// DEXRaydiumAMMv4 identifica a los pools de Raydium AMM v4 en types.PoolCreated.
+const DEXRaydiumAMMv4 = "raydium-amm-v4"
+
+// initialize2 es la instrucción con la que se crean los pools de Raydium AMM v4:
+// tag (u8) = 1, nonce (u8), open_time (u64), init_pc_amount (u64), init_coin_amount (u64).
+const (
+	raydiumInitialize2Tag = 1
+	raydiumInitialize2Len = 1 + 1 + 8 + 8 + 8
+)
+
+// Posiciones de las cuentas de initialize2 que nos interesan.
+const (
+	raydiumAccPool       = 4
+	raydiumAccLPMint     = 7
+	raydiumAccCoinMint   = 8
+	raydiumAccPcMint     = 9
+	raydiumAccCoinVault  = 10
+	raydiumAccPcVault    = 11
+	raydiumAccMarket     = 16
+	raydiumAccCreator    = 17
+	raydiumInitAccsCount = 18
+)
+
+// ErrNotPoolCreation indica que la instrucción no crea un pool.
+var ErrNotPoolCreation = errors.New("instruction does not create a pool")
+
+// DecodeRaydiumInitialize2 decodifica una instrucción initialize2 de Raydium AMM v4.
+func DecodeRaydiumInitialize2(ix Instruction) (types.PoolCreated, error) {
+	if !ix.ProgramID.Equals(RaydiumAMMv4ProgramID) || len(ix.Data) == 0 || ix.Data[0] != raydiumInitialize2Tag {
+		return types.PoolCreated{}, ErrNotPoolCreation
+	}
+	if len(ix.Data) < raydiumInitialize2Len {
+		return types.PoolCreated{}, fmt.Errorf("raydium initialize2: data too short (%d bytes)", len(ix.Data))
+	}
+	if len(ix.Accounts) < raydiumInitAccsCount {
+		return types.PoolCreated{}, fmt.Errorf("raydium initialize2: expected at least %d accounts, got %d", raydiumInitAccsCount, len(ix.Accounts))
+	}
+
+	openTime := binary.LittleEndian.Uint64(ix.Data[2:10])
+	return types.PoolCreated{
+		DEX:             DEXRaydiumAMMv4,
+		Pool:            ix.Accounts[raydiumAccPool].String(),
+		BaseMint:        ix.Accounts[raydiumAccCoinMint].String(),
+		QuoteMint:       ix.Accounts[raydiumAccPcMint].String(),
+		LPMint:          ix.Accounts[raydiumAccLPMint].String(),
+		BaseVault:       ix.Accounts[raydiumAccCoinVault].String(),
+		QuoteVault:      ix.Accounts[raydiumAccPcVault].String(),
+		Market:          ix.Accounts[raydiumAccMarket].String(),
+		Creator:         ix.Accounts[raydiumAccCreator].String(),
+		OpenTime:        time.Unix(int64(openTime), 0),
+		InitQuoteAmount: binary.LittleEndian.Uint64(ix.Data[10:18]),
+		InitBaseAmount:  binary.LittleEndian.Uint64(ix.Data[18:26]),
+	}, nil
+}
+
+type raydiumAMMv4Detector struct{}
+
+// RaydiumAMMv4 detecta los pools creados con initialize2 en Raydium AMM v4.
+func RaydiumAMMv4() Detector { return raydiumAMMv4Detector{} }
+
+func (raydiumAMMv4Detector) Name() string                { return DEXRaydiumAMMv4 }
+func (raydiumAMMv4Detector) ProgramID() solana.PublicKey { return RaydiumAMMv4ProgramID }
+
+// MatchesLogs busca el log que emite el programa: "initialize2: InitializeInstruction2 {...}".
+func (raydiumAMMv4Detector) MatchesLogs(logs []string) bool {
+	for _, msg := range programLogs(logs, RaydiumAMMv4ProgramID) {
+		if strings.HasPrefix(msg, "initialize2: ") {
+			return true
+		}
+	}
+	return false
+}
+
+func (raydiumAMMv4Detector) DecodePoolCreated(ix Instruction) (types.PoolCreated, error) {
+	return DecodeRaydiumInitialize2(ix)
+}
+
+// Programas de Raydium con pools de Anchor.
+var (
+	RaydiumCPMMProgramID = solana.MustPublicKeyFromBase58("CPMMoo8L3F4NbTegBCKVNunggL7H1ZpdTHKxQB5qKP1C")
+	RaydiumCLMMProgramID = solana.MustPublicKeyFromBase58("CAMMCzo5YL8w4VFF8KVHrK22GGUsp5VTaW7grrKgrWqK")
+)
+
+const (
+	DEXRaydiumCPMM = "raydium-cpmm"
+	DEXRaydiumCLMM = "raydium-clmm"
+)
+
+// RaydiumCPMM detecta los pools de producto constante (CP-Swap) creados con initialize.
+// Cuentas: 0 creator, 3 pool_state, 4/5 token_0/1_mint, 6 lp_mint, 10/11 token_0/1_vault.
+// Argumentos: init_amount_0, init_amount_1, open_time (u64).
+func RaydiumCPMM() Detector {
+	return newAnchorDetector(DEXRaydiumCPMM, RaydiumCPMMProgramID, anchorPoolInstruction{
+		name:     "initialize",
+		accounts: poolAccounts{pool: 3, baseMint: 4, quoteMint: 5, lpMint: 6, baseVault: 10, quoteVault: 11, creator: 0},
+		args:     amountsArgs(0, 16),
+	})
+}
+
+// RaydiumCLMM detecta los pools de liquidez concentrada creados con create_pool.
+// Cuentas: 0 pool_creator, 2 pool_state, 3/4 token_mint_0/1, 5/6 token_vault_0/1.
+// Argumentos: sqrt_price_x64 (u128), open_time (u64).
+func RaydiumCLMM() Detector {
+	return newAnchorDetector(DEXRaydiumCLMM, RaydiumCLMMProgramID, anchorPoolInstruction{
+		name:     "create_pool",
+		accounts: poolAccounts{pool: 2, baseMint: 3, quoteMint: 4, lpMint: -1, baseVault: 5, quoteVault: 6, creator: 0},
+		args:     amountsArgs(-1, 16),
+	})
+}
@@ -0,0 +1,86 @@
+package dex_test
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"gosol/dex"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func initialize2Data(openTime, initPc, initCoin uint64) []byte {
+	data := []byte{1, 254}
+	data = binary.LittleEndian.AppendUint64(data, openTime)
+	data = binary.LittleEndian.AppendUint64(data, initPc)
+	return binary.LittleEndian.AppendUint64(data, initCoin)
+}
+
+func TestFindPoolsCreatedInInnerInstruction(t *testing.T) {
+	wsol := solana.SolMint
+	token := solana.NewWallet().PublicKey()
+	pool := solana.NewWallet().PublicKey()
+	lpMint := solana.NewWallet().PublicKey()
+	coinVault := solana.NewWallet().PublicKey()
+	pcVault := solana.NewWallet().PublicKey()
+	creator := solana.NewWallet().PublicKey()
+	router := solana.NewWallet().PublicKey()
+
+	// índices: 0 creator, 1 router, 2 raydium, 3 pool, 4 lp, 5 coin, 6 pc, 7/8 vaults, 9.. relleno
+	keys := solana.PublicKeySlice{creator, router, dex.RaydiumAMMv4ProgramID, pool, lpMint, token, wsol, coinVault, pcVault}
+	filler := uint16(len(keys))
+	keys = append(keys, solana.NewWallet().PublicKey())
+
+	accounts := []uint16{filler, filler, filler, filler, 3, filler, filler, 4, 5, 6, 7, 8, filler, filler, filler, filler, filler, 0}
+	tx := &solana.Transaction{
+		Signatures: []solana.Signature{{1, 2, 3}},
+		Message: solana.Message{
+			AccountKeys: keys[:len(keys)-1],
+			Instructions: []solana.CompiledInstruction{
+				{ProgramIDIndex: 1, Accounts: []uint16{0}, Data: []byte{9}},
+			},
+		},
+	}
+	// la última cuenta llega por una lookup table (transacción v0)
+	meta := &rpc.TransactionMeta{
+		LoadedAddresses: rpc.LoadedAddresses{ReadOnly: solana.PublicKeySlice{keys[len(keys)-1]}},
+		InnerInstructions: []rpc.InnerInstruction{{
+			Index: 0,
+			Instructions: []solana.CompiledInstruction{
+				{ProgramIDIndex: 2, Accounts: accounts, Data: initialize2Data(1700000000, 79_000_000_000, 206_900_000_000_000)},
+			},
+		}},
+	}
+
+	pools, err := dex.NewRegistry(dex.RaydiumAMMv4()).FindPoolsCreated(tx, meta)
+	require.NoError(t, err)
+	require.Len(t, pools, 1)
+
+	got := pools[0]
+	assert.Equal(t, dex.DEXRaydiumAMMv4, got.DEX)
+	assert.Equal(t, pool.String(), got.Pool)
+	assert.Equal(t, token.String(), got.BaseMint)
+	assert.Equal(t, wsol.String(), got.QuoteMint)
+	assert.Equal(t, token.String(), got.Token())
+	assert.Equal(t, lpMint.String(), got.LPMint)
+	assert.Equal(t, coinVault.String(), got.BaseVault)
+	assert.Equal(t, pcVault.String(), got.QuoteVault)
+	assert.Equal(t, creator.String(), got.Creator)
+	assert.Equal(t, time.Unix(1700000000, 0), got.OpenTime)
+	assert.Equal(t, uint64(79_000_000_000), got.InitQuoteAmount)
+	assert.Equal(t, uint64(206_900_000_000_000), got.InitBaseAmount)
+	assert.Equal(t, tx.Signatures[0].String(), got.Signature)
+}
+
+func TestDecodeRaydiumIgnoresOtherInstructions(t *testing.T) {
+	// un swap (tag 9) del mismo programa no es una creación de pool
+	_, err := dex.DecodeRaydiumInitialize2(dex.Instruction{ProgramID: dex.RaydiumAMMv4ProgramID, Data: []byte{9, 0, 0}})
+	assert.ErrorIs(t, err, dex.ErrNotPoolCreation)
+
+	_, err = dex.DecodeRaydiumInitialize2(dex.Instruction{ProgramID: dex.RaydiumAMMv4ProgramID, Data: initialize2Data(0, 1, 1)})
+	assert.ErrorContains(t, err, "expected at least 18 accounts")
+}
@@ -0,0 +1,168 @@
+package dex
+
+import (
+	"errors"
+	"fmt"
+	"gosol/types"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Detector reconoce la creación de pools de un DEX.
+type Detector interface {
+	// Name identifica al DEX; es el valor de types.PoolCreated.DEX.
+	Name() string
+	// ProgramID es el programa del DEX (a cuyos logs hay que suscribirse).
+	ProgramID() solana.PublicKey
+	// MatchesLogs indica si los logs de una transacción pueden contener la creación de un
+	// pool, para no pedir por RPC cada swap del programa.
+	MatchesLogs(logs []string) bool
+	// DecodePoolCreated decodifica una instrucción del programa. Devuelve ErrNotPoolCreation
+	// si la instrucción no crea un pool.
+	DecodePoolCreated(ix Instruction) (types.PoolCreated, error)
+}
+
+// Registry agrupa los detectores habilitados, indexados por programa.
+type Registry struct {
+	detectors []Detector
+	byProgram map[solana.PublicKey]Detector
+}
+
+func NewRegistry(detectors ...Detector) *Registry {
+	r := &Registry{byProgram: make(map[solana.PublicKey]Detector, len(detectors))}
+	for _, d := range detectors {
+		r.detectors = append(r.detectors, d)
+		r.byProgram[d.ProgramID()] = d
+	}
+	return r
+}
+
+// Detectors devuelve todos los detectores conocidos.
+func Detectors() []Detector {
+	return []Detector{
+		RaydiumAMMv4(),
+		RaydiumCPMM(),
+		RaydiumCLMM(),
+		MeteoraDLMM(),
+		MeteoraDynamic(),
+		OrcaWhirlpool(),
+	}
+}
+
+// NewRegistryFor arma un registro con los detectores nombrados (ver Detectors).
+func NewRegistryFor(names []string) (*Registry, error) {
+	known := make(map[string]Detector)
+	for _, d := range Detectors() {
+		known[d.Name()] = d
+	}
+	var detectors []Detector
+	for _, name := range names {
+		d, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown dex %q", name)
+		}
+		detectors = append(detectors, d)
+	}
+	return NewRegistry(detectors...), nil
+}
+
+// Names devuelve los nombres de todos los detectores conocidos.
+func Names() []string {
+	var names []string
+	for _, d := range Detectors() {
+		names = append(names, d.Name())
+	}
+	return names
+}
+
+// Detectors devuelve los detectores habilitados.
+func (r *Registry) Detectors() []Detector {
+	return r.detectors
+}
+
+// MatchesLogs indica si algún detector reconoce los logs. Sin logs no se puede descartar
+// nada y se considera que sí.
+func (r *Registry) MatchesLogs(logs []string) bool {
+	if len(logs) == 0 {
+		return true
+	}
+	for _, d := range r.detectors {
+		if d.MatchesLogs(logs) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchingLogs devuelve los nombres de los detectores que reconocen los logs.
+func (r *Registry) MatchingLogs(logs []string) []string {
+	var names []string
+	for _, d := range r.detectors {
+		if d.MatchesLogs(logs) {
+			names = append(names, d.Name())
+		}
+	}
+	return names
+}
+
+// FindPoolsCreated busca en la transacción (incluidas las instrucciones internas) las
+// creaciones de pools de los DEX habilitados. Slot y BlockTime quedan a cargo de quien llama.
+func (r *Registry) FindPoolsCreated(tx *solana.Transaction, meta *rpc.TransactionMeta) ([]types.PoolCreated, error) {
+	instructions, err := Instructions(tx, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	var pools []types.PoolCreated
+	var errs []error
+	for _, ix := range instructions {
+		d, ok := r.byProgram[ix.ProgramID]
+		if !ok {
+			continue
+		}
+		pool, err := d.DecodePoolCreated(ix)
+		if errors.Is(err, ErrNotPoolCreation) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", d.Name(), err))
+			continue
+		}
+		if len(tx.Signatures) > 0 {
+			pool.Signature = tx.Signatures[0].String()
+		}
+		pools = append(pools, pool)
+	}
+	return pools, errors.Join(errs...)
+}
+
+// programLogs devuelve los mensajes "Program log: ..." que emitió el programa mientras era
+// el que estaba en ejecución: los de los programas que invoca (p. ej. SPL Token al crear
+// una cuenta) quedan afuera.
+func programLogs(logs []string, programID solana.PublicKey) []string {
+	id := programID.String()
+	var stack, messages []string
+	for _, line := range logs {
+		rest, ok := strings.CutPrefix(line, "Program ")
+		if !ok {
+			continue
+		}
+		if msg, ok := strings.CutPrefix(rest, "log: "); ok {
+			if len(stack) > 0 && stack[len(stack)-1] == id {
+				messages = append(messages, msg)
+			}
+			continue
+		}
+		// "Program <id> invoke [n]", "Program <id> success", "Program <id> failed: ..."
+		program, action, _ := strings.Cut(rest, " ")
+		switch {
+		case strings.HasPrefix(action, "invoke"):
+			stack = append(stack, program)
+		case (action == "success" || strings.HasPrefix(action, "failed")) && len(stack) > 0:
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return messages
+}
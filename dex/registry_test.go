@@ -0,0 +1,135 @@
+package dex_test
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"gosol/dex"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func anchorData(instruction string, args ...uint64) []byte {
+	sum := sha256.Sum256([]byte("global:" + instruction))
+	data := sum[:8]
+	for _, arg := range args {
+		data = binary.LittleEndian.AppendUint64(data, arg)
+	}
+	return data
+}
+
+func randomKeys(n int) solana.PublicKeySlice {
+	keys := make(solana.PublicKeySlice, n)
+	for i := range keys {
+		keys[i] = solana.NewWallet().PublicKey()
+	}
+	return keys
+}
+
+func TestRegistryDecodesEnabledDEXes(t *testing.T) {
+	registry, err := dex.NewRegistryFor([]string{dex.DEXRaydiumCPMM, dex.DEXOrcaWhirlpool})
+	require.NoError(t, err)
+
+	// CPMM initialize: 0 creator, 3 pool, 4/5 mints, 6 lp, 10/11 vaults
+	cpmm := randomKeys(13)
+	cpmm[4] = solana.SolMint
+	cpmm[12] = dex.RaydiumCPMMProgramID
+	// Whirlpool initialize_pool_v2: 1/2 mints, 5 funder, 6 pool, 7/8 vaults
+	orca := randomKeys(12)
+	orca[11] = dex.OrcaWhirlpoolProgramID
+
+	keys := append(append(solana.PublicKeySlice{}, cpmm...), orca...)
+	indices := func(from, n int) []uint16 {
+		idx := make([]uint16, n)
+		for i := range idx {
+			idx[i] = uint16(from + i)
+		}
+		return idx
+	}
+	tx := &solana.Transaction{
+		Signatures: []solana.Signature{{7}},
+		Message: solana.Message{
+			AccountKeys: keys,
+			Instructions: []solana.CompiledInstruction{
+				{ProgramIDIndex: 12, Accounts: indices(0, 12), Data: anchorData("initialize", 5_000_000_000, 800_000_000_000, 1700000000)},
+				// un swap de CPMM no crea pool
+				{ProgramIDIndex: 12, Accounts: indices(0, 12), Data: anchorData("swap_base_input", 1, 1)},
+				{ProgramIDIndex: 24, Accounts: indices(13, 11), Data: anchorData("initialize_pool_v2", 64)},
+			},
+		},
+	}
+
+	pools, err := registry.FindPoolsCreated(tx, &rpc.TransactionMeta{})
+	require.NoError(t, err)
+	require.Len(t, pools, 2)
+
+	assert.Equal(t, dex.DEXRaydiumCPMM, pools[0].DEX)
+	assert.Equal(t, cpmm[3].String(), pools[0].Pool)
+	assert.Equal(t, cpmm[5].String(), pools[0].Token())
+	assert.Equal(t, cpmm[6].String(), pools[0].LPMint)
+	assert.Equal(t, cpmm[0].String(), pools[0].Creator)
+	assert.Equal(t, uint64(5_000_000_000), pools[0].InitBaseAmount)
+	assert.Equal(t, uint64(800_000_000_000), pools[0].InitQuoteAmount)
+	assert.Equal(t, time.Unix(1700000000, 0), pools[0].OpenTime)
+
+	assert.Equal(t, dex.DEXOrcaWhirlpool, pools[1].DEX)
+	assert.Equal(t, orca[6].String(), pools[1].Pool)
+	assert.Equal(t, orca[1].String(), pools[1].BaseMint)
+	assert.Equal(t, orca[2].String(), pools[1].QuoteMint)
+	assert.Equal(t, orca[7].String(), pools[1].BaseVault)
+	assert.Equal(t, orca[5].String(), pools[1].Creator)
+	assert.Empty(t, pools[1].LPMint)
+	assert.Equal(t, tx.Signatures[0].String(), pools[1].Signature)
+}
+
+func TestRegistryMatchesLogs(t *testing.T) {
+	registry := dex.NewRegistry(dex.RaydiumAMMv4(), dex.MeteoraDLMM(), dex.RaydiumCPMM())
+	invoke := func(program solana.PublicKey, lines ...string) []string {
+		logs := append([]string{"Program " + program.String() + " invoke [1]"}, lines...)
+		return append(logs, "Program "+program.String()+" success")
+	}
+
+	assert.True(t, registry.MatchesLogs(invoke(dex.RaydiumAMMv4ProgramID, "Program log: initialize2: InitializeInstruction2 { nonce: 254 }")))
+	assert.True(t, registry.MatchesLogs(invoke(dex.MeteoraDLMMProgramID, "Program log: Instruction: InitializeLbPair")))
+	assert.False(t, registry.MatchesLogs(invoke(dex.MeteoraDLMMProgramID, "Program log: Instruction: Swap")))
+	// la instrucción tiene que venir del programa del DEX, no de otro
+	assert.False(t, registry.MatchesLogs(invoke(solana.NewWallet().PublicKey(), "Program log: Instruction: InitializeLbPair")))
+
+	// un swap que crea la ATA del destino: SPL Token loguea InitializeAccount3 e
+	// InitializeImmutableOwner, que no son el "Initialize" de CPMM
+	token := solana.TokenProgramID.String()
+	swap := []string{
+		"Program ComputeBudget111111111111111111111111111111 invoke [1]",
+		"Program ComputeBudget111111111111111111111111111111 success",
+		"Program " + dex.RaydiumCPMMProgramID.String() + " invoke [1]",
+		"Program log: Instruction: SwapBaseInput",
+		"Program " + token + " invoke [2]",
+		"Program log: Instruction: InitializeImmutableOwner",
+		"Program " + token + " consumed 1405 of 180000 compute units",
+		"Program " + token + " success",
+		"Program " + token + " invoke [2]",
+		"Program log: Instruction: InitializeAccount3",
+		"Program " + token + " success",
+		"Program " + token + " invoke [2]",
+		"Program log: Instruction: Initialize",
+		"Program " + token + " success",
+		"Program " + dex.RaydiumCPMMProgramID.String() + " success",
+	}
+	assert.False(t, registry.MatchesLogs(swap))
+	assert.Empty(t, registry.MatchingLogs(swap))
+	assert.Equal(t, []string{dex.DEXRaydiumCPMM}, registry.MatchingLogs(invoke(dex.RaydiumCPMMProgramID,
+		"Program log: Instruction: Initialize",
+		"Program "+token+" invoke [2]",
+		"Program log: Instruction: InitializeMint2",
+		"Program "+token+" success",
+	)))
+	assert.True(t, registry.MatchesLogs(nil), "sin logs no se puede descartar la transacción")
+
+	_, err := dex.NewRegistryFor([]string{"uniswap"})
+	assert.ErrorContains(t, err, `unknown dex "uniswap"`)
+}
@@ -1,33 +1,62 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"gosol/config"
 	"gosol/monitor"
+	"gosol/rules"
 	"gosol/telegramadapter"
+	"gosol/types"
 	"gosol/ui"
 	"os"
+	"os/signal"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/joho/godotenv"
 )
 
-var websocketURL string
-var apiKey string
-var pubkey string
-var apiBaseURL string
-
 func main() {
-	pubkey = os.Getenv("RAY_FEE_PUBKEY")
-	apiBaseURL = os.Getenv("API_BASE_URL")
+	if len(os.Args) > 2 && os.Args[1] == "telegram" && os.Args[2] == "login" {
+		telegramLogin(os.Args[3:])
+		return
+	}
 
-	websocketURL = os.Getenv("WEBSOCKET_URL")
-	apiKey = os.Getenv("API_KEY")
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuración inválida:\n%v\n", err)
+		os.Exit(1)
+	}
 
-	app := monitor.NewApp()
-	// app.Run()
+	app, err := monitor.NewApp(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Al iniciar el monitor: %v\n", err)
+		os.Exit(1)
+	}
+	// el notifier se engancha antes de que arranquen los workers de reportes
+	if cfg.Telegram.Bot.Enabled {
+		bot := telegramadapter.NewBotAPI(cfg.Telegram.Bot.APIURL, cfg.Telegram.Bot.Token, nil)
+		notifier := telegramadapter.NewNotifier(cfg.Telegram.Bot, bot, app.StatusUpdates)
+		app.ApiClient.OnReport = notifier.Notify
+		go notifier.Run(app.Ctx)
+		if cfg.Telegram.Bot.Commands {
+			commands := telegramadapter.NewCommands(cfg.Telegram.Bot, bot, app, notifier)
+			app.ApiClient.OnReport = func(report types.Report, result rules.Result) {
+				notifier.Notify(report, result)
+				commands.Report(report, result)
+			}
+			go commands.Run(app.Ctx)
+		}
+	}
+	app.Run()
 
-	telegramclient := telegramadapter.NewTelegramClient(app)
-	telegramclient.Run()
+	if cfg.Telegram.Enabled {
+		telegramclient, err := telegramadapter.NewTelegramClient(app, cfg.Telegram)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Al iniciar el adaptador de Telegram: %v\n", err)
+			os.Exit(1)
+		}
+		go telegramclient.Run(app.Ctx)
+	}
 
 	// Inicializar el modelo de UI con el StateManager
 	model := ui.NewModel(app)
@@ -35,8 +64,26 @@ func main() {
 	p := tea.NewProgram(model, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Al iniciar la aplicación: %v\n", err)
-		app.Stop()
 	}
-	// Cerrar el WSManager al finalizar
-	wsMgr.Close()
+	// Cancelar el contexto de la app y cerrar el almacenamiento al finalizar
+	app.Cancel()
+	app.StateManager.Close()
+}
+
+// telegramLogin implementa `gosol telegram login [flags]`: inicia la sesión de Telegram de forma
+// interactiva y la guarda para los próximos arranques.
+func telegramLogin(args []string) {
+	// se valida la sección telegram aunque el adaptador esté deshabilitado
+	cfg, err := config.Load(append([]string{"-telegram=true"}, args...))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuración inválida:\n%v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := telegramadapter.Login(ctx, cfg.Telegram, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Al iniciar sesión en Telegram: %v\n", err)
+		os.Exit(1)
+	}
 }
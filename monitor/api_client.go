@@ -1,87 +1,172 @@
 package monitor
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"gosol/config"
+	"gosol/rules"
+	"gosol/storage"
 	"gosol/types"
-	"net/http"
-	"time"
+	"slices"
+	"sync"
+	"sync/atomic"
 )
 
+// APIClient obtiene los reportes de riesgo de los mints a través de un ReportProvider
+// y actualiza el StateManager con el resultado.
 type APIClient struct {
+	cfg           config.ReportConfig
+	provider      ReportProvider
 	stateManager  *StateManager
 	statusUpdates chan<- StatusMessage
 	tokenUpdates  chan<- []types.TokenInfo
-	// requestThrottle chan struct{}
+
+	// OnReport, si está definido, recibe cada reporte evaluado (también los descartados).
+	// Se asigna antes de Start.
+	OnReport func(report types.Report, result rules.Result)
+
+	// cola de pedidos asíncronos que atienden cfg.Workers workers (ver Start)
+	queue chan string
+	// onDemand son los pedidos de un usuario (RequestReportOnDemand): no se descartan y se
+	// atienden antes que la cola. urgent despierta a los workers.
+	onDemandMu sync.Mutex
+	onDemand   []string
+	urgent     chan struct{}
+	busy       atomic.Int32
+	dropped    atomic.Int64
+	wg         sync.WaitGroup
 }
 
-func NewAPIClient(stateManager *StateManager, statusUpdates chan<- StatusMessage, tokenUpdates chan<- []types.TokenInfo) *APIClient {
+func NewAPIClient(cfg config.ReportConfig, provider ReportProvider, stateManager *StateManager, statusUpdates chan<- StatusMessage, tokenUpdates chan<- []types.TokenInfo) *APIClient {
 	return &APIClient{
+		cfg:           cfg,
+		provider:      provider,
 		stateManager:  stateManager,
 		statusUpdates: statusUpdates,
 		tokenUpdates:  tokenUpdates,
-		// requestThrottle: make(chan struct{}, 10), // Limitar a 10 solicitudes concurrentes
+		queue:         make(chan string, max(cfg.QueueSize, 1)),
+		urgent:        make(chan struct{}, max(cfg.Workers, 1)),
 	}
 }
 
-func (api *APIClient) FetchAndProcessReport(mint string) {
-	go func() {
-		// api.requestThrottle <- struct{}{}        // Adquirir un "permiso" para hacer la solicitud
-		// defer func() { <-api.requestThrottle }() // Liberar el "permiso" al finalizar
-
-		report, err := api.fetchTokenReport(mint)
-		if err != nil {
-			api.statusUpdates <- StatusMessage{Level: ERR, Message: fmt.Sprintf("Error fetching report for %s: %v", mint, err)}
-			return
-		}
-
-		if report.Score > 8000 {
-			api.handleHighRiskToken(report)
-			return
-		}
+// Start lanza los workers que atienden la cola de FetchAndProcessReport hasta que se cancele ctx.
+func (api *APIClient) Start(ctx context.Context) {
+	for i := 0; i < max(api.cfg.Workers, 1); i++ {
+		api.wg.Add(1)
+		go func() {
+			defer api.wg.Done()
+			for {
+				mint, ok := api.nextOnDemand()
+				if !ok {
+					select {
+					case <-ctx.Done():
+						return
+					case <-api.urgent:
+						continue
+					case mint = <-api.queue:
+					}
+				}
+				api.busy.Add(1)
+				api.ProcessReport(ctx, mint)
+				api.busy.Add(-1)
+			}
+		}()
+	}
+}
 
-		api.stateManager.UpdateMintState(mint, report)
-		api.stateManager.SendTokenUpdates(api.tokenUpdates)
-	}()
+// Wait espera a que terminen los workers (después de cancelar el contexto de Start).
+func (api *APIClient) Wait() {
+	api.wg.Wait()
 }
 
-func (api *APIClient) fetchTokenReport(mint string) (types.Report, error) {
-	var report types.Report
-	var err error
-	for attempts := 0; attempts < 3; attempts++ {
-		report, err = api.tryFetchTokenReport(mint)
-		if err == nil {
-			return report, nil
-		}
-		time.Sleep(time.Duration(attempts+1) * time.Second) // Esperar más tiempo en cada intento
+// FetchAndProcessReport encola el pedido del reporte de un mint detectado. Si la cola está
+// llena el pedido se descarta: con rescan.enabled el re-escaneo lo vuelve a intentar más
+// tarde. Los pedidos de un usuario van por RequestReportOnDemand.
+func (api *APIClient) FetchAndProcessReport(mint string) {
+	select {
+	case api.queue <- mint:
+	default:
+		api.dropped.Add(1)
+		api.statusUpdates <- StatusMessage{Level: WARN, Message: fmt.Sprintf("Report queue full, dropping request for %s", mint)}
 	}
-	return report, err
 }
 
-func (api *APIClient) tryFetchTokenReport(mint string) (types.Report, error) {
-	url := fmt.Sprintf("%s/v1/tokens/%s/report", apiBaseURL, mint)
-	resp, err := http.Get(url)
+// QueueStats devuelve el estado de la cola de reportes.
+func (api *APIClient) QueueStats() (depth, capacity, busy int, dropped int64) {
+	return len(api.queue), cap(api.queue), int(api.busy.Load()), api.dropped.Load()
+}
+
+// ProcessReport pide el reporte de un mint y actualiza el estado. Bloquea hasta terminar;
+// FetchAndProcessReport es la versión asíncrona.
+func (api *APIClient) ProcessReport(ctx context.Context, mint string) {
+	report, err := api.provider.FetchReport(ctx, mint)
 	if err != nil {
-		return types.Report{}, err
+		if ctx.Err() != nil {
+			return
+		}
+		api.statusUpdates <- StatusMessage{Level: ERR, Message: fmt.Sprintf("Error fetching report for %s: %v", mint, err)}
+		api.stateManager.RecordStatus(mint, storage.StatusError, err.Error())
+		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return types.Report{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if report.Mint == "" {
+		report.Mint = mint
 	}
-
-	var report types.Report
-	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
-		return types.Report{}, err
+	result := api.stateManager.Evaluate(report)
+	if api.OnReport != nil {
+		api.OnReport(report, result)
+	}
+	if result.Verdict == config.VerdictIgnore {
+		// en los re-escaneos no repetir el aviso si ya estaba descartado
+		discarded := api.stateManager.LastStatus(mint) == storage.StatusDiscarded
+		// si un re-escaneo lo descarta, la fila pasa a mostrar el reporte nuevo y no el viejo;
+		// si nunca se mostró, la foto se guarda igual para comparar los re-escaneos
+		if _, shown := api.stateManager.GetReport(mint); shown {
+			api.stateManager.UpdateMintState(mint, report)
+			api.stateManager.SendTokenUpdates(api.tokenUpdates)
+		} else {
+			api.stateManager.RecordDiscardedReport(mint, report)
+		}
+		if !discarded {
+			api.handleHighRiskToken(report, result)
+			api.stateManager.RecordStatus(mint, storage.StatusDiscarded, fmt.Sprintf("score %d", result.Score))
+		}
+		return
 	}
 
-	return report, nil
+	api.stateManager.UpdateMintState(mint, report)
+	api.stateManager.SendTokenUpdates(api.tokenUpdates)
 }
 
-func (api *APIClient) handleHighRiskToken(report types.Report) {
-	api.statusUpdates <- StatusMessage{Level: NONE, Message: fmt.Sprintf("💩 Token Sym:[%s]: '%s' Score[%d]", report.TokenMeta.Symbol, report.TokenMeta.Name, report.Score)}
+func (api *APIClient) handleHighRiskToken(report types.Report, result rules.Result) {
+	api.statusUpdates <- StatusMessage{Level: NONE, Message: fmt.Sprintf("%s Token Sym:[%s]: '%s' Score[%d]", result.Color, report.TokenMeta.Symbol, report.TokenMeta.Name, result.Score)}
 }
 
+// RequestReportOnDemand pide el reporte de un mint para un usuario (la UI, /scan...). No
+// bloquea ni se descarta aunque la cola esté llena: se atiende antes que los pedidos en espera.
 func (api *APIClient) RequestReportOnDemand(mint string) {
-	api.FetchAndProcessReport(mint)
+	api.onDemandMu.Lock()
+	if slices.Contains(api.onDemand, mint) {
+		api.onDemandMu.Unlock()
+		return
+	}
+	api.onDemand = append(api.onDemand, mint)
+	api.onDemandMu.Unlock()
+
+	select {
+	case api.urgent <- struct{}{}:
+	default:
+		// todos los workers ya tienen un aviso pendiente y van a vaciar onDemand
+	}
+}
+
+func (api *APIClient) nextOnDemand() (string, bool) {
+	api.onDemandMu.Lock()
+	defer api.onDemandMu.Unlock()
+	if len(api.onDemand) == 0 {
+		return "", false
+	}
+	mint := api.onDemand[0]
+	api.onDemand = api.onDemand[1:]
+	return mint, true
 }
@@ -0,0 +1,166 @@
+package monitor_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gosol/config"
+	"gosol/monitor"
+	"gosol/storage"
+	"gosol/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPIClient(t *testing.T, handler http.HandlerFunc) (*monitor.APIClient, *monitor.StateManager, chan monitor.StatusMessage) {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	cfg := config.Default().Report
+	cfg.APIBaseURL = srv.URL
+	cfg.BackoffBase = 10 * time.Millisecond
+	cfg.BackoffMax = 50 * time.Millisecond
+
+	statusCh := make(chan monitor.StatusMessage, 10)
+	tokenCh := make(chan []types.TokenInfo, 10)
+	stateMgr := monitor.NewStateManager(nil, statusCh)
+	provider := monitor.NewHTTPReportProvider("rugcheck", srv.URL, cfg, monitor.NewRateLimiter(cfg.RateLimit, nil))
+	return monitor.NewAPIClient(cfg, provider, stateMgr, statusCh, tokenCh), stateMgr, statusCh
+}
+
+func TestAPIClientHonorsRetryAfter(t *testing.T) {
+	var calls int32
+	api, stateMgr, _ := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"mint":"mint","score":500,"tokenMeta":{"symbol":"ABC"}}`))
+	})
+
+	start := time.Now()
+	api.ProcessReport(context.Background(), "mint")
+
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	report, ok := stateMgr.GetReport("mint")
+	require.True(t, ok)
+	assert.Equal(t, 500, report.Score)
+}
+
+func TestAPIClientDoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	api, _, statusCh := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	api.ProcessReport(context.Background(), "mint")
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	require.Len(t, statusCh, 1)
+	assert.Contains(t, (<-statusCh).Message, "unexpected status code: 404")
+}
+
+func TestAPIClientDropsWhenQueueIsFull(t *testing.T) {
+	api, _, statusCh := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	// sin Start no hay workers: la cola se llena y el resto se descarta
+	_, capacity, _, _ := api.QueueStats()
+	for i := 0; i < capacity+2; i++ {
+		api.FetchAndProcessReport("mint")
+	}
+
+	depth, _, _, dropped := api.QueueStats()
+	assert.Equal(t, capacity, depth)
+	assert.Equal(t, int64(2), dropped)
+	assert.Len(t, statusCh, 2)
+}
+
+func TestAPIClientPrioritizesOnDemandRequests(t *testing.T) {
+	var mu sync.Mutex
+	var fetched []string
+	release := make(chan struct{})
+	api, _, statusCh := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		// /v1/tokens/{mint}/report
+		fetched = append(fetched, strings.Split(r.URL.Path, "/")[3])
+		mu.Unlock()
+		<-release
+	})
+	t.Cleanup(func() { close(release) })
+
+	// con la cola llena un pedido de un usuario no se descarta y sale antes que el resto
+	_, capacity, _, _ := api.QueueStats()
+	for i := 0; i < capacity; i++ {
+		api.FetchAndProcessReport("background")
+	}
+	api.RequestReportOnDemand("urgent")
+	api.RequestReportOnDemand("urgent")
+	_, _, _, dropped := api.QueueStats()
+	assert.Zero(t, dropped)
+	assert.Empty(t, statusCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	api.Start(ctx)
+	workers := config.Default().Report.Workers
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(fetched) == workers
+	}, 5*time.Second, 10*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	urgent := 0
+	for _, mint := range fetched {
+		if mint == "urgent" {
+			urgent++
+		}
+	}
+	assert.Equal(t, 1, urgent, "los pedidos repetidos se juntan")
+}
+
+func TestAPIClientUpdatesRowWhenRescanDiscards(t *testing.T) {
+	var score atomic.Int32
+	score.Store(500)
+	api, stateMgr, _ := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(types.Report{TokenMeta: types.TokenMeta{Symbol: "RUG"}, Score: int(score.Load())})
+	})
+
+	api.ProcessReport(context.Background(), "mint")
+	require.Len(t, stateMgr.Tokens(), 1)
+	assert.Equal(t, config.VerdictAlert, stateMgr.Tokens()[0].Verdict)
+
+	// el re-escaneo lo descarta: la fila muestra el score nuevo en vez de quedar con el viejo
+	score.Store(9000)
+	api.ProcessReport(context.Background(), "mint")
+	tokens := stateMgr.Tokens()
+	require.Len(t, tokens, 1)
+	assert.Equal(t, int64(9000), tokens[0].Score)
+	assert.Equal(t, config.VerdictIgnore, tokens[0].Verdict)
+	assert.Equal(t, storage.StatusDiscarded, stateMgr.LastStatus("mint"))
+
+	// un mint que nunca se mostró no entra a la tabla, pero su reporte se guarda
+	api.ProcessReport(context.Background(), "other")
+	assert.Len(t, stateMgr.Tokens(), 1)
+	require.Len(t, stateMgr.History("other"), 1)
+	assert.Equal(t, 9000, stateMgr.History("other")[0].Report.Score)
+
+	// si un re-escaneo lo rescata, se avisa el cambio respecto del descartado
+	score.Store(500)
+	api.ProcessReport(context.Background(), "other")
+	assert.Len(t, stateMgr.Tokens(), 2)
+	deltas := stateMgr.Deltas("other")
+	require.Len(t, deltas, 1)
+	assert.Contains(t, deltas[0].String(), "score went 9000 → 500")
+}
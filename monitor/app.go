@@ -3,93 +3,234 @@ package monitor
 import (
 	"context"
 	"fmt"
+	"gosol/config"
+	"gosol/dex"
+	"gosol/rpcpool"
+	"gosol/rules"
+	"gosol/storage"
 	"gosol/types"
 	_ "net/http/pprof"
-	"os"
+	"time"
 
-	"github.com/gagliardetto/solana-go/rpc/ws"
-	"github.com/joho/godotenv"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
 )
 
-var websocketURL string
-var apiKey string
-var pubkey string
-var apiBaseURL string
-
 type App struct {
+	Config         *config.Config
 	wsClient       *WebSocketClient
 	logProcessor   *LogProcessor
 	transactionMgr *TransactionManager
+	tracker        *CommitmentTracker
 	ApiClient      *APIClient
+	Rescans        *RescanScheduler
+	PumpFun        *PumpFunWatcher
 	StateManager   *StateManager
+	Dedupe         *Deduper
 	StatusUpdates  chan StatusMessage
-	LogCh          chan *ws.LogResult
+	Ingest         *IngestQueue
 	TokenUpdates   chan []types.TokenInfo
 	Ctx            context.Context
 	Cancel         context.CancelFunc
 }
 
-func NewApp() *App {
-	err := godotenv.Load()
-	if err != nil {
-		fmt.Println("Error loading .env file")
-	}
-
+// NewApp arma todos los componentes del monitor a partir de una configuración ya validada (ver config.Load).
+func NewApp(cfg *config.Config) (*App, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	statusCh := make(chan StatusMessage, 100)
 	tokenCh := make(chan []types.TokenInfo, 100)
-	logCh := make(chan *ws.LogResult, 100)
+	ingest := NewIngestQueue("logs", cfg.Ingest)
+
+	rpcClient, pool, err := rpcpool.NewClient(cfg.RPC)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	pool.OnFailover = func(endpoint string, err error) {
+		statusCh <- StatusMessage{Level: WARN, Message: fmt.Sprintf("RPC endpoint %s failed, switching: %v", endpoint, err)}
+	}
 
-	pubkey = os.Getenv("RAY_FEE_PUBKEY")
-	apiBaseURL = os.Getenv("API_BASE_URL")
+	var store storage.Store = storage.NopStore{}
+	if cfg.Storage.Enabled {
+		bolt, err := storage.OpenBolt(cfg.Storage.Path)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		store = bolt
+	}
 
-	websocketURL = os.Getenv("WEBSOCKET_URL")
-	apiKey = os.Getenv("API_KEY")
+	stateMgr := NewStateManager(store, statusCh)
+	stateMgr.SetRules(rules.NewEngine(cfg.Scoring))
+	if retention := cfg.Storage.Retention(); retention > 0 {
+		if _, err := stateMgr.Prune(time.Now().Add(-retention)); err != nil {
+			cancel()
+			store.Close()
+			return nil, fmt.Errorf("pruning store: %w", err)
+		}
+	}
+	if err := stateMgr.Restore(); err != nil {
+		cancel()
+		store.Close()
+		return nil, fmt.Errorf("restoring state: %w", err)
+	}
 
-	if websocketURL == "" || apiKey == "" || pubkey == "" || apiBaseURL == "" {
-		panic(fmt.Sprintf("Environment variables are not set properly: WEBSOCKET_URL=%s, API_KEY=%s, RAY_FEE_PUBKEY=%s, API_BASE_URL=%s", websocketURL, apiKey, pubkey, apiBaseURL))
+	provider, err := NewReportProvider(cfg.Report, rpcClient)
+	if err != nil {
+		cancel()
+		store.Close()
+		return nil, err
+	}
+	apiCli := NewAPIClient(cfg.Report, provider, stateMgr, statusCh, tokenCh)
+	rescans := NewRescanScheduler(cfg.Rescan, apiCli)
+	if cfg.Rescan.Enabled {
+		stateMgr.OnMintAdded(rescans.Track)
+		// retomar el seguimiento de los mints recuperados que todavía estén en alguna etapa
+		for mint, detectedAt := range stateMgr.DetectedMints() {
+			rescans.Track(mint, detectedAt)
+		}
+	}
+	dedupe := NewDeduper(cfg.Dedupe)
+	registry, err := dex.NewRegistryFor(cfg.DEX.Enabled)
+	if err != nil {
+		cancel()
+		store.Close()
+		return nil, fmt.Errorf("dex.enabled: %w", err)
+	}
+	transMgr := NewTransactionManager(cfg.Transactions, rpcClient, apiCli, stateMgr, registry, dedupe, statusCh, tokenCh)
+	wsCli := NewWebSocketClient(cfg.Solana, statusCh)
+	tracker := NewCommitmentTracker(cfg.Tracking, wsCli, rpcClient, stateMgr, transMgr.HandleTransaction, statusCh, tokenCh)
+	transMgr.OnProcessed = tracker.Processed
+	logProc := NewLogProcessor(tracker, cfg.Tracking.Detect, registry, dedupe, statusCh)
+
+	var backfill *Backfiller
+	if cfg.Backfill.Enabled {
+		// getSignaturesForAddress no trae los logs, así que no se puede filtrar por DEX: las firmas
+		// recuperadas se piden directamente, sin suscribirse a cada una como las del tracker
+		// (serían miles después de una caída en los programas de los DEX)
+		backfill = NewBackfiller(cfg.Backfill, rpcClient, dedupe, func(sig solana.Signature) { transMgr.HandleTransaction(sig) }, statusCh)
+		wsCli.OnReconnect = func() {
+			go func() {
+				if _, err := backfill.Backfill(ctx); err != nil {
+					statusCh <- StatusMessage{Level: ERR, Message: fmt.Sprintf("Backfill failed: %v", err)}
+				}
+			}()
+		}
+	}
+	for _, d := range registry.Detectors() {
+		name, account := d.Name(), d.ProgramID()
+		if name == dex.DEXRaydiumAMMv4 {
+			// los pools de AMM v4 se siguen por la cuenta de fees, que solo aparece al crearlos
+			name, account = "raydium", solana.MustPublicKeyFromBase58(cfg.Solana.RayFeePubkey)
+		}
+		sub := ingest.Subscription(name, account)
+		sub.Commitment = rpc.CommitmentType(cfg.Tracking.Detect)
+		if backfill != nil {
+			sub = backfill.Watch(sub)
+		}
+		if err := wsCli.Add(sub); err != nil {
+			cancel()
+			store.Close()
+			return nil, err
+		}
 	}
 
-	stateMgr := NewStateManager()
-	apiCli := NewAPIClient(stateMgr, statusCh, tokenCh)
-	transMgr := NewTransactionManager(apiCli, stateMgr, statusCh, tokenCh)
-	logProc := NewLogProcessor(transMgr, statusCh)
-	wsCli := NewWebSocketClient(logCh, statusCh)
+	var pumpFun *PumpFunWatcher
+	if cfg.PumpFun.Enabled {
+		pumpFun, err = NewPumpFunWatcher(cfg.PumpFun, NewIngestQueue("pumpfun", cfg.Ingest), stateMgr, apiCli, dedupe, statusCh, tokenCh)
+		if err != nil {
+			cancel()
+			store.Close()
+			return nil, err
+		}
+		if err := wsCli.Add(pumpFun.Ingest().Subscription("pump.fun", pumpFun.ProgramID())); err != nil {
+			cancel()
+			store.Close()
+			return nil, err
+		}
+	}
 
 	return &App{
+		Config:         cfg,
 		wsClient:       wsCli,
 		logProcessor:   logProc,
 		transactionMgr: transMgr,
+		tracker:        tracker,
 		ApiClient:      apiCli,
+		Rescans:        rescans,
+		PumpFun:        pumpFun,
 		StateManager:   stateMgr,
+		Dedupe:         dedupe,
 		StatusUpdates:  statusCh,
 		TokenUpdates:   tokenCh,
-		LogCh:          logCh,
+		Ingest:         ingest,
 		Ctx:            ctx,
 		Cancel:         cancel,
-	}
+	}, nil
 }
 
 func (app *App) Run() {
-	go app.wsClient.Reconnect(app.Ctx)
+	// mostrar en la tabla lo recuperado de sesiones anteriores
+	go app.StateManager.SendTokenUpdates(app.TokenUpdates)
+	if app.Config.Storage.Enabled && app.Config.Storage.CompactInterval > 0 {
+		go app.maintainStorage(app.Config.Storage)
+	}
+
+	app.ApiClient.Start(app.Ctx)
+	app.transactionMgr.Start(app.Ctx)
+	go app.tracker.Run(app.Ctx)
+	if app.Config.Rescan.Enabled {
+		go app.Rescans.Run(app.Ctx)
+	}
+	if app.PumpFun != nil {
+		go app.PumpFun.Run(app.Ctx)
+	}
+
+	go app.wsClient.Run(app.Ctx)
 	// done := make(chan struct{})
 
 	go func() {
 		// defer close(done)
 		for {
-			select {
-			case logMsg := <-app.LogCh:
-				app.logProcessor.ProcessLog(logMsg)
-			case <-app.Ctx.Done():
+			logMsg, ok := app.Ingest.Pop(app.Ctx)
+			if !ok {
 				return
 			}
+			app.logProcessor.ProcessLog(logMsg)
 		}
 	}()
 
 	// <-done
 }
 
+// maintainStorage aplica la política de retención y compacta el almacenamiento periódicamente.
+func (app *App) maintainStorage(cfg config.StorageConfig) {
+	ticker := time.NewTicker(cfg.CompactInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.Ctx.Done():
+			return
+		case <-ticker.C:
+			if retention := cfg.Retention(); retention > 0 {
+				deleted, err := app.StateManager.Prune(time.Now().Add(-retention))
+				if err != nil {
+					app.updateStatus(fmt.Sprintf("Error pruning storage: %v", err), ERR)
+					continue
+				}
+				if deleted > 0 {
+					app.updateStatus(fmt.Sprintf("Storage: %d expired records removed", deleted), INFO)
+				}
+			}
+			if err := app.StateManager.Compact(); err != nil {
+				app.updateStatus(fmt.Sprintf("Error compacting storage: %v", err), ERR)
+			}
+		}
+	}
+}
+
 func (app *App) updateStatus(message string, level LogLevel) {
 	app.StatusUpdates <- StatusMessage{Level: level, Message: message}
 }
@@ -97,7 +238,12 @@ func (app *App) updateStatus(message string, level LogLevel) {
 func (app *App) Stop() {
 	app.Cancel()
 	app.transactionMgr.Wait()
+	app.ApiClient.Wait()
+	app.StateManager.Close()
 	close(app.StatusUpdates)
 	close(app.TokenUpdates)
-	close(app.LogCh)
+	app.Ingest.Close()
+	if app.PumpFun != nil {
+		app.PumpFun.Ingest().Close()
+	}
 }
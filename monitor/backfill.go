@@ -0,0 +1,214 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gosol/config"
+	"slices"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+)
+
+// Backfiller recupera las transacciones que se perdieron mientras el websocket estuvo caído.
+// Recuerda las últimas firmas recibidas por cada cuenta vigilada y, después de reconectar,
+// pide con getSignaturesForAddress las posteriores a la más nueva que esté confirmada y las
+// entrega a handle (TransactionManager.HandleTransaction), salteando las que ya se habían
+// visto (ver Deduper).
+type Backfiller struct {
+	cfg           config.BackfillConfig
+	rpcClient     *rpc.Client
+	dedupe        *Deduper
+	handle        func(solana.Signature)
+	statusUpdates chan<- StatusMessage
+
+	mu      sync.Mutex
+	cursors map[solana.PublicKey][]backfillCursor
+	names   map[solana.PublicKey]string
+	running sync.Mutex
+}
+
+// maxBackfillCursors es cuántas firmas recientes se recuerdan por cuenta. Los logs llegan
+// con tracking.detect (processed): si la última se perdió en un fork se usa la anterior.
+const maxBackfillCursors = 16
+
+// backfillCursor es una transacción recibida de una cuenta.
+type backfillCursor struct {
+	Signature solana.Signature
+	Slot      uint64
+}
+
+func NewBackfiller(cfg config.BackfillConfig, rpcClient *rpc.Client, dedupe *Deduper, handle func(solana.Signature), statusUpdates chan<- StatusMessage) *Backfiller {
+	return &Backfiller{
+		cfg:           cfg,
+		rpcClient:     rpcClient,
+		dedupe:        dedupe,
+		handle:        handle,
+		statusUpdates: statusUpdates,
+		cursors:       make(map[solana.PublicKey][]backfillCursor),
+		names:         make(map[solana.PublicKey]string),
+	}
+}
+
+// Watch envuelve una suscripción a logs para que, además de entregarlos, registre cada
+// firma y se puedan recuperar las que falten después de una caída.
+func (b *Backfiller) Watch(sub Subscription) Subscription {
+	b.mu.Lock()
+	b.names[sub.Account] = sub.Name
+	b.mu.Unlock()
+
+	account, handle := sub.Account, sub.OnLogs
+	sub.OnLogs = func(msg *ws.LogResult) {
+		b.Seen(account, msg.Value.Signature, msg.Context.Slot)
+		handle(msg)
+	}
+	return sub
+}
+
+// Seen registra que llegó la transacción de account.
+func (b *Backfiller) Seen(account solana.PublicKey, signature solana.Signature, slot uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cursors := b.cursors[account]
+	if len(cursors) > 0 && slot < cursors[len(cursors)-1].Slot {
+		return
+	}
+	cursors = append(cursors, backfillCursor{Signature: signature, Slot: slot})
+	if len(cursors) > maxBackfillCursors {
+		cursors = cursors[len(cursors)-maxBackfillCursors:]
+	}
+	b.cursors[account] = cursors
+}
+
+// Backfill procesa las firmas posteriores al último cursor de cada cuenta vigilada y
+// devuelve cuántas se encolaron. Si ya hay un backfill en curso no hace nada.
+func (b *Backfiller) Backfill(ctx context.Context) (int, error) {
+	if !b.running.TryLock() {
+		return 0, nil
+	}
+	defer b.running.Unlock()
+
+	b.mu.Lock()
+	cursors := make(map[solana.PublicKey][]backfillCursor, len(b.cursors))
+	for account, list := range b.cursors {
+		cursors[account] = slices.Clone(list)
+	}
+	b.mu.Unlock()
+
+	total := 0
+	var errs []error
+	for account, list := range cursors {
+		name := b.name(account)
+		cursor, ok, err := b.confirmedCursor(ctx, list)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("backfilling %s: %w", name, err))
+			continue
+		}
+		if !ok {
+			// sin una firma confirmada desde donde seguir, getSignaturesForAddress recorrería
+			// historia vieja; se vuelve a empezar con lo que llegue por los logs
+			b.forget(account, list)
+			b.updateStatus(fmt.Sprintf("Backfill %s: no confirmed cursor (dropped on a fork?), skipping", name), WARN)
+			continue
+		}
+		missed, truncated, err := b.missedSignatures(ctx, account, cursor.Signature)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("backfilling %s: %w", name, err))
+			continue
+		}
+		if truncated {
+			b.updateStatus(fmt.Sprintf("Backfill %s: more than %d missed signatures, only the latest are processed", name, b.cfg.MaxSignatures), WARN)
+		}
+
+		queued := 0
+		// getSignaturesForAddress devuelve de la más nueva a la más vieja
+		for _, sig := range slices.Backward(missed) {
+			if sig.Err != nil {
+				continue
+			}
+			b.Seen(account, sig.Signature, sig.Slot)
+			if !b.dedupe.FirstSignature(sig.Signature) {
+				continue
+			}
+			b.handle(sig.Signature)
+			queued++
+		}
+		if queued > 0 {
+			b.updateStatus(fmt.Sprintf("Backfill %s: %d missed transactions since slot %d", name, queued, cursor.Slot), INFO)
+		}
+		total += queued
+	}
+	return total, errors.Join(errs...)
+}
+
+// confirmedCursor devuelve la firma más nueva de cursors que llegó al menos a confirmed.
+func (b *Backfiller) confirmedCursor(ctx context.Context, cursors []backfillCursor) (backfillCursor, bool, error) {
+	sigs := make([]solana.Signature, len(cursors))
+	for i, cursor := range cursors {
+		sigs[i] = cursor.Signature
+	}
+	out, err := b.rpcClient.GetSignatureStatuses(ctx, true, sigs...)
+	if err != nil {
+		return backfillCursor{}, false, err
+	}
+	for i := len(cursors) - 1; i >= 0; i-- {
+		if i >= len(out.Value) || out.Value[i] == nil {
+			continue
+		}
+		switch out.Value[i].ConfirmationStatus {
+		case rpc.ConfirmationStatusConfirmed, rpc.ConfirmationStatusFinalized:
+			return cursors[i], true, nil
+		}
+	}
+	return backfillCursor{}, false, nil
+}
+
+// forget descarta los cursores revisados de account; los que llegaron mientras tanto quedan.
+func (b *Backfiller) forget(account solana.PublicKey, checked []backfillCursor) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cursors[account] = slices.DeleteFunc(b.cursors[account], func(c backfillCursor) bool {
+		return slices.Contains(checked, c)
+	})
+}
+
+// missedSignatures pagina hacia atrás desde la más reciente hasta until, sin pasar de
+// cfg.MaxSignatures. truncated indica que quedaron firmas sin pedir.
+func (b *Backfiller) missedSignatures(ctx context.Context, account solana.PublicKey, until solana.Signature) (sigs []*rpc.TransactionSignature, truncated bool, err error) {
+	commitment := rpc.CommitmentType(b.cfg.Commitment)
+	var before solana.Signature
+	for len(sigs) < b.cfg.MaxSignatures {
+		limit := min(b.cfg.PageSize, b.cfg.MaxSignatures-len(sigs))
+		page, err := b.rpcClient.GetSignaturesForAddressWithOpts(ctx, account, &rpc.GetSignaturesForAddressOpts{
+			Limit:      &limit,
+			Before:     before,
+			Until:      until,
+			Commitment: commitment,
+		})
+		if err != nil {
+			return sigs, false, err
+		}
+		sigs = append(sigs, page...)
+		if len(page) < limit {
+			return sigs, false, nil
+		}
+		before = page[len(page)-1].Signature
+	}
+	return sigs, true, nil
+}
+
+func (b *Backfiller) name(account solana.PublicKey) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if name, ok := b.names[account]; ok {
+		return name
+	}
+	return account.String()
+}
+
+func (b *Backfiller) updateStatus(message string, level LogLevel) {
+	b.statusUpdates <- StatusMessage{Level: level, Message: message}
+}
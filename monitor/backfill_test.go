@@ -0,0 +1,149 @@
+package monitor_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"gosol/config"
+	"gosol/monitor"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSignaturesRPC responde getSignaturesForAddress con el historial de cada cuenta (de la
+// más nueva a la más vieja) respetando before, until y limit.
+// Las firmas de los historiales figuran confirmadas en getSignatureStatuses; el resto, como
+// las que se perdieron en un fork, no existen.
+func fakeSignaturesRPC(t *testing.T, histories map[string][]map[string]any) *rpc.Client {
+	return newFakeRPC(t, map[string]rpcMethod{
+		"getSignaturesForAddress": signaturesForAddress(histories),
+		"getSignatureStatuses": func(params []json.RawMessage) (any, error) {
+			var sigs []string
+			if err := rpcParam(params, 0, &sigs); err != nil {
+				return nil, err
+			}
+			value := make([]any, len(sigs))
+			for i, sig := range sigs {
+				for _, history := range histories {
+					for _, entry := range history {
+						if entry["signature"] == sig {
+							value[i] = map[string]any{"slot": entry["slot"], "confirmations": nil, "err": nil, "confirmationStatus": "confirmed"}
+						}
+					}
+				}
+			}
+			return map[string]any{"context": map[string]any{"slot": 200}, "value": value}, nil
+		},
+	})
+}
+
+func signaturesForAddress(histories map[string][]map[string]any) rpcMethod {
+	return func(params []json.RawMessage) (any, error) {
+		var account string
+		if err := rpcParam(params, 0, &account); err != nil {
+			return nil, err
+		}
+		var opts struct {
+			Limit  int    `json:"limit"`
+			Before string `json:"before"`
+			Until  string `json:"until"`
+		}
+		if err := rpcParam(params, 1, &opts); err != nil {
+			return nil, err
+		}
+
+		page := []map[string]any{}
+		started := opts.Before == ""
+		for _, entry := range histories[account] {
+			sig := entry["signature"].(string)
+			if sig == opts.Until || len(page) == opts.Limit {
+				break
+			}
+			if started {
+				page = append(page, entry)
+			}
+			if sig == opts.Before {
+				started = true
+			}
+		}
+		return page, nil
+	}
+}
+
+func TestBackfillerFeedsMissedSignatures(t *testing.T) {
+	sigs := make([]solana.Signature, 6)
+	for i := range sigs {
+		sigs[i] = solana.Signature{byte(i + 1)}
+	}
+	entry := func(i int, failed bool) map[string]any {
+		e := map[string]any{"signature": sigs[i].String(), "slot": 100 + i, "err": nil}
+		if failed {
+			e["err"] = map[string]any{"InstructionError": []any{0, "Custom"}}
+		}
+		return e
+	}
+	// sigs[1] es la última recibida antes de la caída; sigs[3] llegó igual por los logs
+	// (por otra suscripción) y sigs[4] falló
+	history := []map[string]any{entry(5, false), entry(4, true), entry(3, false), entry(2, false), entry(1, false), entry(0, false)}
+
+	account := solana.NewWallet().PublicKey()
+	other := solana.NewWallet().PublicKey()
+	histories := map[string][]map[string]any{account.String(): history}
+
+	var handled []solana.Signature
+	cfg := config.BackfillConfig{Enabled: true, PageSize: 2, MaxSignatures: 10, Commitment: config.CommitmentConfirmed}
+	dedupe := monitor.NewDeduper(config.Default().Dedupe)
+	b := monitor.NewBackfiller(cfg, fakeSignaturesRPC(t, histories), dedupe, func(sig solana.Signature) { handled = append(handled, sig) }, drainStatus(context.Background()))
+
+	b.Seen(account, sigs[0], 100)
+	b.Seen(account, sigs[1], 101)
+	b.Seen(other, sigs[3], 103)
+	dedupe.FirstSignature(sigs[3])
+
+	n, err := b.Backfill(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []solana.Signature{sigs[2], sigs[5]}, handled, "se procesan de la más vieja a la más nueva")
+
+	// el cursor avanzó: un segundo backfill no repite nada
+	handled = nil
+	n, err = b.Backfill(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Empty(t, handled)
+}
+
+func TestBackfillerSkipsCursorsDroppedOnAFork(t *testing.T) {
+	sigs := make([]solana.Signature, 4)
+	for i := range sigs {
+		sigs[i] = solana.Signature{byte(i + 1)}
+	}
+	dropped, forked := solana.Signature{0xd1}, solana.Signature{0xd2}
+	entry := func(i int) map[string]any {
+		return map[string]any{"signature": sigs[i].String(), "slot": 100 + i, "err": nil}
+	}
+	account, orphan := solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey()
+	histories := map[string][]map[string]any{
+		account.String(): {entry(3), entry(2), entry(1), entry(0)},
+		// sin un cursor confirmado se recorrería toda esta historia vieja
+		orphan.String(): {entry(3), entry(2), entry(1), entry(0)},
+	}
+
+	var handled []solana.Signature
+	cfg := config.BackfillConfig{Enabled: true, PageSize: 10, MaxSignatures: 10, Commitment: config.CommitmentConfirmed}
+	b := monitor.NewBackfiller(cfg, fakeSignaturesRPC(t, histories), monitor.NewDeduper(config.Default().Dedupe), func(sig solana.Signature) { handled = append(handled, sig) }, drainStatus(context.Background()))
+
+	// la última firma vista (processed) se perdió en un fork: se sigue desde la anterior
+	b.Seen(account, sigs[1], 101)
+	b.Seen(account, dropped, 102)
+	b.Seen(orphan, forked, 102)
+
+	n, err := b.Backfill(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []solana.Signature{sigs[2], sigs[3]}, handled)
+}
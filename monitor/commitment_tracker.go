@@ -0,0 +1,327 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"gosol/config"
+	"gosol/types"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+)
+
+// CommitmentTracker sigue cada transacción candidata a crear un pool por los niveles de
+// commitment: la ve en processed (por los logs), la pide cuando se confirma (getTransaction
+// no acepta processed) y espera a que se finalice. Las que no avanzan en cfg.DropAfter y el
+// nodo ya no conoce se marcan como perdidas (config.CommitmentDropped).
+type CommitmentTracker struct {
+	cfg           config.TrackingConfig
+	wsClient      *WebSocketClient
+	rpcClient     *rpc.Client
+	stateManager  *StateManager
+	fetch         func(signature solana.Signature, dexes ...string)
+	statusUpdates chan<- StatusMessage
+	tokenUpdates  chan<- []types.TokenInfo
+
+	mu         sync.Mutex
+	detections map[solana.Signature]*detection
+
+	finalized atomic.Int64
+	dropped   atomic.Int64
+}
+
+// detection es una transacción en seguimiento.
+type detection struct {
+	dexes      []string
+	commitment string
+	// since es cuándo llegó a commitment.
+	since time.Time
+	// fetched indica que ya se procesó la transacción y pools son los que creó.
+	fetched bool
+	pools   []types.PoolCreated
+}
+
+// TrackingStats es una foto del CommitmentTracker.
+type TrackingStats struct {
+	Pending   int
+	Finalized int64
+	Dropped   int64
+}
+
+// maxSignatureStatuses es el máximo de firmas por llamada a getSignatureStatuses.
+const maxSignatureStatuses = 256
+
+func NewCommitmentTracker(cfg config.TrackingConfig, wsClient *WebSocketClient, rpcClient *rpc.Client, stateManager *StateManager, fetch func(solana.Signature, ...string), statusUpdates chan<- StatusMessage, tokenUpdates chan<- []types.TokenInfo) *CommitmentTracker {
+	return &CommitmentTracker{
+		cfg:           cfg,
+		wsClient:      wsClient,
+		rpcClient:     rpcClient,
+		stateManager:  stateManager,
+		fetch:         fetch,
+		statusUpdates: statusUpdates,
+		tokenUpdates:  tokenUpdates,
+		detections:    make(map[solana.Signature]*detection),
+	}
+}
+
+// Track empieza a seguir una transacción vista con el commitment dado. dexes son los DEX que
+// reconocieron sus logs (ver TransactionManager.HandleTransaction).
+func (ct *CommitmentTracker) Track(signature solana.Signature, commitment string, dexes ...string) {
+	ct.mu.Lock()
+	if _, ok := ct.detections[signature]; ok {
+		ct.mu.Unlock()
+		return
+	}
+	ct.detections[signature] = &detection{dexes: dexes, commitment: config.CommitmentProcessed, since: time.Now()}
+	ct.mu.Unlock()
+
+	if commitment == config.CommitmentProcessed {
+		ct.alert(config.CommitmentProcessed, fmt.Sprintf("Pool creation seen at processed: %s (%s)", signature, strings.Join(dexes, ", ")), INFO)
+		ct.watch(signature, config.CommitmentConfirmed)
+		return
+	}
+	ct.advance(signature, commitment)
+}
+
+// watch se suscribe a la firma para enterarse de cuándo llega a commitment.
+func (ct *CommitmentTracker) watch(signature solana.Signature, commitment string) {
+	err := ct.wsClient.Add(Subscription{
+		Name:       subscriptionName(signature, commitment),
+		Kind:       SubscriptionSignature,
+		Signature:  signature,
+		Commitment: rpc.CommitmentType(commitment),
+		OnSignature: func(msg *ws.SignatureResult) {
+			if msg.Value.Err != nil {
+				// la transacción falló: no creó nada
+				ct.forget(signature)
+				return
+			}
+			ct.advance(signature, commitment)
+		},
+	})
+	if err != nil {
+		ct.updateStatus(fmt.Sprintf("Error watching %s: %v", signature, err), ERR)
+	}
+}
+
+func subscriptionName(signature solana.Signature, commitment string) string {
+	return "commitment/" + commitment + "/" + signature.String()
+}
+
+// advance registra que la transacción llegó a commitment. Al confirmarse se pide (ver
+// fetch) y se espera a que se finalice.
+func (ct *CommitmentTracker) advance(signature solana.Signature, commitment string) {
+	ct.mu.Lock()
+	d, ok := ct.detections[signature]
+	if !ok || commitmentRank(commitment) <= commitmentRank(d.commitment) {
+		ct.mu.Unlock()
+		return
+	}
+	previous := d.commitment
+	d.commitment, d.since = commitment, time.Now()
+	dexes, fetched, pools := d.dexes, d.fetched, d.pools
+	if commitment == config.CommitmentFinalized && fetched {
+		delete(ct.detections, signature)
+	}
+	ct.mu.Unlock()
+
+	// si se salteó un nivel, la suscripción a ese ya no hace falta
+	ct.wsClient.Remove(subscriptionName(signature, config.CommitmentConfirmed))
+
+	if previous == config.CommitmentProcessed {
+		ct.fetch(signature, dexes...)
+	}
+	if commitment == config.CommitmentConfirmed {
+		ct.watch(signature, config.CommitmentFinalized)
+		return
+	}
+	ct.wsClient.Remove(subscriptionName(signature, config.CommitmentFinalized))
+	if fetched {
+		ct.finalize(pools)
+	}
+}
+
+// Processed registra el resultado de pedir la transacción: los pools que creó (ninguno si
+// no era una creación). Lo llama el TransactionManager.
+func (ct *CommitmentTracker) Processed(signature solana.Signature, pools []types.PoolCreated) {
+	ct.mu.Lock()
+	d, ok := ct.detections[signature]
+	commitment := config.CommitmentConfirmed
+	if ok {
+		d.fetched, d.pools = true, pools
+		commitment = d.commitment
+		if len(pools) == 0 || commitment == config.CommitmentFinalized {
+			delete(ct.detections, signature)
+		}
+	}
+	ct.mu.Unlock()
+
+	if ok && len(pools) == 0 {
+		ct.wsClient.Remove(subscriptionName(signature, config.CommitmentFinalized))
+	}
+	for _, pool := range pools {
+		ct.alert(config.CommitmentConfirmed, fmt.Sprintf("========== New Token Found: %s (%s pool %s) ==========", pool.Token(), pool.DEX, pool.Pool), INFO)
+	}
+	if commitment == config.CommitmentFinalized {
+		ct.finalize(pools)
+	}
+}
+
+func (ct *CommitmentTracker) finalize(pools []types.PoolCreated) {
+	if len(pools) == 0 {
+		return
+	}
+	ct.finalized.Add(1)
+	for _, pool := range pools {
+		ct.stateManager.SetCommitment(pool.Token(), config.CommitmentFinalized)
+		ct.alert(config.CommitmentFinalized, fmt.Sprintf("Token %s finalized (%s pool %s)", pool.Token(), pool.DEX, pool.Pool), INFO)
+	}
+	ct.stateManager.SendTokenUpdates(ct.tokenUpdates)
+}
+
+// Run revisa periódicamente las detecciones demoradas hasta que se cancele ctx.
+func (ct *CommitmentTracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(min(ct.cfg.DropAfter/4, 5*time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ct.Check(ctx); err != nil && ctx.Err() == nil {
+				ct.updateStatus(fmt.Sprintf("Error checking commitments: %v", err), ERR)
+			}
+		}
+	}
+}
+
+// Check consulta el estado de las detecciones que no avanzaron en cfg.DropAfter: las que
+// el nodo ya no conoce se marcan como perdidas y las que avanzaron sin que llegara la
+// notificación (p. ej. durante una reconexión) se actualizan.
+func (ct *CommitmentTracker) Check(ctx context.Context) error {
+	cutoff := time.Now().Add(-ct.cfg.DropAfter)
+	ct.mu.Lock()
+	var late []solana.Signature
+	for sig, d := range ct.detections {
+		if d.since.Before(cutoff) {
+			late = append(late, sig)
+		}
+	}
+	ct.mu.Unlock()
+
+	for chunk := range slices.Chunk(late, maxSignatureStatuses) {
+		res, err := ct.rpcClient.GetSignatureStatuses(ctx, true, chunk...)
+		if err != nil {
+			return err
+		}
+		for i, status := range res.Value {
+			sig := chunk[i]
+			switch {
+			case status == nil:
+				ct.drop(sig)
+			case status.Err != nil:
+				ct.forget(sig)
+			case status.ConfirmationStatus == rpc.ConfirmationStatusFinalized:
+				ct.advance(sig, config.CommitmentFinalized)
+				ct.forgetFinalized(sig)
+			case status.ConfirmationStatus == rpc.ConfirmationStatusConfirmed:
+				ct.advance(sig, config.CommitmentConfirmed)
+				ct.touch(sig)
+			default:
+				ct.touch(sig)
+			}
+		}
+	}
+	return nil
+}
+
+// touch reinicia la espera de una detección que sigue en curso.
+func (ct *CommitmentTracker) touch(signature solana.Signature) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	if d, ok := ct.detections[signature]; ok {
+		d.since = time.Now()
+	}
+}
+
+// forgetFinalized deja de seguir una transacción finalizada que no se pudo pedir.
+func (ct *CommitmentTracker) forgetFinalized(signature solana.Signature) {
+	ct.mu.Lock()
+	d, ok := ct.detections[signature]
+	stale := ok && d.commitment == config.CommitmentFinalized && d.since.Before(time.Now().Add(-ct.cfg.DropAfter))
+	ct.mu.Unlock()
+	if stale {
+		ct.forget(signature)
+	}
+}
+
+// drop marca como perdida una transacción que el nodo ya no conoce.
+func (ct *CommitmentTracker) drop(signature solana.Signature) {
+	ct.mu.Lock()
+	d, ok := ct.detections[signature]
+	if !ok {
+		ct.mu.Unlock()
+		return
+	}
+	commitment, pools := d.commitment, d.pools
+	ct.mu.Unlock()
+	ct.forget(signature)
+	ct.dropped.Add(1)
+
+	if len(pools) == 0 {
+		ct.alert(config.CommitmentDropped, fmt.Sprintf("Transaction %s dropped at %s", signature, commitment), WARN)
+		return
+	}
+	for _, pool := range pools {
+		ct.stateManager.SetCommitment(pool.Token(), config.CommitmentDropped)
+		ct.alert(config.CommitmentDropped, fmt.Sprintf("Token %s dropped: pool creation %s never got past %s", pool.Token(), signature, commitment), WARN)
+	}
+	ct.stateManager.SendTokenUpdates(ct.tokenUpdates)
+}
+
+// forget deja de seguir la transacción.
+func (ct *CommitmentTracker) forget(signature solana.Signature) {
+	ct.mu.Lock()
+	delete(ct.detections, signature)
+	ct.mu.Unlock()
+	ct.wsClient.Remove(subscriptionName(signature, config.CommitmentConfirmed))
+	ct.wsClient.Remove(subscriptionName(signature, config.CommitmentFinalized))
+}
+
+// Stats devuelve cuántas detecciones siguen en curso y cuántas terminaron.
+func (ct *CommitmentTracker) Stats() TrackingStats {
+	ct.mu.Lock()
+	pending := len(ct.detections)
+	ct.mu.Unlock()
+	return TrackingStats{Pending: pending, Finalized: ct.finalized.Load(), Dropped: ct.dropped.Load()}
+}
+
+func commitmentRank(commitment string) int {
+	switch commitment {
+	case config.CommitmentProcessed:
+		return 1
+	case config.CommitmentConfirmed:
+		return 2
+	case config.CommitmentFinalized:
+		return 3
+	}
+	return 0
+}
+
+// alert avisa solo si el nivel está en cfg.Alerts.
+func (ct *CommitmentTracker) alert(level, message string, severity LogLevel) {
+	if slices.Contains(ct.cfg.Alerts, level) {
+		ct.updateStatus(message, severity)
+	}
+}
+
+func (ct *CommitmentTracker) updateStatus(message string, level LogLevel) {
+	ct.statusUpdates <- StatusMessage{Level: level, Message: message}
+}
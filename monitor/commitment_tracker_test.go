@@ -0,0 +1,147 @@
+package monitor_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"gosol/config"
+	"gosol/monitor"
+	"gosol/storage"
+	"gosol/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStatusesRPC responde getSignatureStatuses con el estado de cada firma (sin estado = null).
+func fakeStatusesRPC(t *testing.T) (*rpc.Client, func(sig solana.Signature, status string)) {
+	var mu sync.Mutex
+	statuses := make(map[string]string)
+	client := newFakeRPC(t, map[string]rpcMethod{
+		"getSignatureStatuses": func(params []json.RawMessage) (any, error) {
+			var sigs []string
+			if err := rpcParam(params, 0, &sigs); err != nil {
+				return nil, err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			value := make([]any, len(sigs))
+			for i, sig := range sigs {
+				if status, ok := statuses[sig]; ok {
+					value[i] = map[string]any{"slot": 10, "confirmations": nil, "err": nil, "confirmationStatus": status}
+				}
+			}
+			return map[string]any{"context": map[string]any{"slot": 10}, "value": value}, nil
+		},
+	})
+	return client, func(sig solana.Signature, status string) {
+		mu.Lock()
+		defer mu.Unlock()
+		statuses[sig.String()] = status
+	}
+}
+
+func TestCommitmentTrackerFollowsDetections(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := newFakeWS(t)
+	wsc := monitor.NewWebSocketClient(config.SolanaConfig{WebsocketURL: f.url(), Commitment: config.CommitmentConfirmed}, drainStatus(ctx))
+	require.NoError(t, wsc.Connect(ctx))
+	require.NoError(t, wsc.Subscribe(ctx))
+
+	client, setStatus := fakeStatusesRPC(t)
+	status := drainStatus(ctx)
+	sm := monitor.NewStateManager(nil, status)
+	tokens := make(chan []types.TokenInfo, 10)
+	fetched := make(chan solana.Signature, 10)
+	cfg := config.TrackingConfig{Detect: config.CommitmentProcessed, DropAfter: 20 * time.Millisecond, Alerts: []string{config.CommitmentDropped}}
+	tracker := monitor.NewCommitmentTracker(cfg, wsc, client, sm, func(sig solana.Signature, _ ...string) { fetched <- sig }, status, tokens)
+
+	waitFetch := func(want solana.Signature) {
+		t.Helper()
+		select {
+		case sig := <-fetched:
+			assert.Equal(t, want, sig)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s not fetched", want)
+		}
+	}
+	// la baja de una suscripción se manda en paralelo con el alta de la siguiente
+	expectSwap := func() wsRequest {
+		t.Helper()
+		got := map[string]wsRequest{}
+		for range 2 {
+			select {
+			case req := <-f.requests:
+				got[req.Method] = req
+			case <-time.After(5 * time.Second):
+				t.Fatal("timeout waiting for resubscription")
+			}
+		}
+		require.Contains(t, got, "signatureUnsubscribe")
+		require.Contains(t, got, "signatureSubscribe")
+		return got["signatureSubscribe"]
+	}
+	newPool := func(sig solana.Signature) types.PoolCreated {
+		pool := types.PoolCreated{
+			DEX:        "raydium-cpmm",
+			Signature:  sig.String(),
+			Pool:       solana.NewWallet().PublicKey().String(),
+			BaseMint:   solana.NewWallet().PublicKey().String(),
+			QuoteMint:  solana.SolMint.String(),
+			Commitment: config.CommitmentConfirmed,
+		}
+		sm.AddPool(pool)
+		return pool
+	}
+
+	// processed → confirmed (se pide la transacción) → finalized
+	a := solana.Signature{1}
+	tracker.Track(a, config.CommitmentProcessed, "raydium-cpmm")
+	confirmReq := f.expect("signatureSubscribe")
+	assert.Contains(t, string(confirmReq.Params[1]), `"confirmed"`)
+	f.notify("signatureNotification", confirmReq.SubID, `{"context":{"slot":8},"value":{"err":null}}`)
+	waitFetch(a)
+	finalizeReq := expectSwap()
+	assert.Contains(t, string(finalizeReq.Params[1]), `"finalized"`)
+
+	poolA := newPool(a)
+	tracker.Processed(a, []types.PoolCreated{poolA})
+	f.notify("signatureNotification", finalizeReq.SubID, `{"context":{"slot":40},"value":{"err":null}}`)
+	assert.Eventually(t, func() bool {
+		pool, _ := sm.Pool(poolA.Token())
+		return pool.Commitment == config.CommitmentFinalized
+	}, 5*time.Second, 10*time.Millisecond)
+	f.expect("signatureUnsubscribe")
+
+	// b se confirma pero el nodo la pierde; c se confirma sin que llegue la notificación
+	b, c := solana.Signature{2}, solana.Signature{3}
+	tracker.Track(b, config.CommitmentConfirmed)
+	waitFetch(b)
+	f.expect("signatureSubscribe")
+	poolB := newPool(b)
+	tracker.Processed(b, []types.PoolCreated{poolB})
+	tracker.Track(c, config.CommitmentProcessed)
+	f.expect("signatureSubscribe")
+	setStatus(c, "confirmed")
+
+	time.Sleep(2 * cfg.DropAfter)
+	require.NoError(t, tracker.Check(ctx))
+	waitFetch(c)
+
+	pool, _ := sm.Pool(poolB.Token())
+	assert.Equal(t, config.CommitmentDropped, pool.Commitment)
+	history := sm.StatusHistory(poolB.Token())
+	require.NotEmpty(t, history)
+	assert.Equal(t, storage.StatusDropped, history[len(history)-1].Status)
+
+	stats := tracker.Stats()
+	assert.Equal(t, 1, stats.Pending, "c sigue esperando la finalización")
+	assert.Equal(t, int64(1), stats.Finalized)
+	assert.Equal(t, int64(1), stats.Dropped)
+}
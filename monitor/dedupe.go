@@ -0,0 +1,101 @@
+package monitor
+
+import (
+	"container/list"
+	"gosol/config"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Deduper es la capa de deduplicación compartida por todas las fuentes de entrada: las
+// firmas que llegan por los logs o por el backfill y los mints que detectan el
+// TransactionManager, el PumpFunWatcher o Telegram pasan por acá antes de procesarse, así
+// una misma transacción no se pide dos veces y un mismo mint no dispara dos reportes.
+type Deduper struct {
+	signatures *recentSet[solana.Signature]
+	mints      *recentSet[string]
+
+	droppedSignatures atomic.Int64
+	droppedMints      atomic.Int64
+}
+
+func NewDeduper(cfg config.DedupeConfig) *Deduper {
+	return &Deduper{
+		signatures: newRecentSet[solana.Signature](cfg.Signatures.Size, cfg.Signatures.TTL),
+		mints:      newRecentSet[string](cfg.Mints.Size, cfg.Mints.TTL),
+	}
+}
+
+// FirstSignature registra la firma y devuelve true si no se había visto.
+func (d *Deduper) FirstSignature(sig solana.Signature) bool {
+	if d.signatures.Seen(sig) {
+		d.droppedSignatures.Add(1)
+		return false
+	}
+	return true
+}
+
+// FirstMint registra el mint y devuelve true si no se había visto.
+func (d *Deduper) FirstMint(mint string) bool {
+	if d.mints.Seen(mint) {
+		d.droppedMints.Add(1)
+		return false
+	}
+	return true
+}
+
+// Dropped devuelve cuántas firmas y mints repetidos se descartaron.
+func (d *Deduper) Dropped() (signatures, mints int64) {
+	return d.droppedSignatures.Load(), d.droppedMints.Load()
+}
+
+// recentSet es un LRU con vencimiento: recuerda hasta size claves por ttl desde que se
+// vieron por primera vez.
+type recentSet[K comparable] struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	order *list.List // de la más reciente a la más vieja
+	items map[K]*list.Element
+}
+
+type recentEntry[K comparable] struct {
+	key     K
+	expires time.Time
+}
+
+func newRecentSet[K comparable](size int, ttl time.Duration) *recentSet[K] {
+	return &recentSet[K]{
+		size:  size,
+		ttl:   ttl,
+		order: list.New(),
+		items: make(map[K]*list.Element, size),
+	}
+}
+
+// Seen devuelve true si la clave ya estaba (y no venció); si no, la registra.
+func (s *recentSet[K]) Seen(key K) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if el, ok := s.items[key]; ok {
+		if now.Before(el.Value.(*recentEntry[K]).expires) {
+			s.order.MoveToFront(el)
+			return true
+		}
+		s.order.Remove(el)
+		delete(s.items, key)
+	}
+
+	s.items[key] = s.order.PushFront(&recentEntry[K]{key: key, expires: now.Add(s.ttl)})
+	for s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*recentEntry[K]).key)
+	}
+	return false
+}
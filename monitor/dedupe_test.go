@@ -0,0 +1,37 @@
+package monitor_test
+
+import (
+	"testing"
+	"time"
+
+	"gosol/config"
+	"gosol/monitor"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeduperDropsRepeatedSignaturesAndMints(t *testing.T) {
+	d := monitor.NewDeduper(config.DedupeConfig{
+		Signatures: config.DedupeCacheConfig{Size: 2, TTL: time.Hour},
+		Mints:      config.DedupeCacheConfig{Size: 10, TTL: 50 * time.Millisecond},
+	})
+
+	a, b, c := solana.Signature{1}, solana.Signature{2}, solana.Signature{3}
+	assert.True(t, d.FirstSignature(a))
+	assert.True(t, d.FirstSignature(b))
+	assert.False(t, d.FirstSignature(a))
+	// a se usó recién, así que al pasar el tamaño se olvida b
+	assert.True(t, d.FirstSignature(c))
+	assert.False(t, d.FirstSignature(a))
+	assert.True(t, d.FirstSignature(b))
+
+	assert.True(t, d.FirstMint("mint"))
+	assert.False(t, d.FirstMint("mint"))
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, d.FirstMint("mint"), "vencido el ttl se vuelve a procesar")
+
+	signatures, mints := d.Dropped()
+	assert.Equal(t, int64(2), signatures)
+	assert.Equal(t, int64(1), mints)
+}
@@ -0,0 +1,24 @@
+package monitor
+
+import "time"
+
+// Acceso a lo interno del RescanScheduler para los tests de monitor_test.
+
+func (rs *RescanScheduler) Interval(age time.Duration) (time.Duration, bool) {
+	return rs.interval(age)
+}
+
+func (rs *RescanScheduler) WithJitter(d time.Duration) time.Duration {
+	return rs.withJitter(d)
+}
+
+// NextRescan devuelve cuándo toca re-escanear un mint en seguimiento.
+func (rs *RescanScheduler) NextRescan(mint string) (time.Time, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	entry, ok := rs.entries[mint]
+	if !ok {
+		return time.Time{}, false
+	}
+	return entry.next, true
+}
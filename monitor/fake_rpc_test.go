@@ -0,0 +1,56 @@
+package monitor_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// rpcMethod atiende un método JSON-RPC: recibe los params y devuelve el result. Un error se
+// responde como error JSON-RPC.
+type rpcMethod func(params []json.RawMessage) (any, error)
+
+// newFakeRPC levanta un servidor JSON-RPC que atiende los métodos dados. Los problemas del
+// lado del servidor se reportan con t.Errorf (el handler no corre en la goroutine del test)
+// y como error en la respuesta.
+func newFakeRPC(t *testing.T, methods map[string]rpcMethod) *rpc.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     any               `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("fake rpc: decoding request: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		method, ok := methods[req.Method]
+		if !ok {
+			t.Errorf("fake rpc: unexpected method %s", req.Method)
+			resp["error"] = map[string]any{"code": -32601, "message": "method not found"}
+		} else if result, err := method(req.Params); err != nil {
+			t.Errorf("fake rpc: %s: %v", req.Method, err)
+			resp["error"] = map[string]any{"code": -32602, "message": err.Error()}
+		} else {
+			resp["result"] = result
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return rpc.New(srv.URL)
+}
+
+// rpcParam decodifica el parámetro i del pedido en v.
+func rpcParam(params []json.RawMessage, i int, v any) error {
+	if i >= len(params) {
+		return fmt.Errorf("missing param %d", i)
+	}
+	return json.Unmarshal(params[i], v)
+}
@@ -0,0 +1,119 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gosol/config"
+	"gosol/types"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPReportProvider pide el reporte a una API con el formato de rugcheck
+// (GET {baseURL}/v1/tokens/{mint}/report), respetando el rate limit compartido.
+type HTTPReportProvider struct {
+	name       string
+	baseURL    string
+	cfg        config.ReportConfig
+	limiter    *RateLimiter
+	httpClient *http.Client
+}
+
+func NewHTTPReportProvider(name, baseURL string, cfg config.ReportConfig, limiter *RateLimiter) *HTTPReportProvider {
+	return &HTTPReportProvider{
+		name:       name,
+		baseURL:    baseURL,
+		cfg:        cfg,
+		limiter:    limiter,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (hp *HTTPReportProvider) Name() string { return hp.name }
+
+// httpStatusError es una respuesta no exitosa de la API de reportes.
+type httpStatusError struct {
+	code       int
+	retryAfter time.Duration
+	hasRetry   bool
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.code)
+}
+
+// retryable indica si vale la pena reintentar: rate limit, errores del servidor o de red.
+func retryable(err error) bool {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == http.StatusTooManyRequests || statusErr.code >= 500
+	}
+	return true
+}
+
+func (hp *HTTPReportProvider) FetchReport(ctx context.Context, mint string) (types.Report, error) {
+	reportURL := fmt.Sprintf("%s/v1/tokens/%s/report", hp.baseURL, mint)
+	host := ""
+	if u, err := url.Parse(reportURL); err == nil {
+		host = u.Host
+	}
+
+	var report types.Report
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = hp.limiter.Wait(ctx, host); err != nil {
+			return report, err
+		}
+		report, err = hp.tryFetchReport(ctx, reportURL)
+		if err == nil {
+			return stampSource(report, hp.name), nil
+		}
+		if !retryable(err) || attempt >= hp.cfg.MaxRetries {
+			return report, err
+		}
+
+		wait := backoffDelay(attempt, hp.cfg.BackoffBase, hp.cfg.BackoffMax)
+		var statusErr *httpStatusError
+		if errors.As(err, &statusErr) && statusErr.hasRetry {
+			// el servidor dice cuándo volver: frenar a todos los pedidos a ese host
+			wait = statusErr.retryAfter
+			hp.limiter.Pause(host, time.Now().Add(wait))
+		}
+		select {
+		case <-ctx.Done():
+			return report, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (hp *HTTPReportProvider) tryFetchReport(ctx context.Context, reportURL string) (types.Report, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reportURL, nil)
+	if err != nil {
+		return types.Report{}, err
+	}
+	resp, err := hp.httpClient.Do(req)
+	if err != nil {
+		return types.Report{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return types.Report{}, fmt.Errorf("%w: %w", ErrReportNotFound, &httpStatusError{code: resp.StatusCode})
+	}
+	if resp.StatusCode != http.StatusOK {
+		statusErr := &httpStatusError{code: resp.StatusCode}
+		statusErr.retryAfter, statusErr.hasRetry = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return types.Report{}, statusErr
+	}
+
+	var report types.Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return types.Report{}, err
+	}
+
+	return report, nil
+}
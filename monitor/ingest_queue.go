@@ -0,0 +1,300 @@
+package monitor
+
+import (
+	"bufio"
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gosol/config"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+)
+
+// IngestQueue es la cola acotada entre el websocket y quien procesa los logs. El websocket
+// entrega cada mensaje apenas llega (Push) y, si la cola está llena, se aplica la política
+// configurada:
+//   - drop_oldest: se descarta el mensaje más viejo.
+//   - block: Push espera a que haya lugar, frenando la lectura del websocket.
+//   - spill: los mensajes que no entran se escriben en un archivo y se leen, en orden,
+//     cuando la cola se vacía.
+type IngestQueue struct {
+	name string
+	cfg  config.IngestConfig
+
+	mu     sync.Mutex
+	items  *list.List // de ingestItem, el más viejo al frente
+	spill  *spillFile
+	closed bool
+	// ready se señala al encolar; space, al desencolar.
+	ready chan struct{}
+	space chan struct{}
+	done  chan struct{}
+
+	dropped  atomic.Int64
+	spilled  atomic.Int64
+	lag      atomic.Int64 // ns que esperó en la cola el último mensaje procesado
+	lastSlot atomic.Uint64
+}
+
+type ingestItem struct {
+	Received time.Time     `json:"received"`
+	Msg      *ws.LogResult `json:"msg"`
+}
+
+// IngestStats es una foto del estado de una IngestQueue.
+type IngestStats struct {
+	Depth    int
+	Capacity int
+	// Spilled es la cantidad de mensajes esperando en disco.
+	Spilled int
+	Dropped int64
+	// Lag es cuánto esperó en la cola el último mensaje procesado.
+	Lag time.Duration
+	// LastSlot es el slot del último mensaje procesado.
+	LastSlot uint64
+}
+
+func NewIngestQueue(name string, cfg config.IngestConfig) *IngestQueue {
+	return &IngestQueue{
+		name:  name,
+		cfg:   cfg,
+		items: list.New(),
+		ready: make(chan struct{}, 1),
+		space: make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+}
+
+// Subscription es una suscripción a los logs que mencionan account que los encola acá.
+func (q *IngestQueue) Subscription(name string, account solana.PublicKey) Subscription {
+	return Subscription{
+		Name:    name,
+		Kind:    SubscriptionLogs,
+		Account: account,
+		OnLogs:  func(msg *ws.LogResult) { q.Push(msg) },
+	}
+}
+
+// Push encola un mensaje aplicando la política de desborde. Devuelve false si el mensaje
+// se descartó (por la política o porque la cola está cerrada).
+func (q *IngestQueue) Push(msg *ws.LogResult) bool {
+	item := ingestItem{Received: time.Now(), Msg: msg}
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return false
+		}
+		pending := q.spill != nil && q.spill.pending > 0
+		if q.items.Len() < q.cfg.QueueSize && !pending {
+			q.items.PushBack(item)
+			q.mu.Unlock()
+			signal(q.ready)
+			return true
+		}
+
+		switch q.cfg.Overflow {
+		case config.OverflowBlock:
+			q.mu.Unlock()
+			select {
+			case <-q.space:
+			case <-q.done:
+			}
+			continue
+		case config.OverflowSpill:
+			err := q.spillItem(item)
+			q.mu.Unlock()
+			if err != nil {
+				q.dropped.Add(1)
+				return false
+			}
+			q.spilled.Add(1)
+			signal(q.ready)
+			return true
+		default: // config.OverflowDropOldest
+			q.items.Remove(q.items.Front())
+			q.items.PushBack(item)
+			q.mu.Unlock()
+			q.dropped.Add(1)
+			signal(q.ready)
+			return true
+		}
+	}
+}
+
+// spillItem escribe el mensaje al final del archivo de desborde. Se llama con el lock tomado.
+func (q *IngestQueue) spillItem(item ingestItem) error {
+	if q.spill == nil {
+		spill, err := openSpillFile(filepath.Join(q.cfg.SpillDir, "ingest-"+q.name+".jsonl"))
+		if err != nil {
+			return err
+		}
+		q.spill = spill
+	}
+	return q.spill.write(item)
+}
+
+// Pop devuelve el próximo mensaje, esperando a que haya uno. Devuelve false si se canceló
+// ctx o se cerró la cola.
+func (q *IngestQueue) Pop(ctx context.Context) (*ws.LogResult, bool) {
+	for {
+		q.mu.Lock()
+		item, ok := q.next()
+		closed := q.closed
+		q.mu.Unlock()
+
+		if ok {
+			signal(q.space)
+			q.lag.Store(int64(time.Since(item.Received)))
+			if item.Msg.Context.Slot > 0 {
+				q.lastSlot.Store(item.Msg.Context.Slot)
+			}
+			return item.Msg, true
+		}
+		if closed {
+			return nil, false
+		}
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-q.done:
+		case <-q.ready:
+		}
+	}
+}
+
+// next saca el mensaje más viejo: primero los que están en memoria y, cuando se vacía,
+// los del archivo de desborde. Se llama con el lock tomado.
+func (q *IngestQueue) next() (ingestItem, bool) {
+	if front := q.items.Front(); front != nil {
+		q.items.Remove(front)
+		return front.Value.(ingestItem), true
+	}
+	if q.spill == nil || q.spill.pending == 0 {
+		return ingestItem{}, false
+	}
+	item, err := q.spill.read()
+	if err != nil {
+		// el archivo quedó inconsistente: se descarta lo que tenía
+		q.dropped.Add(int64(q.spill.pending))
+		q.spill.reset()
+		return ingestItem{}, false
+	}
+	return item, true
+}
+
+// Stats devuelve el estado de la cola.
+func (q *IngestQueue) Stats() IngestStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := IngestStats{
+		Depth:    q.items.Len(),
+		Capacity: q.cfg.QueueSize,
+		Dropped:  q.dropped.Load(),
+		Lag:      time.Duration(q.lag.Load()),
+		LastSlot: q.lastSlot.Load(),
+	}
+	if q.spill != nil {
+		stats.Spilled = q.spill.pending
+	}
+	return stats
+}
+
+// Close despierta a quienes esperan en Push o Pop y borra el archivo de desborde. Los
+// mensajes que quedaban en memoria se pueden seguir sacando con Pop.
+func (q *IngestQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	close(q.done)
+	if q.spill != nil {
+		q.spill.close()
+		q.spill = nil
+	}
+}
+
+// signal avisa sin bloquear (si ya había un aviso pendiente alcanza con ese).
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// spillFile es un archivo de líneas JSON que se escribe al final y se lee desde el principio.
+type spillFile struct {
+	path    string
+	w       *os.File
+	r       *os.File
+	reader  *bufio.Reader
+	pending int
+}
+
+func openSpillFile(path string) (*spillFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("creating spill dir: %w", err)
+	}
+	w, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening spill file: %w", err)
+	}
+	r, err := os.Open(path)
+	if err != nil {
+		w.Close()
+		return nil, fmt.Errorf("opening spill file: %w", err)
+	}
+	return &spillFile{path: path, w: w, r: r, reader: bufio.NewReader(r)}, nil
+}
+
+func (s *spillFile) write(item ingestItem) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	if _, err := s.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	s.pending++
+	return nil
+}
+
+func (s *spillFile) read() (ingestItem, error) {
+	var item ingestItem
+	line, err := s.reader.ReadBytes('\n')
+	if err != nil {
+		return item, err
+	}
+	if err := json.Unmarshal(line, &item); err != nil {
+		return item, err
+	}
+	s.pending--
+	if s.pending == 0 {
+		// vaciado: se trunca para que el archivo no crezca indefinidamente
+		s.reset()
+	}
+	return item, nil
+}
+
+func (s *spillFile) reset() {
+	s.pending = 0
+	s.w.Truncate(0)
+	s.w.Seek(0, 0)
+	s.r.Seek(0, 0)
+	s.reader.Reset(s.r)
+}
+
+func (s *spillFile) close() {
+	s.w.Close()
+	s.r.Close()
+	os.Remove(s.path)
+}
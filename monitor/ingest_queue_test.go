@@ -0,0 +1,103 @@
+package monitor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gosol/config"
+	"gosol/monitor"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func logResult(i int) *ws.LogResult {
+	msg := &ws.LogResult{}
+	msg.Context.Slot = uint64(100 + i)
+	msg.Value.Signature = solana.Signature{byte(i)}
+	msg.Value.Logs = []string{"Program log: hi"}
+	return msg
+}
+
+// popAll saca todo lo que haya en la cola y devuelve los slots en el orden en que salieron.
+func popAll(t *testing.T, q *monitor.IngestQueue) []uint64 {
+	t.Helper()
+	var slots []uint64
+	for q.Stats().Depth > 0 || q.Stats().Spilled > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		msg, ok := q.Pop(ctx)
+		cancel()
+		require.True(t, ok)
+		slots = append(slots, msg.Context.Slot)
+	}
+	return slots
+}
+
+func TestIngestQueueDropsOldest(t *testing.T) {
+	q := monitor.NewIngestQueue("test", config.IngestConfig{QueueSize: 2, Overflow: config.OverflowDropOldest})
+	for i := range 4 {
+		assert.True(t, q.Push(logResult(i)))
+	}
+
+	stats := q.Stats()
+	assert.Equal(t, 2, stats.Depth)
+	assert.Equal(t, int64(2), stats.Dropped)
+	assert.Equal(t, []uint64{102, 103}, popAll(t, q))
+	assert.Equal(t, uint64(103), q.Stats().LastSlot)
+}
+
+func TestIngestQueueSpillsInOrder(t *testing.T) {
+	q := monitor.NewIngestQueue("test", config.IngestConfig{QueueSize: 2, Overflow: config.OverflowSpill, SpillDir: t.TempDir()})
+	defer q.Close()
+	for i := range 5 {
+		assert.True(t, q.Push(logResult(i)))
+	}
+	assert.Equal(t, 3, q.Stats().Spilled)
+
+	// con mensajes en disco, lo nuevo también va a disco aunque se haga lugar en memoria
+	ctx := context.Background()
+	msg, ok := q.Pop(ctx)
+	require.True(t, ok)
+	assert.Equal(t, uint64(100), msg.Context.Slot)
+	require.True(t, q.Push(logResult(5)))
+
+	assert.Equal(t, []uint64{101, 102, 103, 104, 105}, popAll(t, q))
+	assert.Zero(t, q.Stats().Dropped)
+}
+
+func TestIngestQueueBlocksUntilPopped(t *testing.T) {
+	q := monitor.NewIngestQueue("test", config.IngestConfig{QueueSize: 1, Overflow: config.OverflowBlock})
+	require.True(t, q.Push(logResult(0)))
+
+	pushed := make(chan bool)
+	go func() { pushed <- q.Push(logResult(1)) }()
+	select {
+	case <-pushed:
+		t.Fatal("push did not block with the queue full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	msg, ok := q.Pop(context.Background())
+	require.True(t, ok)
+	assert.Equal(t, uint64(100), msg.Context.Slot)
+	select {
+	case ok := <-pushed:
+		assert.True(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("push not released after pop")
+	}
+	assert.Positive(t, q.Stats().Lag)
+
+	// al cerrar se liberan los que esperan
+	go func() { pushed <- q.Push(logResult(2)) }()
+	q.Close()
+	select {
+	case ok := <-pushed:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("push not released after close")
+	}
+}
@@ -2,34 +2,52 @@ package monitor
 
 import (
 	"fmt"
+	"gosol/dex"
 
 	"github.com/gagliardetto/solana-go/rpc/ws"
 )
 
 type LogProcessor struct {
-	transactionManager *TransactionManager
-	statusUpdates      chan<- StatusMessage
+	tracker *CommitmentTracker
+	// commitment es el nivel de las suscripciones cuyos logs se procesan.
+	commitment    string
+	registry      *dex.Registry
+	dedupe        *Deduper
+	statusUpdates chan<- StatusMessage
 }
 
-func NewLogProcessor(tm *TransactionManager, statusUpdates chan<- StatusMessage) *LogProcessor {
+func NewLogProcessor(tracker *CommitmentTracker, commitment string, registry *dex.Registry, dedupe *Deduper, statusUpdates chan<- StatusMessage) *LogProcessor {
 	return &LogProcessor{
-		transactionManager: tm,
-		statusUpdates:      statusUpdates,
+		tracker:       tracker,
+		commitment:    commitment,
+		registry:      registry,
+		dedupe:        dedupe,
+		statusUpdates: statusUpdates,
 	}
 }
 
 func (lp *LogProcessor) ProcessLog(msg *ws.LogResult) {
+	signature := msg.Value.Signature
+	// la misma transacción puede llegar por varias suscripciones o por el backfill
+	if !lp.dedupe.FirstSignature(signature) {
+		return
+	}
+	// los programas de los DEX loguean cada swap; solo se piden las transacciones que
+	// pueden crear un pool
+	if !lp.registry.MatchesLogs(msg.Value.Logs) {
+		return
+	}
+	// los swaps fallidos se descartan arriba sin avisar; solo interesa una creación fallida
 	if msg.Value.Err != nil {
-		lp.updateStatus(fmt.Sprintf("Transaction failed: %v", msg.Value.Err), ERR)
+		lp.updateStatus(fmt.Sprintf("Transaction %s failed: %v", signature, msg.Value.Err), ERR)
 		return
 	}
 
-	signature := msg.Value.Signature
 	// lp.updateStatus(fmt.Sprintf("Transaction Signature: %s", signature), INFO)
 
-	lp.transactionManager.HandleTransaction(signature)
+	lp.tracker.Track(signature, lp.commitment, lp.registry.MatchingLogs(msg.Value.Logs)...)
 }
 
 func (lp *LogProcessor) updateStatus(message string, level LogLevel) {
-	lp.transactionManager.statusUpdates <- StatusMessage{Level: level, Message: message}
+	lp.statusUpdates <- StatusMessage{Level: level, Message: message}
 }
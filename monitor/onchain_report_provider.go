@@ -0,0 +1,327 @@
+package monitor
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"gosol/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Rúbrica del análisis on-chain. Cada riesgo suma su score al total (más alto = peor),
+// con los mismos nombres que usa rugcheck para que los deltas sean comparables:
+//
+//	Mint Authority still enabled    danger  3000  se pueden emitir tokens nuevos
+//	Freeze Authority still enabled  danger  3000  se pueden congelar cuentas de holders
+//	Top 10 holders high ownership   danger  2000  los 10 mayores holders tienen > 50%
+//	Single holder ownership         danger  1500  un holder tiene > 20%
+//	Missing metadata                warn     500  no hay cuenta de metadata de Metaplex
+//	Mutable metadata                warn     100  el update authority puede cambiar nombre/símbolo/URI
+//
+// Las cuentas de pools conocidos (ver knownPoolOwners) no cuentan para la concentración.
+const (
+	onChainMintAuthorityScore   = 3000
+	onChainFreezeAuthorityScore = 3000
+	onChainTop10Score           = 2000
+	onChainSingleHolderScore    = 1500
+	onChainMissingMetadataScore = 500
+	onChainMutableMetadataScore = 100
+
+	onChainTop10MaxPct        = 50.0
+	onChainSingleHolderMaxPct = 20.0
+	onChainTopHolders         = 10
+)
+
+// knownPoolOwners son dueños de token accounts que custodian liquidez y no holders reales.
+var knownPoolOwners = map[string]string{
+	"5Q544fKrFoe6tsEbD7S8EmxGTJYAKtTVhAW5Q5pge4j1": "Raydium Liquidity Pool V4",
+}
+
+// OnChainReportProvider arma el reporte sin depender de una API externa: lee la cuenta
+// del mint, la metadata de Metaplex y los mayores holders por RPC.
+type OnChainReportProvider struct {
+	name      string
+	rpcClient *rpc.Client
+}
+
+func NewOnChainReportProvider(name string, rpcClient *rpc.Client) *OnChainReportProvider {
+	return &OnChainReportProvider{name: name, rpcClient: rpcClient}
+}
+
+func (op *OnChainReportProvider) Name() string { return op.name }
+
+func (op *OnChainReportProvider) FetchReport(ctx context.Context, mint string) (types.Report, error) {
+	mintKey, err := solana.PublicKeyFromBase58(mint)
+	if err != nil {
+		return types.Report{}, fmt.Errorf("invalid mint %s: %w", mint, err)
+	}
+
+	mintAccount, err := op.fetchMint(ctx, mintKey)
+	if err != nil {
+		return types.Report{}, err
+	}
+	report := types.Report{Mint: mint, KnownAccounts: types.KnownAccounts{}}
+	if mintAccount.MintAuthority != nil {
+		report.MintAuthority = mintAccount.MintAuthority.String()
+	}
+	if mintAccount.FreezeAuthority != nil {
+		report.FreezeAuthority = mintAccount.FreezeAuthority.String()
+	}
+
+	meta, found, err := op.fetchMetadata(ctx, mintKey)
+	if err != nil {
+		return types.Report{}, err
+	}
+	report.TokenMeta = meta
+
+	holders, err := op.fetchTopHolders(ctx, mintKey, mintAccount.Supply)
+	if err != nil {
+		return types.Report{}, err
+	}
+	report.TopHolders = holders
+	for _, h := range holders {
+		if name, ok := knownPoolOwners[h.Owner]; ok {
+			report.KnownAccounts[h.Owner] = struct {
+				Name string `json:"name"`
+				Type string `json:"type"`
+			}{Name: name, Type: "AMM"}
+		}
+	}
+
+	report.Risks = onChainRisks(report, found)
+	for _, risk := range report.Risks {
+		report.Score += int(risk.Score)
+	}
+	return stampSource(report, op.name), nil
+}
+
+// onChainRisks aplica la rúbrica a un reporte ya completado.
+func onChainRisks(report types.Report, hasMetadata bool) []types.Risk {
+	var risks []types.Risk
+	add := func(name, level string, score int64) {
+		risks = append(risks, types.Risk{Name: name, Level: level, Score: score})
+	}
+
+	if report.MintAuthority != "" {
+		add("Mint Authority still enabled", "danger", onChainMintAuthorityScore)
+	}
+	if report.FreezeAuthority != "" {
+		add("Freeze Authority still enabled", "danger", onChainFreezeAuthorityScore)
+	}
+
+	// TopHolders viene ordenado por cantidad: se saltean los pools y se suman los 10 primeros
+	var top10, single float64
+	counted := 0
+	for _, h := range report.TopHolders {
+		if _, pool := knownPoolOwners[h.Owner]; pool {
+			continue
+		}
+		if counted == onChainTopHolders {
+			break
+		}
+		counted++
+		top10 += h.Pct
+		single = max(single, h.Pct)
+	}
+	if top10 > onChainTop10MaxPct {
+		add("Top 10 holders high ownership", "danger", onChainTop10Score)
+	}
+	if single > onChainSingleHolderMaxPct {
+		add("Single holder ownership", "danger", onChainSingleHolderScore)
+	}
+
+	if !hasMetadata {
+		add("Missing metadata", "warn", onChainMissingMetadataScore)
+	} else if report.TokenMeta.Mutable {
+		add("Mutable metadata", "warn", onChainMutableMetadataScore)
+	}
+	return risks
+}
+
+// fetchMint lee y decodifica la cuenta del mint (SPL Token o Token-2022; las extensiones de
+// Token-2022 van después de los primeros 82 bytes, que tienen el mismo formato).
+func (op *OnChainReportProvider) fetchMint(ctx context.Context, mintKey solana.PublicKey) (token.Mint, error) {
+	var mint token.Mint
+	account, err := op.getAccount(ctx, mintKey)
+	if err != nil {
+		return mint, err
+	}
+	if account == nil {
+		return mint, fmt.Errorf("%w: mint account %s does not exist", ErrReportNotFound, mintKey)
+	}
+	if !account.Owner.Equals(solana.TokenProgramID) && !account.Owner.Equals(solana.Token2022ProgramID) {
+		return mint, fmt.Errorf("%s is not an SPL token mint (owner %s)", mintKey, account.Owner)
+	}
+	if err := bin.NewBinDecoder(account.Data.GetBinary()).Decode(&mint); err != nil {
+		return mint, fmt.Errorf("decoding mint %s: %w", mintKey, err)
+	}
+	return mint, nil
+}
+
+// fetchMetadata lee la cuenta de metadata de Metaplex del mint. found es false si no existe.
+func (op *OnChainReportProvider) fetchMetadata(ctx context.Context, mintKey solana.PublicKey) (meta types.TokenMeta, found bool, err error) {
+	pda, _, err := solana.FindTokenMetadataAddress(mintKey)
+	if err != nil {
+		return meta, false, err
+	}
+	account, err := op.getAccount(ctx, pda)
+	if err != nil || account == nil {
+		return meta, false, err
+	}
+	meta, err = decodeMetadata(account.Data.GetBinary())
+	if err != nil {
+		return meta, false, fmt.Errorf("decoding metadata of %s: %w", mintKey, err)
+	}
+	return meta, true, nil
+}
+
+// fetchTopHolders devuelve los mayores holders con su porcentaje del supply y el dueño de
+// cada token account: las cuentas de pools conocidos más los 10 mayores holders que no lo
+// son. Se piden los dueños de todas las cuentas que devuelve el nodo (hasta 20) porque los
+// pools suelen estar entre las más grandes y no deben dejar afuera a un holder real.
+func (op *OnChainReportProvider) fetchTopHolders(ctx context.Context, mintKey solana.PublicKey, supply uint64) ([]types.Holder, error) {
+	largest, err := op.rpcClient.GetTokenLargestAccounts(ctx, mintKey, rpc.CommitmentConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("fetching largest accounts of %s: %w", mintKey, err)
+	}
+
+	accounts := largest.Value
+	sort.SliceStable(accounts, func(i, j int) bool { return amountOf(accounts[i]) > amountOf(accounts[j]) })
+	if len(accounts) == 0 {
+		return nil, nil
+	}
+
+	addresses := make([]solana.PublicKey, len(accounts))
+	for i, acc := range accounts {
+		addresses[i] = acc.Address
+	}
+	owners, err := op.rpcClient.GetMultipleAccountsWithOpts(ctx, addresses, &rpc.GetMultipleAccountsOpts{
+		Encoding:   solana.EncodingBase64,
+		Commitment: rpc.CommitmentConfirmed,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetching holder accounts of %s: %w", mintKey, err)
+	}
+
+	holders := make([]types.Holder, len(accounts))
+	for i, acc := range accounts {
+		amount := amountOf(acc)
+		holders[i] = types.Holder{
+			Address:        acc.Address.String(),
+			Amount:         int64(amount),
+			Decimals:       int(acc.Decimals),
+			UiAmountString: acc.UiAmountString,
+		}
+		if acc.UiAmount != nil {
+			holders[i].UiAmount = *acc.UiAmount
+		}
+		if supply > 0 {
+			holders[i].Pct = float64(amount) / float64(supply) * 100
+		}
+		// el dueño de un token account está en los bytes 32..64
+		if i < len(owners.Value) && owners.Value[i] != nil {
+			if data := owners.Value[i].Data.GetBinary(); len(data) >= 64 {
+				holders[i].Owner = solana.PublicKeyFromBytes(data[32:64]).String()
+			}
+		}
+	}
+
+	kept, others := holders[:0], 0
+	for _, h := range holders {
+		if _, pool := knownPoolOwners[h.Owner]; !pool {
+			if others == onChainTopHolders {
+				continue
+			}
+			others++
+		}
+		kept = append(kept, h)
+	}
+	return kept, nil
+}
+
+// getAccount devuelve la cuenta o nil si no existe.
+func (op *OnChainReportProvider) getAccount(ctx context.Context, key solana.PublicKey) (*rpc.Account, error) {
+	out, err := op.rpcClient.GetAccountInfoWithOpts(ctx, key, &rpc.GetAccountInfoOpts{
+		Encoding:   solana.EncodingBase64,
+		Commitment: rpc.CommitmentConfirmed,
+	})
+	if errors.Is(err, rpc.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching account %s: %w", key, err)
+	}
+	return out.Value, nil
+}
+
+func amountOf(acc *rpc.TokenLargestAccountsResult) uint64 {
+	amount, _ := strconv.ParseUint(acc.Amount, 10, 64)
+	return amount
+}
+
+// decodeMetadata decodifica (borsh) el prefijo de la cuenta Metadata de Metaplex que nos
+// interesa: key, update_authority, mint, name, symbol, uri, seller_fee_basis_points,
+// creators, primary_sale_happened e is_mutable.
+func decodeMetadata(data []byte) (types.TokenMeta, error) {
+	r := borshReader{data: data}
+	var meta types.TokenMeta
+
+	r.skip(1) // key
+	meta.UpdateAuthority = solana.PublicKeyFromBytes(r.bytes(32)).String()
+	r.skip(32) // mint
+	meta.Name = r.string()
+	meta.Symbol = r.string()
+	meta.URI = r.string()
+	r.skip(2) // seller_fee_basis_points
+	if r.u8() == 1 {
+		creators := r.u32()
+		r.skip(int(creators) * (32 + 1 + 1)) // address, verified, share
+	}
+	r.skip(1) // primary_sale_happened
+	meta.Mutable = r.u8() == 1
+
+	if r.err != nil {
+		return types.TokenMeta{}, r.err
+	}
+	return meta, nil
+}
+
+// borshReader lee valores borsh secuencialmente; el primer error corta el resto de las lecturas.
+type borshReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *borshReader) bytes(n int) []byte {
+	if r.err == nil && (n < 0 || r.pos+n > len(r.data)) {
+		r.err = fmt.Errorf("unexpected end of data at offset %d (need %d bytes)", r.pos, n)
+	}
+	if r.err != nil {
+		// alcanza para que los lectores de tamaño fijo no fallen; el error ya quedó guardado
+		return make([]byte, 32)
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *borshReader) skip(n int) { r.bytes(n) }
+func (r *borshReader) u8() uint8  { return r.bytes(1)[0] }
+func (r *borshReader) u32() uint32 {
+	return binary.LittleEndian.Uint32(r.bytes(4))
+}
+
+// string lee un String de borsh; Metaplex rellena los campos con ceros al final.
+func (r *borshReader) string() string {
+	n := r.u32()
+	return strings.TrimRight(string(r.bytes(int(n))), "\x00")
+}
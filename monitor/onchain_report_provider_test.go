@@ -0,0 +1,186 @@
+package monitor_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"gosol/monitor"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRPC responde los métodos que usa el análisis on-chain con cuentas armadas a mano.
+func fakeRPC(t *testing.T, accounts map[string]accountFixture, largest []map[string]any) *rpc.Client {
+	encode := func(key string) any {
+		acc, ok := accounts[key]
+		if !ok {
+			return nil
+		}
+		return map[string]any{
+			"lamports": 1, "owner": acc.owner, "executable": false, "rentEpoch": 0,
+			"data": []string{base64.StdEncoding.EncodeToString(acc.data), "base64"},
+		}
+	}
+	withContext := func(value any) any {
+		return map[string]any{"context": map[string]any{"slot": 1}, "value": value}
+	}
+	return newFakeRPC(t, map[string]rpcMethod{
+		"getAccountInfo": func(params []json.RawMessage) (any, error) {
+			var key string
+			if err := rpcParam(params, 0, &key); err != nil {
+				return nil, err
+			}
+			return withContext(encode(key)), nil
+		},
+		"getMultipleAccounts": func(params []json.RawMessage) (any, error) {
+			var keys []string
+			if err := rpcParam(params, 0, &keys); err != nil {
+				return nil, err
+			}
+			list := make([]any, len(keys))
+			for i, key := range keys {
+				list[i] = encode(key)
+			}
+			return withContext(list), nil
+		},
+		"getTokenLargestAccounts": func([]json.RawMessage) (any, error) {
+			return withContext(largest), nil
+		},
+	})
+}
+
+type accountFixture struct {
+	owner string
+	data  []byte
+}
+
+func mintData(mintAuthority *solana.PublicKey, supply uint64, decimals uint8) []byte {
+	data := make([]byte, 82)
+	if mintAuthority != nil {
+		binary.LittleEndian.PutUint32(data[0:], 1)
+		copy(data[4:36], mintAuthority[:])
+	}
+	binary.LittleEndian.PutUint64(data[36:], supply)
+	data[44] = decimals
+	data[45] = 1 // is_initialized
+	return data
+}
+
+func metadataData(updateAuthority, mint solana.PublicKey, name, symbol, uri string, mutable bool) []byte {
+	data := []byte{4}
+	data = append(data, updateAuthority[:]...)
+	data = append(data, mint[:]...)
+	for _, s := range []string{name, symbol, uri} {
+		padded := append([]byte(s), make([]byte, 8)...) // Metaplex rellena con ceros
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(padded)))
+		data = append(data, padded...)
+	}
+	data = append(data, 0, 0) // seller_fee_basis_points
+	data = append(data, 0)    // sin creators
+	data = append(data, 0)    // primary_sale_happened
+	if mutable {
+		return append(data, 1)
+	}
+	return append(data, 0)
+}
+
+func tokenAccountData(mint, owner solana.PublicKey) []byte {
+	data := make([]byte, 165)
+	copy(data[0:32], mint[:])
+	copy(data[32:64], owner[:])
+	return data
+}
+
+func TestOnChainReportProvider(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	authority := solana.NewWallet().PublicKey()
+	whale := solana.NewWallet().PublicKey()
+	raydium := solana.MustPublicKeyFromBase58("5Q544fKrFoe6tsEbD7S8EmxGTJYAKtTVhAW5Q5pge4j1")
+	metadataPDA, _, err := solana.FindTokenMetadataAddress(mint)
+	require.NoError(t, err)
+
+	poolAccount := solana.NewWallet().PublicKey()
+	whaleAccount := solana.NewWallet().PublicKey()
+	smallAccount := solana.NewWallet().PublicKey()
+
+	client := fakeRPC(t,
+		map[string]accountFixture{
+			mint.String():         {owner: solana.TokenProgramID.String(), data: mintData(&authority, 1_000_000, 6)},
+			metadataPDA.String():  {owner: solana.TokenMetadataProgramID.String(), data: metadataData(authority, mint, "Test Token", "TEST", "https://example.com/t.json", true)},
+			poolAccount.String():  {owner: solana.TokenProgramID.String(), data: tokenAccountData(mint, raydium)},
+			whaleAccount.String(): {owner: solana.TokenProgramID.String(), data: tokenAccountData(mint, whale)},
+			smallAccount.String(): {owner: solana.TokenProgramID.String(), data: tokenAccountData(mint, solana.NewWallet().PublicKey())},
+		},
+		[]map[string]any{
+			{"address": whaleAccount.String(), "amount": "300000", "decimals": 6, "uiAmountString": "0.3"},
+			{"address": poolAccount.String(), "amount": "600000", "decimals": 6, "uiAmountString": "0.6"},
+			{"address": smallAccount.String(), "amount": "100000", "decimals": 6, "uiAmountString": "0.1"},
+		},
+	)
+
+	report, err := monitor.NewOnChainReportProvider("onchain", client).FetchReport(context.Background(), mint.String())
+	require.NoError(t, err)
+
+	assert.Equal(t, "onchain", report.Source)
+	assert.Equal(t, "TEST", report.TokenMeta.Symbol)
+	assert.Equal(t, "Test Token", report.TokenMeta.Name)
+	assert.True(t, report.TokenMeta.Mutable)
+	assert.Equal(t, authority.String(), report.TokenMeta.UpdateAuthority)
+	assert.Equal(t, authority.String(), report.MintAuthority)
+	assert.Empty(t, report.FreezeAuthority)
+
+	require.Len(t, report.TopHolders, 3)
+	assert.Equal(t, raydium.String(), report.TopHolders[0].Owner, "ordenados por cantidad")
+	assert.InDelta(t, 60.0, report.TopHolders[0].Pct, 0.001)
+	assert.Contains(t, report.KnownAccounts, raydium.String())
+
+	// el pool no cuenta: top 10 = 40% (sin riesgo) pero un holder tiene 30%
+	var names []string
+	for _, risk := range report.Risks {
+		names = append(names, risk.Name)
+	}
+	assert.Equal(t, []string{"Mint Authority still enabled", "Single holder ownership", "Mutable metadata"}, names)
+	assert.Equal(t, 3000+1500+100, report.Score)
+}
+
+func TestOnChainReportProviderUnknownMint(t *testing.T) {
+	client := fakeRPC(t, nil, nil)
+
+	_, err := monitor.NewOnChainReportProvider("onchain", client).FetchReport(context.Background(), solana.NewWallet().PublicKey().String())
+	assert.ErrorIs(t, err, monitor.ErrReportNotFound)
+}
+
+func TestOnChainReportProviderSkipsPoolsBeforeTakingTheTop10(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	raydium := solana.MustPublicKeyFromBase58("5Q544fKrFoe6tsEbD7S8EmxGTJYAKtTVhAW5Q5pge4j1")
+
+	poolAccount := solana.NewWallet().PublicKey()
+	accounts := map[string]accountFixture{
+		mint.String():        {owner: solana.TokenProgramID.String(), data: mintData(nil, 1_000_000, 6)},
+		poolAccount.String(): {owner: solana.TokenProgramID.String(), data: tokenAccountData(mint, raydium)},
+	}
+	largest := []map[string]any{{"address": poolAccount.String(), "amount": "400000", "decimals": 6}}
+	// 11 holders de 5.4%: los 10 mayores sin el pool suman 54%
+	for range 11 {
+		account := solana.NewWallet().PublicKey()
+		accounts[account.String()] = accountFixture{owner: solana.TokenProgramID.String(), data: tokenAccountData(mint, solana.NewWallet().PublicKey())}
+		largest = append(largest, map[string]any{"address": account.String(), "amount": "54000", "decimals": 6})
+	}
+
+	report, err := monitor.NewOnChainReportProvider("onchain", fakeRPC(t, accounts, largest)).FetchReport(context.Background(), mint.String())
+	require.NoError(t, err)
+
+	require.Len(t, report.TopHolders, 11, "the pool plus the top 10 holders")
+	assert.Equal(t, raydium.String(), report.TopHolders[0].Owner)
+	var names []string
+	for _, risk := range report.Risks {
+		names = append(names, risk.Name)
+	}
+	assert.Contains(t, names, "Top 10 holders high ownership")
+}
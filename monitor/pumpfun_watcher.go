@@ -0,0 +1,147 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"gosol/config"
+	"gosol/dex"
+	"gosol/types"
+	"sync/atomic"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+)
+
+// pumpFunRefresh es cada cuánto se refresca la tabla con el avance de las curvas y se
+// olvidan las que vencieron. Los trades llegan de a cientos por segundo, así que no se
+// manda una actualización por cada uno.
+const pumpFunRefresh = 2 * time.Second
+
+// PumpFunWatcher sigue los tokens lanzados en pump.fun decodificando los eventos de los logs
+// del programa (sin pedir cada transacción por RPC): registra la curva al crearse el token,
+// actualiza su avance con cada trade y, cuando se completa, lo da por detectado y pide su
+// reporte. La migración a Raydium la detecta después el TransactionManager como un pool nuevo.
+type PumpFunWatcher struct {
+	cfg           config.PumpFunConfig
+	programID     solana.PublicKey
+	stateManager  *StateManager
+	apiClient     *APIClient
+	dedupe        *Deduper
+	statusUpdates chan<- StatusMessage
+	tokenUpdates  chan<- []types.TokenInfo
+	ingest        *IngestQueue
+
+	dirty atomic.Bool
+}
+
+func NewPumpFunWatcher(cfg config.PumpFunConfig, ingest *IngestQueue, stateManager *StateManager, apiClient *APIClient, dedupe *Deduper, statusUpdates chan<- StatusMessage, tokenUpdates chan<- []types.TokenInfo) (*PumpFunWatcher, error) {
+	programID, err := solana.PublicKeyFromBase58(cfg.ProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("pumpfun.program_id: %w", err)
+	}
+	return &PumpFunWatcher{
+		cfg:           cfg,
+		programID:     programID,
+		stateManager:  stateManager,
+		apiClient:     apiClient,
+		dedupe:        dedupe,
+		statusUpdates: statusUpdates,
+		tokenUpdates:  tokenUpdates,
+		ingest:        ingest,
+	}, nil
+}
+
+// ProgramID es la cuenta a la que hay que suscribirse para recibir los logs.
+func (pw *PumpFunWatcher) ProgramID() solana.PublicKey { return pw.programID }
+
+// Ingest es la cola por la que llegan los logs del programa.
+func (pw *PumpFunWatcher) Ingest() *IngestQueue { return pw.ingest }
+
+// Run procesa los logs hasta que se cancele ctx.
+func (pw *PumpFunWatcher) Run(ctx context.Context) {
+	go pw.refresh(ctx)
+	for {
+		msg, ok := pw.ingest.Pop(ctx)
+		if !ok {
+			return
+		}
+		pw.ProcessLog(msg)
+	}
+}
+
+// refresh olvida las curvas vencidas y actualiza la tabla si cambió algún avance.
+func (pw *PumpFunWatcher) refresh(ctx context.Context) {
+	ticker := time.NewTicker(pumpFunRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			pw.stateManager.ForgetCurves(now.Add(-pw.cfg.TrackFor))
+			if pw.dirty.Swap(false) {
+				pw.stateManager.SendTokenUpdates(pw.tokenUpdates)
+			}
+		}
+	}
+}
+
+// ProcessLog aplica los eventos de pump.fun de una transacción.
+func (pw *PumpFunWatcher) ProcessLog(msg *ws.LogResult) {
+	if msg.Value.Err != nil {
+		return
+	}
+	events, err := dex.PumpFunEvents(msg.Value.Logs)
+	if err != nil {
+		pw.updateStatus(fmt.Sprintf("Error decoding pump.fun events of %s: %v", msg.Value.Signature, err), WARN)
+	}
+
+	now := time.Now()
+	for _, event := range events {
+		mint := event.EventMint().String()
+		switch e := event.(type) {
+		case dex.PumpFunCreate:
+			pw.stateManager.TrackCurve(types.BondingCurve{
+				Mint:         mint,
+				BondingCurve: e.BondingCurve.String(),
+				Name:         e.Name,
+				Symbol:       e.Symbol,
+				URI:          e.URI,
+				Creator:      e.User.String(),
+				CreatedAt:    now,
+				UpdatedAt:    now,
+			})
+			if pw.cfg.ReportOnCreate {
+				pw.updateStatus(fmt.Sprintf("🆕 pump.fun launch %s (%s)", e.Symbol, mint), INFO)
+				pw.detect(mint)
+			}
+		case dex.PumpFunTrade:
+			if pw.stateManager.UpdateCurve(mint, e.Progress(), now) {
+				pw.dirty.Store(true)
+			}
+		case dex.PumpFunComplete:
+			curve, ok := pw.stateManager.CompleteCurve(mint, now)
+			if !ok {
+				continue
+			}
+			pw.dirty.Store(true)
+			pw.updateStatus(fmt.Sprintf("🎓 %s bonding curve complete, migrating to Raydium (%s)", curve.Symbol, mint), INFO)
+			pw.detect(mint)
+		}
+	}
+}
+
+// detect da el mint por detectado y pide su reporte.
+func (pw *PumpFunWatcher) detect(mint string) {
+	if !pw.dedupe.FirstMint(mint) {
+		return
+	}
+	pw.stateManager.AddMint(mint)
+	pw.apiClient.FetchAndProcessReport(mint)
+}
+
+func (pw *PumpFunWatcher) updateStatus(message string, level LogLevel) {
+	pw.statusUpdates <- StatusMessage{Level: level, Message: message}
+}
@@ -0,0 +1,162 @@
+package monitor_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"testing"
+	"time"
+
+	"gosol/config"
+	"gosol/dex"
+	"gosol/monitor"
+	"gosol/storage"
+	"gosol/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pumpFunLog arma el log "Program data: ..." de un evento de Anchor: sha256("event:<name>")[:8]
+// seguido de los campos en borsh.
+func pumpFunLog(name string, fields ...[]byte) string {
+	sum := sha256.Sum256([]byte("event:" + name))
+	data := append([]byte{}, sum[:8]...)
+	for _, field := range fields {
+		data = append(data, field...)
+	}
+	return "Program data: " + base64.StdEncoding.EncodeToString(data)
+}
+
+func borshString(s string) []byte {
+	return append(binary.LittleEndian.AppendUint32(nil, uint32(len(s))), s...)
+}
+
+func borshU64(v uint64) []byte { return binary.LittleEndian.AppendUint64(nil, v) }
+
+func pumpFunTx(logs ...string) *ws.LogResult {
+	msg := &ws.LogResult{}
+	msg.Value.Logs = logs
+	return msg
+}
+
+func createLog(mint solana.PublicKey, symbol string) string {
+	user := solana.NewWallet().PublicKey()
+	return pumpFunLog("CreateEvent", borshString("Token "+symbol), borshString(symbol), borshString("https://example.com/"+symbol),
+		mint.Bytes(), solana.NewWallet().PublicKey().Bytes(), user.Bytes())
+}
+
+func tradeLog(mint solana.PublicKey, virtualTokenReserves uint64) string {
+	return pumpFunLog("TradeEvent", mint.Bytes(), borshU64(1_000_000_000), borshU64(1_000_000), []byte{1},
+		solana.NewWallet().PublicKey().Bytes(), borshU64(uint64(time.Now().Unix())), borshU64(30_000_000_000), borshU64(virtualTokenReserves))
+}
+
+func completeLog(mint solana.PublicKey) string {
+	return pumpFunLog("CompleteEvent", solana.NewWallet().PublicKey().Bytes(), mint.Bytes(), solana.NewWallet().PublicKey().Bytes(),
+		borshU64(uint64(time.Now().Unix())))
+}
+
+// newTestPumpFunWatcher arma un watcher cuyo APIClient no tiene workers, así que los
+// reportes pedidos quedan en la cola y se pueden contar con QueueStats.
+func newTestPumpFunWatcher(t *testing.T, ctx context.Context, reportOnCreate bool) (*monitor.PumpFunWatcher, *monitor.APIClient, *monitor.StateManager) {
+	api, stateMgr, _ := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	cfg := config.Default()
+	cfg.PumpFun.ReportOnCreate = reportOnCreate
+	watcher, err := monitor.NewPumpFunWatcher(cfg.PumpFun, monitor.NewIngestQueue("pumpfun", cfg.Ingest), stateMgr, api,
+		monitor.NewDeduper(cfg.Dedupe), drainStatus(ctx), make(chan []types.TokenInfo, 10))
+	require.NoError(t, err)
+	return watcher, api, stateMgr
+}
+
+func reportsRequested(api *monitor.APIClient) int {
+	depth, _, _, _ := api.QueueStats()
+	return depth
+}
+
+func TestPumpFunWatcherFollowsTheCurveUntilComplete(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watcher, api, stateMgr := newTestPumpFunWatcher(t, ctx, false)
+	mint := solana.NewWallet().PublicKey()
+
+	watcher.ProcessLog(pumpFunTx(createLog(mint, "PUMP")))
+	curve, ok := stateMgr.Curve(mint.String())
+	require.True(t, ok)
+	assert.Equal(t, "PUMP", curve.Symbol)
+	assert.Zero(t, curve.Progress)
+	assert.Zero(t, reportsRequested(api), "no report until the curve completes")
+	assert.Empty(t, stateMgr.LastStatus(mint.String()))
+
+	halfway := uint64(dex.PumpFunInitialVirtualTokenReserves - (dex.PumpFunInitialVirtualTokenReserves-dex.PumpFunFinalVirtualTokenReserves)/2)
+	watcher.ProcessLog(pumpFunTx(tradeLog(mint, halfway)))
+	curve, _ = stateMgr.Curve(mint.String())
+	assert.InDelta(t, 50, curve.Progress, 0.01)
+
+	// los trades de tokens que no se siguen se ignoran
+	other := solana.NewWallet().PublicKey()
+	watcher.ProcessLog(pumpFunTx(tradeLog(other, halfway)))
+	_, ok = stateMgr.Curve(other.String())
+	assert.False(t, ok)
+
+	watcher.ProcessLog(pumpFunTx(completeLog(mint)))
+	curve, _ = stateMgr.Curve(mint.String())
+	assert.True(t, curve.Complete)
+	assert.Equal(t, float64(100), curve.Progress)
+	assert.Equal(t, 1, reportsRequested(api))
+	assert.Equal(t, storage.StatusDetected, stateMgr.LastStatus(mint.String()))
+
+	// un trade tardío no cambia una curva completa y un segundo complete no pide otro reporte
+	watcher.ProcessLog(pumpFunTx(tradeLog(mint, halfway), completeLog(mint)))
+	curve, _ = stateMgr.Curve(mint.String())
+	assert.Equal(t, float64(100), curve.Progress)
+	assert.Equal(t, 1, reportsRequested(api))
+}
+
+func TestPumpFunWatcherDedupesCompletionOfTokensReportedOnCreate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watcher, api, stateMgr := newTestPumpFunWatcher(t, ctx, true)
+	mint := solana.NewWallet().PublicKey()
+
+	watcher.ProcessLog(pumpFunTx(createLog(mint, "EARLY")))
+	assert.Equal(t, 1, reportsRequested(api))
+	assert.Equal(t, storage.StatusDetected, stateMgr.LastStatus(mint.String()))
+
+	watcher.ProcessLog(pumpFunTx(completeLog(mint)))
+	assert.Equal(t, 1, reportsRequested(api), "the completion must not request the report again")
+	assert.Equal(t, storage.StatusCurveComplete, stateMgr.LastStatus(mint.String()))
+}
+
+func TestStateManagerForgetsExpiredCurves(t *testing.T) {
+	stateMgr := monitor.NewStateManager(nil, make(chan monitor.StatusMessage, 10))
+	old := time.Now().Add(-2 * time.Hour)
+	for _, mint := range []string{"stale", "detected", "fresh"} {
+		createdAt := old
+		if mint == "fresh" {
+			createdAt = time.Now()
+		}
+		stateMgr.TrackCurve(types.BondingCurve{Mint: mint, CreatedAt: createdAt, UpdatedAt: createdAt})
+	}
+	stateMgr.AddMint("detected")
+
+	// TrackCurve no pisa una curva que ya se sigue
+	stateMgr.TrackCurve(types.BondingCurve{Mint: "stale", CreatedAt: time.Now()})
+
+	assert.Equal(t, 1, stateMgr.ForgetCurves(time.Now().Add(-time.Hour)))
+	_, ok := stateMgr.Curve("stale")
+	assert.False(t, ok)
+	_, ok = stateMgr.Curve("detected")
+	assert.True(t, ok, "curves of detected mints are kept")
+	_, ok = stateMgr.Curve("fresh")
+	assert.True(t, ok)
+
+	assert.False(t, stateMgr.UpdateCurve("stale", 10, time.Now()))
+	_, ok = stateMgr.CompleteCurve("stale", time.Now())
+	assert.False(t, ok)
+}
@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"context"
+	"gosol/config"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// maxRetryAfter acota cuánto se respeta un Retry-After del servidor.
+const maxRetryAfter = 2 * time.Minute
+
+// RateLimiter combina un token bucket global, uno opcional por host y pausas por host
+// cuando el servidor responde 429 con Retry-After.
+type RateLimiter struct {
+	global  *rate.Limiter
+	perHost map[string]*rate.Limiter
+
+	mu          sync.Mutex
+	pausedUntil map[string]time.Time
+}
+
+func NewRateLimiter(global config.RateLimitConfig, perHost map[string]config.RateLimitConfig) *RateLimiter {
+	rl := &RateLimiter{
+		global:      rate.NewLimiter(rate.Limit(global.RPS), global.Burst),
+		perHost:     make(map[string]*rate.Limiter, len(perHost)),
+		pausedUntil: make(map[string]time.Time),
+	}
+	for host, limit := range perHost {
+		rl.perHost[host] = rate.NewLimiter(rate.Limit(limit.RPS), limit.Burst)
+	}
+	return rl
+}
+
+// Wait bloquea hasta que se pueda hacer un pedido a host.
+func (rl *RateLimiter) Wait(ctx context.Context, host string) error {
+	rl.mu.Lock()
+	until := rl.pausedUntil[host]
+	rl.mu.Unlock()
+
+	if wait := time.Until(until); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+
+	if err := rl.global.Wait(ctx); err != nil {
+		return err
+	}
+	if limiter, ok := rl.perHost[host]; ok {
+		return limiter.Wait(ctx)
+	}
+	return nil
+}
+
+// Pause frena todos los pedidos a host hasta until (se queda con la pausa más larga).
+func (rl *RateLimiter) Pause(host string, until time.Time) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if until.After(rl.pausedUntil[host]) {
+		rl.pausedUntil[host] = until
+	}
+}
+
+// parseRetryAfter interpreta el header Retry-After (segundos o fecha HTTP).
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		wait = at.Sub(now)
+	} else {
+		return 0, false
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return min(wait, maxRetryAfter), true
+}
+
+// backoffDelay calcula la espera antes del reintento attempt (0, 1, 2...) con backoff
+// exponencial y "equal jitter": la mitad fija y la otra mitad aleatoria.
+func backoffDelay(attempt int, base, maxDelay time.Duration) time.Duration {
+	d := base << attempt
+	if d <= 0 || d > maxDelay {
+		d = maxDelay
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
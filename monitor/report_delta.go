@@ -0,0 +1,131 @@
+package monitor
+
+import (
+	"fmt"
+	"gosol/types"
+	"strings"
+	"time"
+)
+
+// topHoldersForConcentration es la cantidad de holders que se suman para medir concentración.
+const topHoldersForConcentration = 10
+
+// ReportDelta describe qué cambió entre dos reportes consecutivos de un mint.
+type ReportDelta struct {
+	Mint string
+	From time.Time
+	To   time.Time
+
+	ScoreFrom int
+	ScoreTo   int
+
+	NewRisks     []types.Risk
+	RemovedRisks []types.Risk
+
+	LPProvidersFrom int
+	LPProvidersTo   int
+
+	// Porcentaje del supply en manos de los principales holders.
+	ConcentrationFrom float64
+	ConcentrationTo   float64
+
+	LiquidityFrom float64
+	LiquidityTo   float64
+}
+
+// HolderConcentration suma el porcentaje de los principales holders del reporte.
+func HolderConcentration(report types.Report) float64 {
+	total := 0.0
+	for i, holder := range report.TopHolders {
+		if i >= topHoldersForConcentration {
+			break
+		}
+		total += holder.Pct
+	}
+	return total
+}
+
+// DiffReports compara dos reportes del mismo mint (prev es el anterior).
+func DiffReports(prev, curr types.Report, prevAt, currAt time.Time) ReportDelta {
+	delta := ReportDelta{
+		Mint:              curr.Mint,
+		From:              prevAt,
+		To:                currAt,
+		ScoreFrom:         prev.Score,
+		ScoreTo:           curr.Score,
+		LPProvidersFrom:   prev.TotalLPProviders,
+		LPProvidersTo:     curr.TotalLPProviders,
+		ConcentrationFrom: HolderConcentration(prev),
+		ConcentrationTo:   HolderConcentration(curr),
+		LiquidityFrom:     prev.TotalMarketLiquidity,
+		LiquidityTo:       curr.TotalMarketLiquidity,
+	}
+
+	prevRisks := make(map[string]bool, len(prev.Risks))
+	for _, risk := range prev.Risks {
+		prevRisks[risk.Name] = true
+	}
+	currRisks := make(map[string]bool, len(curr.Risks))
+	for _, risk := range curr.Risks {
+		currRisks[risk.Name] = true
+		if !prevRisks[risk.Name] {
+			delta.NewRisks = append(delta.NewRisks, risk)
+		}
+	}
+	for _, risk := range prev.Risks {
+		if !currRisks[risk.Name] {
+			delta.RemovedRisks = append(delta.RemovedRisks, risk)
+		}
+	}
+	return delta
+}
+
+// concentrationEpsilon evita reportar cambios de concentración por redondeo.
+const concentrationEpsilon = 0.01
+
+// Changed indica si hubo algún cambio relevante entre los dos reportes.
+func (d ReportDelta) Changed() bool {
+	return d.ScoreFrom != d.ScoreTo ||
+		len(d.NewRisks) > 0 || len(d.RemovedRisks) > 0 ||
+		d.LPProvidersFrom != d.LPProvidersTo ||
+		abs(d.ConcentrationTo-d.ConcentrationFrom) > concentrationEpsilon
+}
+
+// String arma un resumen corto, ej: "score went 3400 → 800, +1 risk, LP providers 2 → 5".
+func (d ReportDelta) String() string {
+	var parts []string
+	if d.ScoreFrom != d.ScoreTo {
+		parts = append(parts, fmt.Sprintf("score went %d → %d", d.ScoreFrom, d.ScoreTo))
+	}
+	if len(d.NewRisks) > 0 {
+		parts = append(parts, fmt.Sprintf("+%d risk (%s)", len(d.NewRisks), riskNames(d.NewRisks)))
+	}
+	if len(d.RemovedRisks) > 0 {
+		parts = append(parts, fmt.Sprintf("-%d risk (%s)", len(d.RemovedRisks), riskNames(d.RemovedRisks)))
+	}
+	if d.LPProvidersFrom != d.LPProvidersTo {
+		parts = append(parts, fmt.Sprintf("LP providers %d → %d", d.LPProvidersFrom, d.LPProvidersTo))
+	}
+	if abs(d.ConcentrationTo-d.ConcentrationFrom) > concentrationEpsilon {
+		parts = append(parts, fmt.Sprintf("top holders %.2f%% → %.2f%%", d.ConcentrationFrom, d.ConcentrationTo))
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func riskNames(risks []types.Risk) string {
+	names := make([]string, len(risks))
+	for i, risk := range risks {
+		names[i] = risk.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
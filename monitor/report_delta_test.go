@@ -0,0 +1,64 @@
+package monitor_test
+
+import (
+	"testing"
+	"time"
+
+	"gosol/monitor"
+	"gosol/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffReports(t *testing.T) {
+	prev := types.Report{
+		Mint:             "mint",
+		Score:            3400,
+		TotalLPProviders: 1,
+		Risks:            []types.Risk{{Name: "Mutable metadata"}, {Name: "Low Liquidity"}},
+		TopHolders:       []types.Holder{{Pct: 40}, {Pct: 10}},
+	}
+	curr := types.Report{
+		Mint:             "mint",
+		Score:            800,
+		TotalLPProviders: 4,
+		Risks:            []types.Risk{{Name: "Mutable metadata"}, {Name: "Single holder ownership"}},
+		TopHolders:       []types.Holder{{Pct: 12}, {Pct: 8}},
+	}
+
+	delta := monitor.DiffReports(prev, curr, time.Now().Add(-time.Minute), time.Now())
+
+	assert.True(t, delta.Changed())
+	assert.Equal(t, 3400, delta.ScoreFrom)
+	assert.Equal(t, 800, delta.ScoreTo)
+	require.Len(t, delta.NewRisks, 1)
+	assert.Equal(t, "Single holder ownership", delta.NewRisks[0].Name)
+	require.Len(t, delta.RemovedRisks, 1)
+	assert.Equal(t, "Low Liquidity", delta.RemovedRisks[0].Name)
+	assert.InDelta(t, 50.0, delta.ConcentrationFrom, 0.001)
+	assert.InDelta(t, 20.0, delta.ConcentrationTo, 0.001)
+	assert.Contains(t, delta.String(), "score went 3400 → 800")
+	assert.Contains(t, delta.String(), "LP providers 1 → 4")
+
+	assert.False(t, monitor.DiffReports(curr, curr, time.Now(), time.Now()).Changed())
+}
+
+func TestStateManagerEmitsScoreChanges(t *testing.T) {
+	statusCh := make(chan monitor.StatusMessage, 10)
+	sm := monitor.NewStateManager(nil, statusCh)
+
+	sm.UpdateMintState("mint", types.Report{Score: 3400, TokenMeta: types.TokenMeta{Symbol: "ABC"}})
+	sm.UpdateMintState("mint", types.Report{Score: 3400, TokenMeta: types.TokenMeta{Symbol: "ABC"}})
+	assert.Empty(t, statusCh, "un reporte sin cambios no genera evento")
+
+	sm.UpdateMintState("mint", types.Report{Score: 800, TokenMeta: types.TokenMeta{Symbol: "ABC"}})
+	require.Len(t, statusCh, 1)
+	assert.Equal(t, "📈 ABC: score went 3400 → 800", (<-statusCh).Message)
+
+	assert.Len(t, sm.History("mint"), 3)
+	deltas := sm.Deltas("mint")
+	require.Len(t, deltas, 2)
+	assert.False(t, deltas[0].Changed())
+	assert.True(t, deltas[1].Changed())
+}
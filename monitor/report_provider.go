@@ -0,0 +1,261 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gosol/config"
+	"gosol/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// ReportProvider es una fuente de reportes de riesgo para un mint.
+type ReportProvider interface {
+	Name() string
+	FetchReport(ctx context.Context, mint string) (types.Report, error)
+}
+
+// ErrReportNotFound indica que el proveedor no tiene reporte para el mint.
+var ErrReportNotFound = errors.New("report not found")
+
+// NewReportProvider arma el proveedor configurado. Con un solo proveedor se usa directo;
+// con varios se combinan según cfg.Strategy. rpcClient lo usan los proveedores on-chain.
+func NewReportProvider(cfg config.ReportConfig, rpcClient *rpc.Client) (ReportProvider, error) {
+	// el limiter se comparte entre todos los proveedores HTTP
+	limiter := NewRateLimiter(cfg.RateLimit, cfg.EndpointLimits)
+
+	var providers []ReportProvider
+	for _, p := range cfg.Providers {
+		switch p.Type {
+		case config.ProviderHTTP:
+			providers = append(providers, NewHTTPReportProvider(p.Name, p.BaseURL, cfg, limiter))
+		case config.ProviderFixture:
+			providers = append(providers, NewFixtureReportProvider(p.Name, p.Path))
+		case config.ProviderOnChain:
+			if rpcClient == nil {
+				return nil, fmt.Errorf("report provider %s needs an RPC client", p.Name)
+			}
+			providers = append(providers, NewOnChainReportProvider(p.Name, rpcClient))
+		default:
+			return nil, fmt.Errorf("unknown report provider type %q", p.Type)
+		}
+	}
+
+	if len(providers) == 1 {
+		return providers[0], nil
+	}
+	return NewCompositeReportProvider(cfg.Strategy, cfg.Quorum, providers...), nil
+}
+
+// stampSource marca el reporte y cada uno de sus riesgos con el nombre del proveedor.
+func stampSource(report types.Report, source string) types.Report {
+	report.Source = source
+	risks := make([]types.Risk, len(report.Risks))
+	for i, risk := range report.Risks {
+		if risk.Source == "" {
+			risk.Source = source
+		}
+		risks[i] = risk
+	}
+	report.Risks = risks
+	return report
+}
+
+// FixtureReportProvider lee reportes de archivos <mint>.json en un directorio, o de un
+// mapa en memoria. Sirve para tests y para reproducir casos sin depender de la API.
+type FixtureReportProvider struct {
+	name    string
+	dir     string
+	reports map[string]types.Report
+}
+
+func NewFixtureReportProvider(name, dir string) *FixtureReportProvider {
+	return &FixtureReportProvider{name: name, dir: dir}
+}
+
+// NewStaticReportProvider devuelve siempre los reportes del mapa.
+func NewStaticReportProvider(name string, reports map[string]types.Report) *FixtureReportProvider {
+	return &FixtureReportProvider{name: name, reports: reports}
+}
+
+func (fp *FixtureReportProvider) Name() string { return fp.name }
+
+func (fp *FixtureReportProvider) FetchReport(ctx context.Context, mint string) (types.Report, error) {
+	if fp.reports != nil {
+		report, ok := fp.reports[mint]
+		if !ok {
+			return types.Report{}, ErrReportNotFound
+		}
+		return stampSource(report, fp.name), nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(fp.dir, filepath.Base(mint)+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return types.Report{}, ErrReportNotFound
+	}
+	if err != nil {
+		return types.Report{}, err
+	}
+	var report types.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return types.Report{}, fmt.Errorf("fixture %s: %w", mint, err)
+	}
+	return stampSource(report, fp.name), nil
+}
+
+// CompositeReportProvider combina varios proveedores:
+//   - first_success: se prueban en orden y gana el primero que responde.
+//   - merge_all: se consultan todos en paralelo y se fusionan los que respondieron.
+//   - quorum: como merge_all pero exige al menos quorum respuestas; el score es la
+//     mediana y solo quedan los riesgos que reportaron al menos quorum proveedores.
+type CompositeReportProvider struct {
+	strategy  string
+	quorum    int
+	providers []ReportProvider
+}
+
+func NewCompositeReportProvider(strategy string, quorum int, providers ...ReportProvider) *CompositeReportProvider {
+	return &CompositeReportProvider{strategy: strategy, quorum: quorum, providers: providers}
+}
+
+func (cp *CompositeReportProvider) Name() string {
+	names := make([]string, len(cp.providers))
+	for i, p := range cp.providers {
+		names[i] = p.Name()
+	}
+	return cp.strategy + "(" + strings.Join(names, ",") + ")"
+}
+
+func (cp *CompositeReportProvider) FetchReport(ctx context.Context, mint string) (types.Report, error) {
+	if cp.strategy == config.StrategyFirstSuccess {
+		var errs []error
+		for _, p := range cp.providers {
+			report, err := p.FetchReport(ctx, mint)
+			if err == nil {
+				return report, nil
+			}
+			if ctx.Err() != nil {
+				return types.Report{}, ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+		}
+		return types.Report{}, errors.Join(errs...)
+	}
+
+	reports, errs := cp.fetchAll(ctx, mint)
+	switch cp.strategy {
+	case config.StrategyMergeAll:
+		if len(reports) == 0 {
+			return types.Report{}, errors.Join(errs...)
+		}
+		return mergeReports(reports, 1), nil
+	case config.StrategyQuorum:
+		if len(reports) < cp.quorum {
+			errs = append(errs, fmt.Errorf("quorum not reached: %d of %d providers answered", len(reports), cp.quorum))
+			return types.Report{}, errors.Join(errs...)
+		}
+		return mergeReports(reports, cp.quorum), nil
+	}
+	return types.Report{}, fmt.Errorf("unknown report strategy %q", cp.strategy)
+}
+
+// fetchAll consulta todos los proveedores en paralelo. Los reportes quedan en el orden
+// de los proveedores para que la fusión sea determinística.
+func (cp *CompositeReportProvider) fetchAll(ctx context.Context, mint string) ([]types.Report, []error) {
+	results := make([]types.Report, len(cp.providers))
+	errs := make([]error, len(cp.providers))
+
+	var wg sync.WaitGroup
+	for i, p := range cp.providers {
+		wg.Add(1)
+		go func(i int, p ReportProvider) {
+			defer wg.Done()
+			results[i], errs[i] = p.FetchReport(ctx, mint)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("%s: %w", p.Name(), errs[i])
+			}
+		}(i, p)
+	}
+	wg.Wait()
+
+	var reports []types.Report
+	var failures []error
+	for i := range cp.providers {
+		if errs[i] != nil {
+			failures = append(failures, errs[i])
+			continue
+		}
+		reports = append(reports, results[i])
+	}
+	return reports, failures
+}
+
+// mergeReports fusiona reportes del mismo mint. Los datos descriptivos salen del primer
+// reporte que los tenga; el score es la mediana; Rugged si alguno lo marca; y se quedan
+// los riesgos reportados por al menos minVotes proveedores (con el peor score de cada uno).
+func mergeReports(reports []types.Report, minVotes int) types.Report {
+	merged := reports[0]
+	merged.Risks = nil
+
+	var sources []string
+	scoreValues := make([]int, 0, len(reports))
+	for _, r := range reports {
+		sources = append(sources, r.Source)
+		scoreValues = append(scoreValues, r.Score)
+		merged.Rugged = merged.Rugged || r.Rugged
+		if merged.TokenMeta.Symbol == "" {
+			merged.TokenMeta = r.TokenMeta
+		}
+		if len(merged.TopHolders) == 0 {
+			merged.TopHolders = r.TopHolders
+		}
+		if merged.FreezeAuthority == "" {
+			merged.FreezeAuthority = r.FreezeAuthority
+		}
+		if merged.MintAuthority == "" {
+			merged.MintAuthority = r.MintAuthority
+		}
+		merged.TotalMarketLiquidity = max(merged.TotalMarketLiquidity, r.TotalMarketLiquidity)
+		merged.TotalLPProviders = max(merged.TotalLPProviders, r.TotalLPProviders)
+	}
+	sort.Ints(scoreValues)
+	merged.Score = scoreValues[len(scoreValues)/2]
+	merged.Source = strings.Join(sources, "+")
+
+	type vote struct {
+		risk    types.Risk
+		sources []string
+	}
+	votes := make(map[string]*vote)
+	var order []string
+	for _, r := range reports {
+		for _, risk := range r.Risks {
+			v, ok := votes[risk.Name]
+			if !ok {
+				v = &vote{risk: risk}
+				votes[risk.Name] = v
+				order = append(order, risk.Name)
+			}
+			if risk.Score > v.risk.Score {
+				v.risk.Score, v.risk.Level = risk.Score, risk.Level
+			}
+			v.sources = append(v.sources, risk.Source)
+		}
+	}
+	for _, name := range order {
+		v := votes[name]
+		if len(v.sources) < minVotes {
+			continue
+		}
+		v.risk.Source = strings.Join(v.sources, "+")
+		merged.Risks = append(merged.Risks, v.risk)
+	}
+	return merged
+}
@@ -0,0 +1,56 @@
+package monitor_test
+
+import (
+	"context"
+	"testing"
+
+	"gosol/config"
+	"gosol/monitor"
+	"gosol/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompositeReportProvider(t *testing.T) {
+	a := monitor.NewStaticReportProvider("a", map[string]types.Report{
+		"mint": {Score: 900, TokenMeta: types.TokenMeta{Symbol: "ABC"}, Risks: []types.Risk{{Name: "Mutable metadata", Score: 100}, {Name: "Low Liquidity", Score: 500}}},
+	})
+	b := monitor.NewStaticReportProvider("b", map[string]types.Report{
+		"mint": {Score: 3000, Risks: []types.Risk{{Name: "Mutable metadata", Score: 200}}},
+	})
+	c := monitor.NewStaticReportProvider("c", map[string]types.Report{
+		"mint": {Score: 1200, Risks: []types.Risk{{Name: "Mutable metadata", Score: 100}}},
+	})
+	empty := monitor.NewStaticReportProvider("empty", map[string]types.Report{})
+	ctx := context.Background()
+
+	t.Run("first_success", func(t *testing.T) {
+		report, err := monitor.NewCompositeReportProvider(config.StrategyFirstSuccess, 0, empty, b, a).FetchReport(ctx, "mint")
+		require.NoError(t, err)
+		assert.Equal(t, "b", report.Source)
+		assert.Equal(t, 3000, report.Score)
+	})
+
+	t.Run("merge_all", func(t *testing.T) {
+		report, err := monitor.NewCompositeReportProvider(config.StrategyMergeAll, 0, a, b, empty).FetchReport(ctx, "mint")
+		require.NoError(t, err)
+		assert.Equal(t, "a+b", report.Source)
+		assert.Equal(t, "ABC", report.TokenMeta.Symbol)
+		require.Len(t, report.Risks, 2)
+		assert.Equal(t, types.Risk{Name: "Mutable metadata", Score: 200, Source: "a+b"}, report.Risks[0])
+		assert.Equal(t, "a", report.Risks[1].Source)
+	})
+
+	t.Run("quorum", func(t *testing.T) {
+		report, err := monitor.NewCompositeReportProvider(config.StrategyQuorum, 2, a, b, c).FetchReport(ctx, "mint")
+		require.NoError(t, err)
+		assert.Equal(t, 1200, report.Score, "el score es la mediana")
+		require.Len(t, report.Risks, 1, "Low Liquidity solo lo reporta a")
+		assert.Equal(t, "Mutable metadata", report.Risks[0].Name)
+
+		_, err = monitor.NewCompositeReportProvider(config.StrategyQuorum, 2, a, empty).FetchReport(ctx, "mint")
+		assert.ErrorContains(t, err, "quorum not reached")
+		assert.ErrorIs(t, err, monitor.ErrReportNotFound)
+	})
+}
@@ -0,0 +1,157 @@
+package monitor
+
+import (
+	"context"
+	"gosol/config"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// rescanTick es cada cuánto el scheduler revisa qué mints toca re-escanear.
+const rescanTick = time.Second
+
+// RescanScheduler vuelve a pedir el reporte de los mints seguidos con una cadencia que
+// decae con la edad del mint (ver config.RescanConfig) hasta dejar de re-escanearlos.
+type RescanScheduler struct {
+	cfg       config.RescanConfig
+	apiClient *APIClient
+	sem       chan struct{}
+
+	mu      sync.Mutex
+	entries map[string]*rescanEntry
+}
+
+type rescanEntry struct {
+	detectedAt time.Time
+	next       time.Time
+	running    bool
+}
+
+func NewRescanScheduler(cfg config.RescanConfig, apiClient *APIClient) *RescanScheduler {
+	return &RescanScheduler{
+		cfg:       cfg,
+		apiClient: apiClient,
+		sem:       make(chan struct{}, max(cfg.MaxConcurrent, 1)),
+		entries:   make(map[string]*rescanEntry),
+	}
+}
+
+// interval devuelve la cadencia que corresponde a un mint de la edad dada, o false si ya
+// superó la última etapa y no hay que re-escanearlo más.
+func (rs *RescanScheduler) interval(age time.Duration) (time.Duration, bool) {
+	for _, stage := range rs.cfg.Stages {
+		if age < stage.Until {
+			return stage.Every, true
+		}
+	}
+	return 0, false
+}
+
+// withJitter desplaza d aleatoriamente hasta ±Jitter (fracción) para no re-escanear todo junto.
+func (rs *RescanScheduler) withJitter(d time.Duration) time.Duration {
+	if rs.cfg.Jitter <= 0 {
+		return d
+	}
+	factor := 1 + rs.cfg.Jitter*(2*rand.Float64()-1)
+	return time.Duration(float64(d) * factor)
+}
+
+// Track agrega un mint al seguimiento. Los mints que ya superaron la última etapa se ignoran.
+func (rs *RescanScheduler) Track(mint string, detectedAt time.Time) {
+	every, ok := rs.interval(time.Since(detectedAt))
+	if !ok {
+		return
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if _, exists := rs.entries[mint]; !exists {
+		rs.entries[mint] = &rescanEntry{detectedAt: detectedAt, next: time.Now().Add(rs.withJitter(every))}
+	}
+}
+
+// Untrack deja de re-escanear un mint.
+func (rs *RescanScheduler) Untrack(mint string) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	delete(rs.entries, mint)
+}
+
+// Tracked devuelve la cantidad de mints en seguimiento.
+func (rs *RescanScheduler) Tracked() int {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return len(rs.entries)
+}
+
+// Run procesa los re-escaneos hasta que se cancele ctx.
+func (rs *RescanScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(rescanTick)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, mint := range rs.due(now) {
+				select {
+				case rs.sem <- struct{}{}:
+				default:
+					// sin cupo: queda pendiente para el próximo tick
+					rs.release(mint, false)
+					continue
+				}
+				wg.Add(1)
+				go func(mint string) {
+					defer wg.Done()
+					defer func() { <-rs.sem }()
+					rs.apiClient.ProcessReport(ctx, mint)
+					rs.release(mint, true)
+				}(mint)
+			}
+		}
+	}
+}
+
+// due marca como en curso y devuelve los mints cuyo próximo re-escaneo ya venció.
+func (rs *RescanScheduler) due(now time.Time) []string {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	var mints []string
+	for mint, entry := range rs.entries {
+		if entry.running || now.Before(entry.next) {
+			continue
+		}
+		entry.running = true
+		mints = append(mints, mint)
+	}
+	return mints
+}
+
+// release libera un mint después de un intento. Si se re-escaneó se programa el próximo
+// según su edad, o se deja de seguir si superó la última etapa.
+func (rs *RescanScheduler) release(mint string, scanned bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	entry, ok := rs.entries[mint]
+	if !ok {
+		return
+	}
+	entry.running = false
+	if !scanned {
+		return
+	}
+	every, ok := rs.interval(time.Since(entry.detectedAt))
+	if !ok {
+		delete(rs.entries, mint)
+		return
+	}
+	entry.next = time.Now().Add(rs.withJitter(every))
+}
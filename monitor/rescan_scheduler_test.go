@@ -0,0 +1,139 @@
+package monitor_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gosol/config"
+	"gosol/monitor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRescanSchedulerStages(t *testing.T) {
+	cfg := config.Default().Rescan
+	rs := monitor.NewRescanScheduler(cfg, nil)
+
+	// la cadencia decae con la edad y se deja de re-escanear después de la última etapa
+	every, ok := rs.Interval(time.Minute)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, every)
+	every, ok = rs.Interval(time.Hour)
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Minute, every)
+	_, ok = rs.Interval(3 * time.Hour)
+	assert.False(t, ok)
+
+	now := time.Now()
+	rs.Track("old", now.Add(-3*time.Hour))
+	assert.Zero(t, rs.Tracked(), "un mint que superó la última etapa no se sigue")
+
+	rs.Track("new", now)
+	next, ok := rs.NextRescan("new")
+	require.True(t, ok)
+	// 30s ± 20% de jitter
+	assert.WithinDuration(t, now.Add(30*time.Second), next, 7*time.Second)
+
+	// Track no reprograma un mint que ya se sigue
+	rs.Track("new", now.Add(-time.Hour))
+	again, _ := rs.NextRescan("new")
+	assert.Equal(t, next, again)
+	assert.Equal(t, 1, rs.Tracked())
+
+	rs.Untrack("new")
+	assert.Zero(t, rs.Tracked())
+	_, ok = rs.NextRescan("new")
+	assert.False(t, ok)
+}
+
+func TestRescanSchedulerJitterBounds(t *testing.T) {
+	cfg := config.Default().Rescan
+	cfg.Jitter = 0.2
+	rs := monitor.NewRescanScheduler(cfg, nil)
+	for i := 0; i < 1000; i++ {
+		d := rs.WithJitter(10 * time.Second)
+		assert.GreaterOrEqual(t, d, 8*time.Second)
+		assert.LessOrEqual(t, d, 12*time.Second)
+	}
+
+	cfg.Jitter = 0
+	assert.Equal(t, 10*time.Second, monitor.NewRescanScheduler(cfg, nil).WithJitter(10*time.Second))
+}
+
+func TestRescanSchedulerCapsConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	scanned := make(map[string]int)
+	release := make(chan struct{})
+	api, _, statusCh := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		scanned[strings.Split(r.URL.Path, "/")[3]]++
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	cfg := config.RescanConfig{
+		Enabled:       true,
+		Stages:        []config.RescanStage{{Until: time.Minute, Every: time.Millisecond}},
+		MaxConcurrent: 2,
+	}
+	rs := monitor.NewRescanScheduler(cfg, api)
+	// "expiring" supera la única etapa después de su primer re-escaneo
+	rs.Track("expiring", time.Now().Add(-time.Minute+time.Second))
+	rs.Track("a", time.Now())
+	rs.Track("b", time.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-statusCh:
+			}
+		}
+	}()
+	done := make(chan struct{})
+	go func() {
+		rs.Run(ctx)
+		close(done)
+	}()
+
+	inFlightNow := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return inFlight
+	}
+	require.Eventually(t, func() bool { return inFlightNow() == 2 }, 3*time.Second, 10*time.Millisecond)
+	// el tercero espera un tick con cupo
+	time.Sleep(1200 * time.Millisecond)
+	assert.Equal(t, 2, inFlightNow())
+	close(release)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return scanned["expiring"] > 0 && scanned["a"] > 0 && scanned["b"] > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return rs.Tracked() == 2 }, 3*time.Second, 10*time.Millisecond)
+	_, ok := rs.NextRescan("expiring")
+	assert.False(t, ok)
+
+	cancel()
+	<-done
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, maxInFlight)
+}
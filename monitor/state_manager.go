@@ -0,0 +1,558 @@
+package monitor
+
+import (
+	"fmt"
+	"gosol/config"
+	"gosol/rules"
+	"gosol/storage"
+	"gosol/types"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxReportHistory limita la cantidad de reportes que se guardan en memoria por mint.
+const maxReportHistory = 500
+
+// StateManager guarda los mints detectados, la serie de reportes de cada uno y su historial
+// de estados. Todo cambio se persiste en el storage.Store para poder retomarlo al reiniciar.
+type StateManager struct {
+	mu            sync.RWMutex
+	store         storage.Store
+	rules         *rules.Engine
+	statusUpdates chan<- StatusMessage
+	detectedAt    map[string]time.Time
+	pools         map[string]types.PoolCreated
+	curves        map[string]types.BondingCurve
+	mentions      map[string][]types.Mention
+	mintState     map[string]types.Report
+	reportHistory map[string][]storage.ReportRecord
+	statusHistory map[string][]storage.StatusRecord
+	onMintAdded   []func(mint string, detectedAt time.Time)
+}
+
+func NewStateManager(store storage.Store, statusUpdates chan<- StatusMessage) *StateManager {
+	if store == nil {
+		store = storage.NopStore{}
+	}
+	return &StateManager{
+		store:         store,
+		rules:         rules.NewEngine(config.Default().Scoring),
+		statusUpdates: statusUpdates,
+		detectedAt:    make(map[string]time.Time),
+		pools:         make(map[string]types.PoolCreated),
+		curves:        make(map[string]types.BondingCurve),
+		mentions:      make(map[string][]types.Mention),
+		mintState:     make(map[string]types.Report),
+		reportHistory: make(map[string][]storage.ReportRecord),
+		statusHistory: make(map[string][]storage.StatusRecord),
+	}
+}
+
+// SetRules reemplaza las reglas con las que se evalúan los reportes.
+func (sm *StateManager) SetRules(engine *rules.Engine) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.rules = engine
+}
+
+// Evaluate evalúa un reporte con las reglas de scoring vigentes y las menciones del mint.
+func (sm *StateManager) Evaluate(report types.Report) rules.Result {
+	sm.mu.RLock()
+	engine := sm.rules
+	mentions := sm.mentions[report.Mint]
+	sm.mu.RUnlock()
+	return engine.Evaluate(report, mentions...)
+}
+
+// Restore carga en memoria lo persistido en sesiones anteriores.
+func (sm *StateManager) Restore() error {
+	snap, err := sm.store.Load()
+	if err != nil {
+		return err
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for _, rec := range snap.Mints {
+		sm.detectedAt[rec.Mint] = rec.DetectedAt
+		if rec.Pool != nil {
+			sm.pools[rec.Mint] = *rec.Pool
+		}
+		if rec.Curve != nil {
+			sm.curves[rec.Mint] = *rec.Curve
+		}
+		if len(rec.Mentions) > 0 {
+			sm.mentions[rec.Mint] = rec.Mentions
+		}
+	}
+	// los reportes vienen ordenados por fecha dentro de cada mint: el último gana
+	for _, rec := range snap.Reports {
+		if !rec.Discarded {
+			sm.mintState[rec.Mint] = rec.Report
+		}
+		sm.appendReport(rec)
+		if _, exists := sm.detectedAt[rec.Mint]; !exists {
+			sm.detectedAt[rec.Mint] = rec.Report.DetectedAt
+		}
+	}
+	for _, rec := range snap.Statuses {
+		sm.statusHistory[rec.Mint] = append(sm.statusHistory[rec.Mint], rec)
+	}
+	return nil
+}
+
+// AddMint registra un mint recién detectado. Si ya existía no hace nada y devuelve false.
+func (sm *StateManager) AddMint(mint string) bool {
+	sm.mu.Lock()
+	_, exists := sm.detectedAt[mint]
+	now := time.Now()
+	if !exists {
+		sm.detectedAt[mint] = now
+	}
+	rec := sm.mintRecord(mint)
+	hooks := sm.onMintAdded
+	sm.mu.Unlock()
+
+	if !exists {
+		sm.persist(sm.store.SaveMint(rec))
+		sm.RecordStatus(mint, storage.StatusDetected, "")
+		for _, hook := range hooks {
+			hook(mint, now)
+		}
+	}
+	return !exists
+}
+
+// AddPool registra la creación de un pool y su token como mint detectado. Si el mint ya
+// se conocía (por ejemplo por Telegram) se le asocia el pool.
+func (sm *StateManager) AddPool(pool types.PoolCreated) {
+	mint := pool.Token()
+
+	sm.mu.Lock()
+	detectedAt, exists := sm.detectedAt[mint]
+	if !exists {
+		detectedAt = time.Now()
+		sm.detectedAt[mint] = detectedAt
+	}
+	sm.pools[mint] = pool
+	rec := sm.mintRecord(mint)
+	hooks := sm.onMintAdded
+	sm.mu.Unlock()
+
+	sm.persist(sm.store.SaveMint(rec))
+	if !exists {
+		sm.RecordStatus(mint, storage.StatusDetected, fmt.Sprintf("%s pool %s", pool.DEX, pool.Pool))
+		for _, hook := range hooks {
+			hook(mint, detectedAt)
+		}
+	}
+}
+
+// SetCommitment actualiza hasta dónde se confirmó la transacción que creó el pool del mint.
+// Si se perdió (config.CommitmentDropped) queda además en el historial de estados.
+func (sm *StateManager) SetCommitment(mint, commitment string) {
+	sm.mu.Lock()
+	pool, ok := sm.pools[mint]
+	if !ok || pool.Commitment == commitment {
+		sm.mu.Unlock()
+		return
+	}
+	pool.Commitment = commitment
+	sm.pools[mint] = pool
+	rec := sm.mintRecord(mint)
+	sm.mu.Unlock()
+
+	sm.persist(sm.store.SaveMint(rec))
+	if commitment == config.CommitmentDropped {
+		sm.RecordStatus(mint, storage.StatusDropped, pool.Signature)
+	}
+}
+
+// RecordMention registra que una fuente nombró el mint. Solo se guarda la primera mención de
+// cada fuente; devuelve false si ya la tenía.
+func (sm *StateManager) RecordMention(mint string, mention types.Mention) bool {
+	sm.mu.Lock()
+	for _, m := range sm.mentions[mint] {
+		if m.Source == mention.Source {
+			sm.mu.Unlock()
+			return false
+		}
+	}
+	// copia: Evaluate puede estar leyendo la anterior
+	sm.mentions[mint] = append(slices.Clone(sm.mentions[mint]), mention)
+	_, detected := sm.detectedAt[mint]
+	rec := sm.mintRecord(mint)
+	sm.mu.Unlock()
+
+	if detected {
+		sm.persist(sm.store.SaveMint(rec))
+	}
+	return true
+}
+
+// Mentions devuelve las fuentes que nombraron el mint, de la primera a la última.
+func (sm *StateManager) Mentions(mint string) []types.Mention {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	return slices.Clone(sm.mentions[mint])
+}
+
+// mintRecord arma el registro persistido de un mint detectado. Se llama con el lock tomado.
+func (sm *StateManager) mintRecord(mint string) storage.MintRecord {
+	rec := storage.MintRecord{Mint: mint, DetectedAt: sm.detectedAt[mint]}
+	if pool, ok := sm.pools[mint]; ok {
+		rec.Pool = &pool
+	}
+	if curve, ok := sm.curves[mint]; ok {
+		rec.Curve = &curve
+	}
+	rec.Mentions = sm.mentions[mint]
+	return rec
+}
+
+// TrackCurve empieza a seguir la bonding curve de un token recién lanzado en pump.fun.
+// Mientras el mint no se detecte (ver AddMint) la curva solo se guarda en memoria.
+func (sm *StateManager) TrackCurve(curve types.BondingCurve) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if _, exists := sm.curves[curve.Mint]; !exists {
+		sm.curves[curve.Mint] = curve
+	}
+}
+
+// UpdateCurve actualiza el avance de una curva seguida. Devuelve false si no se sigue.
+func (sm *StateManager) UpdateCurve(mint string, progress float64, at time.Time) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	curve, ok := sm.curves[mint]
+	if !ok || curve.Complete {
+		return ok
+	}
+	curve.Progress = progress
+	curve.UpdatedAt = at
+	sm.curves[mint] = curve
+	return true
+}
+
+// CompleteCurve marca como completa la curva de un mint seguido y lo registra en su historial
+// si ya estaba detectado. Devuelve false si la curva no se seguía o ya estaba completa.
+func (sm *StateManager) CompleteCurve(mint string, at time.Time) (types.BondingCurve, bool) {
+	sm.mu.Lock()
+	curve, ok := sm.curves[mint]
+	if !ok || curve.Complete {
+		sm.mu.Unlock()
+		return curve, false
+	}
+	curve.Progress = 100
+	curve.Complete = true
+	curve.CompletedAt = at
+	curve.UpdatedAt = at
+	sm.curves[mint] = curve
+	_, detected := sm.detectedAt[mint]
+	rec := sm.mintRecord(mint)
+	sm.mu.Unlock()
+
+	if detected {
+		sm.persist(sm.store.SaveMint(rec))
+		sm.RecordStatus(mint, storage.StatusCurveComplete, "")
+	}
+	return curve, true
+}
+
+// Curve devuelve la bonding curve de un mint lanzado en pump.fun.
+func (sm *StateManager) Curve(mint string) (types.BondingCurve, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	curve, ok := sm.curves[mint]
+	return curve, ok
+}
+
+// ForgetCurves deja de seguir las curvas creadas antes de cutoff cuyo mint nunca se detectó.
+func (sm *StateManager) ForgetCurves(cutoff time.Time) int {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	forgotten := 0
+	for mint, curve := range sm.curves {
+		if _, detected := sm.detectedAt[mint]; !detected && curve.CreatedAt.Before(cutoff) {
+			delete(sm.curves, mint)
+			forgotten++
+		}
+	}
+	return forgotten
+}
+
+// Pool devuelve el pool con el que se detectó un mint.
+func (sm *StateManager) Pool(mint string) (types.PoolCreated, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	pool, ok := sm.pools[mint]
+	return pool, ok
+}
+
+// OnMintAdded registra una función que se llama cada vez que se detecta un mint nuevo.
+func (sm *StateManager) OnMintAdded(hook func(mint string, detectedAt time.Time)) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.onMintAdded = append(sm.onMintAdded, hook)
+}
+
+// DetectedMints devuelve la fecha de detección de cada mint conocido.
+func (sm *StateManager) DetectedMints() map[string]time.Time {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	mints := make(map[string]time.Time, len(sm.detectedAt))
+	for mint, at := range sm.detectedAt {
+		mints[mint] = at
+	}
+	return mints
+}
+
+// UpdateMintState guarda un nuevo reporte de un mint, lo agrega a su serie y persiste la foto.
+// Si cambió algo respecto del reporte anterior se avisa en el canal de estado.
+func (sm *StateManager) UpdateMintState(mint string, report types.Report) {
+	sm.recordReport(mint, report, false)
+}
+
+// RecordDiscardedReport agrega a la serie y persiste el reporte de un mint descartado (ver
+// config.VerdictIgnore) sin mostrarlo en la tabla ni avisar, para que un re-escaneo posterior
+// tenga contra qué compararse.
+func (sm *StateManager) RecordDiscardedReport(mint string, report types.Report) {
+	sm.recordReport(mint, report, true)
+}
+
+func (sm *StateManager) recordReport(mint string, report types.Report, discarded bool) {
+	now := time.Now()
+
+	sm.mu.Lock()
+	detectedAt, exists := sm.detectedAt[mint]
+	if !exists {
+		detectedAt = now
+		sm.detectedAt[mint] = detectedAt
+	}
+	if report.DetectedAt.IsZero() {
+		report.DetectedAt = detectedAt
+	}
+	if report.Mint == "" {
+		report.Mint = mint
+	}
+	history := sm.reportHistory[mint]
+	rec := storage.ReportRecord{Mint: mint, RecordedAt: now, Report: report, Discarded: discarded}
+	if !discarded {
+		sm.mintState[mint] = report
+	}
+	sm.appendReport(rec)
+	sm.mu.Unlock()
+
+	if !exists {
+		sm.persist(sm.store.SaveMint(storage.MintRecord{Mint: mint, DetectedAt: detectedAt}))
+	}
+	sm.persist(sm.store.SaveReport(rec))
+	if discarded {
+		return
+	}
+
+	if len(history) == 0 {
+		sm.RecordStatus(mint, storage.StatusReported, fmt.Sprintf("score %d", report.Score))
+		return
+	}
+	prev := history[len(history)-1]
+	delta := DiffReports(prev.Report, report, prev.RecordedAt, now)
+	if delta.Changed() {
+		sm.RecordStatus(mint, storage.StatusReported, delta.String())
+		if sm.statusUpdates != nil {
+			sm.statusUpdates <- StatusMessage{Level: INFO, Message: fmt.Sprintf("📈 %s: %s", symbolOrMint(report), delta)}
+		}
+	} else if prev.Discarded {
+		// se muestra por primera vez aunque el reporte no cambió (p. ej. por las menciones)
+		sm.RecordStatus(mint, storage.StatusReported, fmt.Sprintf("score %d", report.Score))
+	}
+}
+
+// appendReport agrega un reporte a la serie del mint. Se llama con el lock tomado.
+func (sm *StateManager) appendReport(rec storage.ReportRecord) {
+	history := append(sm.reportHistory[rec.Mint], rec)
+	if len(history) > maxReportHistory {
+		history = history[len(history)-maxReportHistory:]
+	}
+	sm.reportHistory[rec.Mint] = history
+}
+
+// History devuelve la serie de reportes de un mint, del más viejo al más nuevo.
+func (sm *StateManager) History(mint string) []storage.ReportRecord {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	return append([]storage.ReportRecord(nil), sm.reportHistory[mint]...)
+}
+
+// Deltas devuelve los cambios entre cada par de reportes consecutivos de un mint.
+func (sm *StateManager) Deltas(mint string) []ReportDelta {
+	history := sm.History(mint)
+	if len(history) < 2 {
+		return nil
+	}
+	deltas := make([]ReportDelta, 0, len(history)-1)
+	for i := 1; i < len(history); i++ {
+		deltas = append(deltas, DiffReports(history[i-1].Report, history[i].Report, history[i-1].RecordedAt, history[i].RecordedAt))
+	}
+	return deltas
+}
+
+func symbolOrMint(report types.Report) string {
+	if report.TokenMeta.Symbol != "" {
+		return report.TokenMeta.Symbol
+	}
+	return report.Mint
+}
+
+// RecordStatus agrega una entrada al historial de estados de un mint.
+func (sm *StateManager) RecordStatus(mint, status, detail string) {
+	rec := storage.StatusRecord{Mint: mint, At: time.Now(), Status: status, Detail: detail}
+
+	sm.mu.Lock()
+	sm.statusHistory[mint] = append(sm.statusHistory[mint], rec)
+	sm.mu.Unlock()
+
+	sm.persist(sm.store.SaveStatus(rec))
+}
+
+// LastStatus devuelve el último estado registrado de un mint ("" si no tiene).
+func (sm *StateManager) LastStatus(mint string) string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	history := sm.statusHistory[mint]
+	if len(history) == 0 {
+		return ""
+	}
+	return history[len(history)-1].Status
+}
+
+// StatusHistory devuelve el historial de estados de un mint, del más viejo al más nuevo.
+func (sm *StateManager) StatusHistory(mint string) []storage.StatusRecord {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	return append([]storage.StatusRecord(nil), sm.statusHistory[mint]...)
+}
+
+// Prune olvida (en memoria y en el store) los mints detectados antes de cutoff.
+func (sm *StateManager) Prune(cutoff time.Time) (int, error) {
+	sm.mu.Lock()
+	for mint, at := range sm.detectedAt {
+		if at.Before(cutoff) {
+			delete(sm.detectedAt, mint)
+			delete(sm.pools, mint)
+			delete(sm.curves, mint)
+			delete(sm.mentions, mint)
+			delete(sm.mintState, mint)
+			delete(sm.reportHistory, mint)
+			delete(sm.statusHistory, mint)
+		}
+	}
+	for mint, history := range sm.reportHistory {
+		i := sort.Search(len(history), func(i int) bool { return !history[i].RecordedAt.Before(cutoff) })
+		sm.reportHistory[mint] = history[i:]
+	}
+	for mint, history := range sm.statusHistory {
+		i := sort.Search(len(history), func(i int) bool { return !history[i].At.Before(cutoff) })
+		sm.statusHistory[mint] = history[i:]
+	}
+	sm.mu.Unlock()
+
+	return sm.store.Prune(cutoff)
+}
+
+// Compact compacta el almacenamiento subyacente.
+func (sm *StateManager) Compact() error {
+	return sm.store.Compact()
+}
+
+// Close cierra el almacenamiento subyacente.
+func (sm *StateManager) Close() error {
+	return sm.store.Close()
+}
+
+func (sm *StateManager) persist(err error) {
+	if err != nil && sm.statusUpdates != nil {
+		sm.statusUpdates <- StatusMessage{Level: ERR, Message: fmt.Sprintf("Error persisting state: %v", err)}
+	}
+}
+
+// GetReport devuelve el último reporte conocido de un mint.
+func (sm *StateManager) GetReport(mint string) (types.Report, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	report, ok := sm.mintState[mint]
+	return report, ok
+}
+
+// GetMintState devuelve una copia del último reporte de cada mint.
+func (sm *StateManager) GetMintState() map[string]types.Report {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	state := make(map[string]types.Report, len(sm.mintState))
+	for mint, report := range sm.mintState {
+		state[mint] = report
+	}
+	return state
+}
+
+// SendTokenUpdates envía al canal la lista completa de tokens con reporte, ordenada por fecha de detección.
+func (sm *StateManager) SendTokenUpdates(tokenUpdates chan<- []types.TokenInfo) {
+	tokenUpdates <- sm.Tokens()
+}
+
+// Tokens arma la lista de tokens con reporte, ordenada por fecha de detección.
+func (sm *StateManager) Tokens() []types.TokenInfo {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	allTokens := make([]types.TokenInfo, 0, len(sm.mintState))
+	for mint, report := range sm.mintState {
+		mentions := sm.mentions[mint]
+		result := sm.rules.Evaluate(report, mentions...)
+		token := types.TokenInfo{
+			Symbol:    report.TokenMeta.Symbol,
+			Address:   mint,
+			CreatedAt: report.DetectedAt.In(time.Local).Format("15:04"),
+			Score:     int64(result.Score),
+			Verdict:   result.Verdict,
+			Color:     result.Color,
+		}
+		if pool, ok := sm.pools[mint]; ok {
+			token.DEX = pool.DEX
+			token.Commitment = pool.Commitment
+		}
+		if curve, ok := sm.curves[mint]; ok {
+			if token.DEX == "" {
+				token.DEX = "pump.fun"
+			}
+			token.Curve = fmt.Sprintf("%.0f%%", curve.Progress)
+			if curve.Complete {
+				token.Curve = "✅"
+			}
+		}
+		if len(mentions) > 0 {
+			token.Mentions = len(mentions)
+			token.FirstMention = mentions[0].At.In(time.Local).Format("15:04")
+		}
+		allTokens = append(allTokens, token)
+	}
+
+	sort.Slice(allTokens, func(i, j int) bool {
+		return sm.mintState[allTokens[i].Address].DetectedAt.Before(sm.mintState[allTokens[j].Address].DetectedAt)
+	})
+	return allTokens
+}
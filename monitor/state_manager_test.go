@@ -0,0 +1,83 @@
+package monitor_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gosol/config"
+	"gosol/monitor"
+	"gosol/rules"
+	"gosol/storage"
+	"gosol/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateManagerRecordsMentions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gosol.db")
+	store, err := storage.OpenBolt(path)
+	require.NoError(t, err)
+	sm := monitor.NewStateManager(store, nil)
+	one := 1
+	scoring := config.Default().Scoring
+	scoring.Rules = []config.ScoringRule{{Name: "confirmed", When: config.RuleMatch{SourcesAbove: &one}, Score: -1000}}
+	sm.SetRules(rules.NewEngine(scoring))
+
+	first := time.Date(2024, 5, 1, 14, 2, 0, 0, time.Local)
+	sm.AddMint("mint")
+	assert.True(t, sm.RecordMention("mint", types.Mention{Source: "alerts", Weight: 1, At: first}))
+	assert.False(t, sm.RecordMention("mint", types.Mention{Source: "alerts", Weight: 1, At: first.Add(time.Minute)}), "solo cuenta la primera mención de cada canal")
+	assert.Equal(t, 2500, sm.Evaluate(types.Report{Mint: "mint", Score: 2500}).Score)
+
+	assert.True(t, sm.RecordMention("mint", types.Mention{Source: "calls", Weight: 0.5, At: first.Add(2 * time.Minute)}))
+	sm.UpdateMintState("mint", types.Report{Score: 2500})
+	tokens := sm.Tokens()
+	require.Len(t, tokens, 1)
+	assert.Equal(t, 2, tokens[0].Mentions)
+	assert.Equal(t, "14:02", tokens[0].FirstMention)
+	assert.Equal(t, int64(1500), tokens[0].Score)
+
+	// se restauran al reiniciar
+	require.NoError(t, sm.Close())
+	store, err = storage.OpenBolt(path)
+	require.NoError(t, err)
+	defer store.Close()
+	restored := monitor.NewStateManager(store, nil)
+	require.NoError(t, restored.Restore())
+	mentions := restored.Mentions("mint")
+	require.Len(t, mentions, 2)
+	assert.Equal(t, "alerts", mentions[0].Source)
+	assert.True(t, first.Equal(mentions[0].At))
+}
+
+func TestStateManagerKeepsDiscardedReportsOutOfTheTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gosol.db")
+	store, err := storage.OpenBolt(path)
+	require.NoError(t, err)
+	statusCh := make(chan monitor.StatusMessage, 10)
+	sm := monitor.NewStateManager(store, statusCh)
+
+	sm.RecordDiscardedReport("mint", types.Report{Score: 9000, TokenMeta: types.TokenMeta{Symbol: "ABC"}})
+	assert.Empty(t, sm.Tokens())
+	assert.Len(t, sm.History("mint"), 1)
+	assert.Empty(t, statusCh)
+
+	// se restaura en la serie pero no en la tabla
+	require.NoError(t, sm.Close())
+	store, err = storage.OpenBolt(path)
+	require.NoError(t, err)
+	defer store.Close()
+	restored := monitor.NewStateManager(store, statusCh)
+	require.NoError(t, restored.Restore())
+	assert.Empty(t, restored.Tokens())
+	require.Len(t, restored.History("mint"), 1)
+	assert.True(t, restored.History("mint")[0].Discarded)
+
+	// cuando pasa a mostrarse, el cambio se compara con la foto descartada
+	restored.UpdateMintState("mint", types.Report{Score: 3000, TokenMeta: types.TokenMeta{Symbol: "ABC"}})
+	assert.Len(t, restored.Tokens(), 1)
+	require.Len(t, statusCh, 1)
+	assert.Equal(t, "📈 ABC: score went 9000 → 3000", (<-statusCh).Message)
+}
@@ -0,0 +1,48 @@
+package monitor
+
+import "time"
+
+// Stats es una foto de las métricas internas del monitor para mostrar en la UI.
+type Stats struct {
+	ReportQueueDepth  int
+	ReportQueueCap    int
+	ReportWorkersBusy int
+	ReportsDropped    int64
+	TrackedMints      int
+	Transactions      TransactionStats
+	Tracking          TrackingStats
+	// Connection es el estado del websocket; LastMessage, cuándo llegó el último mensaje.
+	Connection  ConnectionState
+	LastMessage time.Time
+	Reconnects  int64
+	// Ingest es la cola de logs de los DEX; PumpFunIngest, la de pump.fun (vacía si no se sigue).
+	Ingest        IngestStats
+	PumpFunIngest IngestStats
+	// SlotLag es cuántos slots atrás del heartbeat está el último log procesado.
+	SlotLag uint64
+	// DuplicateSignatures y DuplicateMints son los descartados por el Deduper.
+	DuplicateSignatures int64
+	DuplicateMints      int64
+}
+
+func (app *App) Stats() Stats {
+	var stats Stats
+	stats.ReportQueueDepth, stats.ReportQueueCap, stats.ReportWorkersBusy, stats.ReportsDropped = app.ApiClient.QueueStats()
+	if app.Rescans != nil {
+		stats.TrackedMints = app.Rescans.Tracked()
+	}
+	stats.Transactions = app.transactionMgr.Stats()
+	stats.Tracking = app.tracker.Stats()
+	stats.Connection = app.wsClient.State()
+	stats.LastMessage = app.wsClient.LastMessage()
+	stats.Reconnects = app.wsClient.Reconnects()
+	stats.DuplicateSignatures, stats.DuplicateMints = app.Dedupe.Dropped()
+	stats.Ingest = app.Ingest.Stats()
+	if app.PumpFun != nil {
+		stats.PumpFunIngest = app.PumpFun.Ingest().Stats()
+	}
+	if slot := app.wsClient.Slot(); slot > stats.Ingest.LastSlot && stats.Ingest.LastSlot > 0 {
+		stats.SlotLag = slot - stats.Ingest.LastSlot
+	}
+	return stats
+}
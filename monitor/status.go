@@ -0,0 +1,15 @@
+package monitor
+
+type LogLevel int
+
+const (
+	INFO LogLevel = iota
+	WARN
+	ERR
+	NONE
+)
+
+type StatusMessage struct {
+	Level   LogLevel
+	Message string
+}
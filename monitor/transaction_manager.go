@@ -1,79 +1,393 @@
 package monitor
 
 import (
+	"container/heap"
 	"context"
+	"errors"
 	"fmt"
+	"gosol/config"
+	"gosol/dex"
 	"gosol/types"
+	"net/http"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gagliardetto/solana-go"
+	addresslookuptable "github.com/gagliardetto/solana-go/programs/address-lookup-table"
 	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
 )
 
+// TransactionManager pide por RPC las transacciones que pueden crear un pool y registra los
+// pools que encuentra. Las firmas esperan en una cola con prioridad (primero las de los DEX
+// de cfg.Priority) que atienden cfg.Workers workers (ver Start).
 type TransactionManager struct {
-	rpcClient       *rpc.Client
-	apiClient       *APIClient
-	stateManager    *StateManager
-	statusUpdates   chan<- StatusMessage
-	tokenUpdates    chan<- []types.TokenInfo
-	wg              sync.WaitGroup
-	requestThrottle chan struct{}
+	cfg           config.TransactionsConfig
+	rpcClient     *rpc.Client
+	apiClient     *APIClient
+	stateManager  *StateManager
+	registry      *dex.Registry
+	dedupe        *Deduper
+	statusUpdates chan<- StatusMessage
+	tokenUpdates  chan<- []types.TokenInfo
+	wg            sync.WaitGroup
+
+	mu    sync.Mutex
+	queue txQueue
+	seq   uint64
+	ready chan struct{}
+
+	busy      atomic.Int32
+	processed atomic.Int64
+	latency   atomic.Int64 // suma en ns, de encolada a terminada
+	failures  sync.Map     // razón -> *atomic.Int64
+
+	// lookupTables cachea el contenido de las address lookup tables (ver resolveLookupTables).
+	lookupTables sync.Map // solana.PublicKey -> solana.PublicKeySlice
+
+	// OnProcessed, si está, se llama con los pools de cada transacción procesada (ninguno si
+	// falló o no creaba pools).
+	OnProcessed func(signature solana.Signature, pools []types.PoolCreated)
+}
+
+// Prioridades de la cola de transacciones.
+const (
+	txPriorityNormal = iota
+	txPriorityHigh
+)
+
+// Razones por las que no se pudo procesar una transacción (ver TransactionStats.Failures).
+const (
+	TxFailureQueueFull = "queue_full"
+	TxFailureNotFound  = "not_found"
+	TxFailureTimeout   = "timeout"
+	TxFailureRPC       = "rpc"
+	TxFailureDecode    = "decode"
+	// TxFailureLookupTable es una transacción v0 cuyas lookup tables no se pudieron cargar.
+	TxFailureLookupTable = "lookup_table"
+)
+
+// TransactionStats es una foto de la cola y los workers del TransactionManager.
+type TransactionStats struct {
+	Depth     int
+	Capacity  int
+	Busy      int
+	Processed int64
+	// AvgLatency es el tiempo promedio desde que se encola una firma hasta que se termina
+	// de procesar.
+	AvgLatency time.Duration
+	// Failures cuenta las transacciones perdidas por razón (TxFailure*).
+	Failures map[string]int64
 }
 
-func NewTransactionManager(apiClient *APIClient, stateManager *StateManager, statusUpdates chan<- StatusMessage, tokenUpdates chan<- []types.TokenInfo) *TransactionManager {
-	rpcURL := "https://mainnet.helius-rpc.com/?api-key=" + apiKey
+type txTask struct {
+	signature solana.Signature
+	priority  int
+	seq       uint64
+	queued    time.Time
+}
+
+// txQueue es un heap de tareas: primero la de mayor prioridad y, a igual prioridad, la
+// más vieja.
+type txQueue []txTask
+
+func (q txQueue) Len() int { return len(q) }
+func (q txQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+func (q txQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *txQueue) Push(x any)   { *q = append(*q, x.(txTask)) }
+func (q *txQueue) Pop() any {
+	old := *q
+	task := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return task
+}
+
+func NewTransactionManager(cfg config.TransactionsConfig, rpcClient *rpc.Client, apiClient *APIClient, stateManager *StateManager, registry *dex.Registry, dedupe *Deduper, statusUpdates chan<- StatusMessage, tokenUpdates chan<- []types.TokenInfo) *TransactionManager {
 	return &TransactionManager{
-		rpcClient:       rpc.New(rpcURL),
-		apiClient:       apiClient,
-		stateManager:    stateManager,
-		statusUpdates:   statusUpdates,
-		tokenUpdates:    tokenUpdates,
-		requestThrottle: make(chan struct{}, 10), // Limitar a 10 consultas concurrentes
+		cfg:           cfg,
+		rpcClient:     rpcClient,
+		apiClient:     apiClient,
+		stateManager:  stateManager,
+		registry:      registry,
+		dedupe:        dedupe,
+		statusUpdates: statusUpdates,
+		tokenUpdates:  tokenUpdates,
+		ready:         make(chan struct{}, 1),
+	}
+}
+
+// Start lanza los workers que atienden la cola hasta que se cancele ctx.
+func (tm *TransactionManager) Start(ctx context.Context) {
+	for i := 0; i < max(tm.cfg.Workers, 1); i++ {
+		tm.wg.Add(1)
+		go func() {
+			defer tm.wg.Done()
+			for {
+				task, ok := tm.next(ctx)
+				if !ok {
+					return
+				}
+				tm.busy.Add(1)
+				tm.process(ctx, task)
+				tm.busy.Add(-1)
+			}
+		}()
+	}
+}
+
+// HandleTransaction encola la firma. dexes son los DEX que reconocieron sus logs: si alguno
+// está en cfg.Priority la transacción se pide antes que las demás. Con la cola llena la
+// firma se descarta.
+func (tm *TransactionManager) HandleTransaction(signature solana.Signature, dexes ...string) {
+	priority := txPriorityNormal
+	for _, name := range dexes {
+		if slices.Contains(tm.cfg.Priority, name) {
+			priority = txPriorityHigh
+			break
+		}
 	}
+
+	tm.mu.Lock()
+	if tm.queue.Len() >= max(tm.cfg.QueueSize, 1) {
+		tm.mu.Unlock()
+		tm.fail(TxFailureQueueFull)
+		tm.updateStatus(fmt.Sprintf("Transaction queue full, dropping %s", signature), WARN)
+		return
+	}
+	tm.seq++
+	heap.Push(&tm.queue, txTask{signature: signature, priority: priority, seq: tm.seq, queued: time.Now()})
+	tm.mu.Unlock()
+	signal(tm.ready)
 }
 
-func (tm *TransactionManager) HandleTransaction(signature solana.Signature) {
-	tm.wg.Add(1)
-	go func(sig solana.Signature) {
-		defer tm.wg.Done()
-		tm.fetchAndProcessTransaction(sig)
-	}(signature)
+// next espera la próxima tarea de la cola. Devuelve false si se canceló ctx.
+func (tm *TransactionManager) next(ctx context.Context) (txTask, bool) {
+	for {
+		tm.mu.Lock()
+		if tm.queue.Len() > 0 {
+			task := heap.Pop(&tm.queue).(txTask)
+			more := tm.queue.Len() > 0
+			tm.mu.Unlock()
+			if more {
+				// que otro worker tome la siguiente
+				signal(tm.ready)
+			}
+			return task, true
+		}
+		tm.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return txTask{}, false
+		case <-tm.ready:
+		}
+	}
 }
 
-func (tm *TransactionManager) fetchAndProcessTransaction(signature solana.Signature) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// process pide la transacción (con reintentos, dentro de cfg.Timeout) y registra los pools
+// que crea.
+func (tm *TransactionManager) process(ctx context.Context, task txTask) {
+	defer func() {
+		tm.processed.Add(1)
+		tm.latency.Add(int64(time.Since(task.queued)))
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, tm.cfg.Timeout)
 	defer cancel()
 
-	updateStatus := func(message string, level LogLevel) {
-		tm.statusUpdates <- StatusMessage{Level: level, Message: message}
+	tx, err := tm.fetchTransaction(ctx, task.signature)
+	if err != nil {
+		reason := txFailureReason(ctx, err)
+		tm.fail(reason)
+		if reason != TxFailureNotFound && ctx.Err() != context.Canceled {
+			tm.updateStatus(fmt.Sprintf("Error fetching transaction %s: %v", task.signature, err), ERR)
+		}
+		return
+	}
+	tm.processTransaction(ctx, task.signature, tx)
+}
+
+// maxSupportedTransactionVersion es la versión más nueva de transacción que se pide; sin
+// ella el nodo rechaza las transacciones v0.
+var maxSupportedTransactionVersion uint64
+
+// fetchTransaction hace getTransaction reintentando los errores transitorios.
+//
+// Se pide base64 y no jsonParsed a propósito: jsonParsed solo estructura las instrucciones
+// de los programas que el nodo conoce (System, SPL Token...) y deja las de Raydium, Orca o
+// Meteora como data en base58 sin decodificar, y solana-go no puede armar un
+// solana.Transaction a partir de esa respuesta. Con base64 se obtiene la transacción binaria
+// y dex.Instructions la convierte en instrucciones estructuradas (programa, cuentas con las
+// address lookup tables resueltas, data cruda) que los detectores de cada DEX decodifican.
+func (tm *TransactionManager) fetchTransaction(ctx context.Context, signature solana.Signature) (*rpc.GetTransactionResult, error) {
+	for attempt := 0; ; attempt++ {
+		tx, err := tm.rpcClient.GetTransaction(
+			ctx,
+			signature,
+			&rpc.GetTransactionOpts{
+				Encoding:                       solana.EncodingBase64,
+				Commitment:                     rpc.CommitmentConfirmed,
+				MaxSupportedTransactionVersion: &maxSupportedTransactionVersion,
+			},
+		)
+		if err == nil {
+			return tx, nil
+		}
+		if ctx.Err() != nil || !transientRPCError(err) || attempt >= tm.cfg.MaxRetries {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoffDelay(attempt, tm.cfg.BackoffBase, tm.cfg.BackoffMax)):
+		}
+	}
+}
+
+// transientRPCError indica si vale la pena reintentar: la transacción todavía no está
+// disponible en el nodo, rate limit, errores del servidor o de red.
+func transientRPCError(err error) bool {
+	if errors.Is(err, rpc.ErrNotFound) {
+		return true
+	}
+	var httpErr *jsonrpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code == http.StatusTooManyRequests || httpErr.Code >= 500
+	}
+	var rpcErr *jsonrpc.RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code == http.StatusTooManyRequests || rpcErr.Code == -32429 ||
+			strings.Contains(strings.ToLower(rpcErr.Message), "too many requests")
+	}
+	return true
+}
+
+func txFailureReason(ctx context.Context, err error) string {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded), errors.Is(err, context.DeadlineExceeded):
+		return TxFailureTimeout
+	case errors.Is(err, rpc.ErrNotFound):
+		return TxFailureNotFound
+	default:
+		return TxFailureRPC
 	}
+}
 
-	tx, err := tm.rpcClient.GetTransaction(
-		ctx,
-		signature,
-		&rpc.GetTransactionOpts{
-			Encoding:                       solana.EncodingBase58,
-			Commitment:                     rpc.CommitmentConfirmed,
-			MaxSupportedTransactionVersion: nil, // Usa el valor por defecto
-		},
-	)
+func (tm *TransactionManager) processTransaction(ctx context.Context, signature solana.Signature, tx *rpc.GetTransactionResult) {
+	if tx == nil || tx.Transaction == nil || tx.Meta == nil || tx.Meta.Err != nil {
+		tm.notifyProcessed(signature, nil)
+		return
+	}
+	txn, err := tx.Transaction.GetTransaction()
 	if err != nil {
-		// updateStatus(fmt.Sprintf("Error fetching transaction %s", signature), ERR)
+		tm.fail(TxFailureDecode)
+		tm.updateStatus(fmt.Sprintf("Error decoding transaction %s: %v", signature, err), ERR)
+		return
+	}
+	if err := tm.resolveLookupTables(ctx, txn, tx.Meta); err != nil {
+		tm.fail(TxFailureLookupTable)
+		tm.updateStatus(fmt.Sprintf("Error loading lookup tables of %s: %v", signature, err), ERR)
 		return
 	}
 
-	if tx != nil {
-		for _, balance := range tx.Meta.PostTokenBalances {
-			if balance.Owner.String() == "5Q544fKrFoe6tsEbD7S8EmxGTJYAKtTVhAW5Q5pge4j1" && balance.Mint.String() != "So11111111111111111111111111111111111111112" {
-				updateStatus(fmt.Sprintf("========== New Token Found: %s ==========", balance.Mint.String()), INFO)
-				tm.stateManager.AddMint(balance.Mint.String())
-				tm.apiClient.FetchAndProcessReport(balance.Mint.String())
-			}
+	pools, err := tm.registry.FindPoolsCreated(txn, tx.Meta)
+	if err != nil {
+		tm.fail(TxFailureDecode)
+		tm.updateStatus(fmt.Sprintf("Error decoding instructions of %s: %v", signature, err), ERR)
+	}
+	for i := range pools {
+		pool := &pools[i]
+		pool.Slot = tx.Slot
+		if tx.BlockTime != nil {
+			pool.BlockTime = tx.BlockTime.Time()
 		}
+		// getTransaction solo devuelve transacciones confirmadas
+		pool.Commitment = config.CommitmentConfirmed
+		mint := pool.Token()
+		tm.stateManager.AddPool(*pool)
+		// p. ej. un token de pump.fun que migra a Raydium ya se reportó al completar la curva
+		if tm.dedupe.FirstMint(mint) {
+			tm.apiClient.FetchAndProcessReport(mint)
+		}
+	}
+	tm.notifyProcessed(signature, pools)
+}
+
+func (tm *TransactionManager) notifyProcessed(signature solana.Signature, pools []types.PoolCreated) {
+	if tm.OnProcessed != nil {
+		tm.OnProcessed(signature, pools)
+	}
+}
+
+// resolveLookupTables carga las address lookup tables de una transacción v0 cuando el nodo
+// no informó las direcciones cargadas. Las tablas solo crecen, así que se cachean y se
+// vuelven a pedir cuando una transacción usa un índice que la copia no tiene.
+func (tm *TransactionManager) resolveLookupTables(ctx context.Context, tx *solana.Transaction, meta *rpc.TransactionMeta) error {
+	if !dex.NeedsLookupTables(tx, meta) {
+		return nil
+	}
+	tables := make(map[solana.PublicKey]solana.PublicKeySlice, tx.Message.NumLookups())
+	for _, lookup := range tx.Message.AddressTableLookups {
+		needed := 0
+		for _, idx := range slices.Concat(lookup.WritableIndexes, lookup.ReadonlyIndexes) {
+			needed = max(needed, int(idx)+1)
+		}
+		cached, ok := tm.lookupTables.Load(lookup.AccountKey)
+		if ok && len(cached.(solana.PublicKeySlice)) >= needed {
+			tables[lookup.AccountKey] = cached.(solana.PublicKeySlice)
+			continue
+		}
+		state, err := addresslookuptable.GetAddressLookupTable(ctx, tm.rpcClient, lookup.AccountKey)
+		if err != nil {
+			return fmt.Errorf("lookup table %s: %w", lookup.AccountKey, err)
+		}
+		tm.lookupTables.Store(lookup.AccountKey, state.Addresses)
+		tables[lookup.AccountKey] = state.Addresses
+	}
+	return tx.Message.SetAddressTables(tables)
+}
+
+func (tm *TransactionManager) fail(reason string) {
+	counter, _ := tm.failures.LoadOrStore(reason, new(atomic.Int64))
+	counter.(*atomic.Int64).Add(1)
+}
+
+// Stats devuelve el estado de la cola y las métricas acumuladas.
+func (tm *TransactionManager) Stats() TransactionStats {
+	tm.mu.Lock()
+	depth := tm.queue.Len()
+	tm.mu.Unlock()
+
+	stats := TransactionStats{
+		Depth:     depth,
+		Capacity:  tm.cfg.QueueSize,
+		Busy:      int(tm.busy.Load()),
+		Processed: tm.processed.Load(),
+		Failures:  make(map[string]int64),
+	}
+	if stats.Processed > 0 {
+		stats.AvgLatency = time.Duration(tm.latency.Load() / stats.Processed)
 	}
+	tm.failures.Range(func(reason, counter any) bool {
+		stats.Failures[reason.(string)] = counter.(*atomic.Int64).Load()
+		return true
+	})
+	return stats
+}
+
+func (tm *TransactionManager) updateStatus(message string, level LogLevel) {
+	tm.statusUpdates <- StatusMessage{Level: level, Message: message}
 }
 
+// Wait espera a que terminen los workers (después de cancelar el contexto de Start).
 func (tm *TransactionManager) Wait() {
 	tm.wg.Wait()
 }
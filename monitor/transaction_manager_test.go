@@ -0,0 +1,100 @@
+package monitor_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"gosol/config"
+	"gosol/dex"
+	"gosol/monitor"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTransactionRPC responde getTransaction con las respuestas de cada firma, en orden
+// (nil = todavía no disponible), y registra las firmas pedidas.
+func fakeTransactionRPC(t *testing.T, responses map[string][]any) (*rpc.Client, func() []string) {
+	var mu sync.Mutex
+	var requested []string
+	client := newFakeRPC(t, map[string]rpcMethod{
+		"getTransaction": func(params []json.RawMessage) (any, error) {
+			var sig string
+			if err := rpcParam(params, 0, &sig); err != nil {
+				return nil, err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			requested = append(requested, sig)
+			var result any
+			if pending := responses[sig]; len(pending) > 0 {
+				result, responses[sig] = pending[0], pending[1:]
+			}
+			return result, nil
+		},
+	})
+	return client, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requested...)
+	}
+}
+
+// failedTransaction es una transacción que falló: se procesa sin decodificarla.
+var failedTransaction = map[string]any{
+	"slot":        1,
+	"transaction": []string{"", "base64"},
+	"meta":        map[string]any{"err": map[string]any{"InstructionError": []any{0, "Custom"}}},
+}
+
+func TestTransactionManagerPrioritizesAndRetries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	normal, retried, high, missing, dropped := solana.Signature{1}, solana.Signature{2}, solana.Signature{3}, solana.Signature{4}, solana.Signature{5}
+	client, requested := fakeTransactionRPC(t, map[string][]any{
+		normal.String():  {failedTransaction},
+		retried.String(): {nil, failedTransaction},
+		high.String():    {failedTransaction},
+	})
+	cfg := config.TransactionsConfig{
+		Workers:     1,
+		QueueSize:   4,
+		Timeout:     5 * time.Second,
+		MaxRetries:  1,
+		BackoffBase: time.Millisecond,
+		BackoffMax:  time.Millisecond,
+		Priority:    []string{dex.DEXRaydiumCPMM},
+	}
+	tm := monitor.NewTransactionManager(cfg, client, nil, nil, dex.NewRegistry(), nil, drainStatus(ctx), nil)
+
+	tm.HandleTransaction(normal, dex.DEXOrcaWhirlpool)
+	tm.HandleTransaction(retried)
+	tm.HandleTransaction(high, dex.DEXOrcaWhirlpool, dex.DEXRaydiumCPMM)
+	tm.HandleTransaction(missing)
+	tm.HandleTransaction(dropped)
+	assert.Equal(t, 4, tm.Stats().Depth)
+
+	tm.Start(ctx)
+	require.Eventually(t, func() bool { return tm.Stats().Processed == 4 }, 5*time.Second, 10*time.Millisecond)
+
+	// primero la prioritaria; la que no estaba disponible se reintenta
+	assert.Equal(t, []string{
+		high.String(), normal.String(),
+		retried.String(), retried.String(),
+		missing.String(), missing.String(),
+	}, requested())
+
+	stats := tm.Stats()
+	assert.Zero(t, stats.Depth)
+	assert.Equal(t, map[string]int64{monitor.TxFailureQueueFull: 1, monitor.TxFailureNotFound: 1}, stats.Failures)
+	assert.Positive(t, stats.AvgLatency)
+
+	cancel()
+	tm.Wait()
+}
@@ -2,7 +2,11 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"gosol/config"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gagliardetto/solana-go"
@@ -10,101 +14,636 @@ import (
 	"github.com/gagliardetto/solana-go/rpc/ws"
 )
 
+// SubscriptionKind es el método de suscripción del websocket de Solana.
+type SubscriptionKind string
+
+const (
+	// SubscriptionLogs es logsSubscribe de las transacciones que mencionan Account.
+	SubscriptionLogs SubscriptionKind = "logs"
+	// SubscriptionAccount es accountSubscribe a los cambios de Account.
+	SubscriptionAccount SubscriptionKind = "account"
+	// SubscriptionProgram es programSubscribe a las cuentas cuyo dueño es el programa Account.
+	SubscriptionProgram SubscriptionKind = "program"
+	// SubscriptionSignature es signatureSubscribe; el nodo la cancela al notificar, así que
+	// se quita sola después del primer mensaje.
+	SubscriptionSignature SubscriptionKind = "signature"
+	// SubscriptionSlot es slotSubscribe; llega un mensaje por slot (~400ms).
+	SubscriptionSlot SubscriptionKind = "slot"
+)
+
+// ConnectionState es el estado de la conexión del websocket.
+type ConnectionState string
+
+const (
+	// ConnectionConnecting: conectando o suscribiendo.
+	ConnectionConnecting ConnectionState = "connecting"
+	// ConnectionLive: conectado y recibiendo mensajes.
+	ConnectionLive ConnectionState = "live"
+	// ConnectionDegraded: conectado pero sin mensajes hace más de reconnect.degraded_after.
+	ConnectionDegraded ConnectionState = "degraded"
+	// ConnectionDown: sin conexión, esperando para reintentar.
+	ConnectionDown ConnectionState = "down"
+)
+
+// Subscription describe una suscripción con nombre. Según Kind se usa Account o Signature y
+// el handler correspondiente; los handlers se llaman desde la goroutine de la suscripción y
+// tienen que ser rápidos (p. ej. encolar en una IngestQueue), porque mientras tanto no se
+// leen más mensajes de esa suscripción.
+type Subscription struct {
+	Name      string
+	Kind      SubscriptionKind
+	Account   solana.PublicKey
+	Signature solana.Signature
+	// Commitment vacío usa solana.commitment.
+	Commitment rpc.CommitmentType
+
+	OnLogs      func(*ws.LogResult)
+	OnAccount   func(*ws.AccountResult)
+	OnProgram   func(*ws.ProgramResult)
+	OnSignature func(*ws.SignatureResult)
+	OnSlot      func(*ws.SlotResult)
+}
+
+// LogsSubscription es una suscripción a los logs que mencionan account que los envía a ch.
+func LogsSubscription(name string, account solana.PublicKey, ch chan<- *ws.LogResult) Subscription {
+	return Subscription{
+		Name:    name,
+		Kind:    SubscriptionLogs,
+		Account: account,
+		OnLogs:  func(msg *ws.LogResult) { ch <- msg },
+	}
+}
+
+func (s Subscription) validate() error {
+	if s.Name == "" {
+		return errors.New("subscription name is required")
+	}
+	var handler bool
+	switch s.Kind {
+	case SubscriptionLogs:
+		handler = s.OnLogs != nil
+	case SubscriptionAccount:
+		handler = s.OnAccount != nil
+	case SubscriptionProgram:
+		handler = s.OnProgram != nil
+	case SubscriptionSignature:
+		handler = s.OnSignature != nil
+	case SubscriptionSlot:
+		handler = s.OnSlot != nil
+	default:
+		return fmt.Errorf("subscription %s: unknown kind %q", s.Name, s.Kind)
+	}
+	if !handler {
+		return fmt.Errorf("subscription %s: missing %s handler", s.Name, s.Kind)
+	}
+	return nil
+}
+
+// WebSocketClient mantiene un conjunto de suscripciones con nombre sobre una sola conexión.
+// Las suscripciones se pueden agregar y quitar en cualquier momento: si hay conexión se
+// activan enseguida y, si no, al conectar. Después de reconectar se restablecen todas.
+//
+// Run supervisa la conexión: si se corta algún stream (las firmas no cuentan, ver Subscribe),
+// si deja de llegar cualquier mensaje (incluido el heartbeat de slots) por más de
+// reconnect.stale_after, o si vence el ping/pong del cliente (que corta la lectura y con ella
+// todos los streams), reconecta con backoff. Eso vigila la conexión, no cada stream: si un
+// stream de logs, cuentas o programas deja de recibir mientras siguen llegando los slots, la
+// conexión se muestra degradada (ver streamActivity).
 type WebSocketClient struct {
-	client        *ws.Client
-	logCh         chan<- *ws.LogResult
+	cfg           config.SolanaConfig
 	statusUpdates chan<- StatusMessage
+
+	mu     sync.Mutex
+	client *ws.Client
+	// ctx es el de la conexión actual (nil si no hay conexión).
+	ctx context.Context
+	// broken recibe el error del primer stream que se corta en la conexión actual.
+	broken chan error
+	subs   map[string]*activeSubscription
+	// order mantiene el orden en que se agregaron, para suscribir siempre igual.
+	order []string
+	state ConnectionState
+
+	lastMessage atomic.Int64 // unix nano
+	lastSlot    atomic.Uint64
+	reconnects  atomic.Int64
+	// activity es el ritmo de cada stream de logs, cuentas o programas, por nombre.
+	activity map[string]*streamActivity
+
+	// OnReconnect se llama después de volver a suscribir todo tras una caída.
+	OnReconnect func()
+}
+
+type activeSubscription struct {
+	Subscription
+	// stop corta la suscripción en la conexión actual (nil si no está activa).
+	stop context.CancelFunc
 }
 
-func NewWebSocketClient(logCh chan<- *ws.LogResult, statusUpdates chan<- StatusMessage) *WebSocketClient {
-	return &WebSocketClient{
-		logCh:         logCh,
+func NewWebSocketClient(cfg config.SolanaConfig, statusUpdates chan<- StatusMessage) *WebSocketClient {
+	wsc := &WebSocketClient{
+		cfg:           cfg,
 		statusUpdates: statusUpdates,
+		subs:          make(map[string]*activeSubscription),
+		activity:      make(map[string]*streamActivity),
+		state:         ConnectionDown,
 	}
+	if cfg.Reconnect.StaleAfter > 0 {
+		// los logs pueden tardar minutos entre lanzamientos; los slots llegan siempre
+		wsc.Add(Subscription{
+			Name:   "heartbeat",
+			Kind:   SubscriptionSlot,
+			OnSlot: func(msg *ws.SlotResult) { wsc.lastSlot.Store(msg.Slot) },
+		})
+	}
+	return wsc
 }
 
-func (wsc *WebSocketClient) Connect(ctx context.Context) error {
-	url := fmt.Sprintf("%s%s", websocketURL, apiKey)
+// Add registra una suscripción; si ya hay conexión la activa enseguida. El nombre no se
+// puede repetir.
+func (wsc *WebSocketClient) Add(sub Subscription) error {
+	if err := sub.validate(); err != nil {
+		return err
+	}
+
+	wsc.mu.Lock()
+	if _, ok := wsc.subs[sub.Name]; ok {
+		wsc.mu.Unlock()
+		return fmt.Errorf("subscription %s already exists", sub.Name)
+	}
+	s := &activeSubscription{Subscription: sub}
+	wsc.subs[sub.Name] = s
+	wsc.order = append(wsc.order, sub.Name)
+	var job *subscribeJob
+	if wsc.client != nil {
+		job = wsc.prepare(s)
+	}
+	wsc.mu.Unlock()
 
-	client, err := ws.Connect(ctx, url)
+	return wsc.start(job)
+}
+
+// AddLogSubscription agrega una suscripción a los logs que mencionan account; los mensajes
+// se envían a ch.
+func (wsc *WebSocketClient) AddLogSubscription(name string, account solana.PublicKey, ch chan<- *ws.LogResult) error {
+	return wsc.Add(LogsSubscription(name, account, ch))
+}
+
+// Remove corta y olvida la suscripción. Devuelve false si no existía.
+func (wsc *WebSocketClient) Remove(name string) bool {
+	wsc.mu.Lock()
+	defer wsc.mu.Unlock()
+	return wsc.remove(name)
+}
+
+func (wsc *WebSocketClient) remove(name string) bool {
+	s, ok := wsc.subs[name]
+	if !ok {
+		return false
+	}
+	if s.stop != nil {
+		s.stop()
+	}
+	delete(wsc.subs, name)
+	delete(wsc.activity, name)
+	for i, n := range wsc.order {
+		if n == name {
+			wsc.order = append(wsc.order[:i], wsc.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Subscriptions devuelve los nombres de las suscripciones registradas.
+func (wsc *WebSocketClient) Subscriptions() []string {
+	wsc.mu.Lock()
+	defer wsc.mu.Unlock()
+	return append([]string(nil), wsc.order...)
+}
+
+// Connect abre una conexión nueva y cierra la anterior. Las suscripciones quedan
+// inactivas hasta llamar a Subscribe.
+func (wsc *WebSocketClient) Connect(ctx context.Context) error {
+	client, err := ws.Connect(ctx, wsc.cfg.WebsocketEndpoint())
 	if err != nil {
 		wsc.updateStatus(fmt.Sprintf("Failed to connect to WebSocket: %v", err), ERR)
 		return err
 	}
+
+	wsc.mu.Lock()
+	defer wsc.mu.Unlock()
+	wsc.disconnect()
 	wsc.client = client
+	wsc.broken = make(chan error, 1)
+	now := time.Now()
+	wsc.lastMessage.Store(now.UnixNano())
+	for _, a := range wsc.activity {
+		a.reset(now)
+	}
 	return nil
 }
 
+// disconnect corta las suscripciones activas y cierra la conexión. Se llama con el lock tomado.
+func (wsc *WebSocketClient) disconnect() {
+	for _, s := range wsc.subs {
+		if s.stop != nil {
+			s.stop()
+			s.stop = nil
+		}
+	}
+	if wsc.client != nil {
+		wsc.client.Close()
+		wsc.client = nil
+	}
+	wsc.ctx = nil
+}
+
+// Subscribe activa en la conexión actual todas las suscripciones registradas. Las que ya
+// estaban activas en una conexión anterior se vuelven a crear.
 func (wsc *WebSocketClient) Subscribe(ctx context.Context) error {
-	program := solana.MustPublicKeyFromBase58(pubkey)
+	wsc.mu.Lock()
+	if wsc.client == nil {
+		wsc.mu.Unlock()
+		return errors.New("websocket not connected")
+	}
+	wsc.ctx = ctx
+	jobs := make([]*subscribeJob, 0, len(wsc.order))
+	for _, name := range wsc.order {
+		jobs = append(jobs, wsc.prepare(wsc.subs[name]))
+	}
+	wsc.mu.Unlock()
+
+	// solo fallan la conexión los streams (logs, cuentas, programas, slots): las firmas de
+	// cada detección se pueden perder, el CommitmentTracker consulta su estado en Check
+	var errs []error
+	for _, job := range jobs {
+		if err := wsc.start(job); err != nil && job.Kind != SubscriptionSignature {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// subscribeJob es lo necesario para crear una suscripción en la conexión actual sin tener
+// el lock tomado (ver prepare y start).
+type subscribeJob struct {
+	Subscription
+	client     *ws.Client
+	commitment rpc.CommitmentType
+	ctx        context.Context
+	stop       context.CancelFunc
+	broken     chan<- error
+	activity   *streamActivity
+}
 
-	sub, err := wsc.client.LogsSubscribeMentions(
-		program,
-		rpc.CommitmentConfirmed,
-	)
+// prepare corta la suscripción en la conexión anterior y arma lo necesario para crearla en
+// la actual. Devuelve nil si todavía no hay Subscribe. Se llama con el lock tomado.
+func (wsc *WebSocketClient) prepare(s *activeSubscription) *subscribeJob {
+	if s.stop != nil {
+		s.stop()
+		s.stop = nil
+	}
+	parent := wsc.ctx
+	if parent == nil {
+		// conectado pero todavía sin Subscribe: se activa cuando se llame
+		return nil
+	}
+
+	commitment := s.Commitment
+	if commitment == "" {
+		commitment = rpc.CommitmentType(wsc.cfg.Commitment)
+	}
+	ctx, stop := context.WithCancel(parent)
+	// Remove o una reconexión la cortan aunque start todavía no haya terminado
+	s.stop = stop
+	job := &subscribeJob{Subscription: s.Subscription, client: wsc.client, commitment: commitment, ctx: ctx, stop: stop, broken: wsc.broken}
+	switch s.Kind {
+	case SubscriptionLogs, SubscriptionAccount, SubscriptionProgram:
+		if wsc.activity[s.Name] == nil {
+			wsc.activity[s.Name] = newStreamActivity(time.Now())
+		}
+		job.activity = wsc.activity[s.Name]
+	}
+	return job
+}
+
+// start crea la suscripción preparada y lanza la goroutine que la consume. Se llama sin el
+// lock: suscribir es un ida y vuelta por la red y el aviso de error puede bloquear.
+func (wsc *WebSocketClient) start(job *subscribeJob) error {
+	if job == nil {
+		return nil
+	}
+	ctx, broken, commitment := job.ctx, job.broken, job.commitment
+	var err error
+	switch job.Kind {
+	case SubscriptionLogs:
+		var sub *ws.LogSubscription
+		if sub, err = job.client.LogsSubscribeMentions(job.Account, commitment); err == nil {
+			go consume(ctx, wsc, broken, job.activity, job.Subscription, sub, job.OnLogs)
+		}
+	case SubscriptionAccount:
+		var sub *ws.AccountSubscription
+		if sub, err = job.client.AccountSubscribe(job.Account, commitment); err == nil {
+			go consume(ctx, wsc, broken, job.activity, job.Subscription, sub, job.OnAccount)
+		}
+	case SubscriptionProgram:
+		var sub *ws.ProgramSubscription
+		if sub, err = job.client.ProgramSubscribe(job.Account, commitment); err == nil {
+			go consume(ctx, wsc, broken, job.activity, job.Subscription, sub, job.OnProgram)
+		}
+	case SubscriptionSignature:
+		var sub *ws.SignatureSubscription
+		if sub, err = job.client.SignatureSubscribe(job.Signature, commitment); err == nil {
+			name := job.Name
+			handle := job.OnSignature
+			go consume(ctx, wsc, broken, nil, job.Subscription, sub, func(msg *ws.SignatureResult) {
+				handle(msg)
+				wsc.Remove(name)
+			})
+		}
+	case SubscriptionSlot:
+		var sub *ws.SlotSubscription
+		if sub, err = job.client.SlotSubscribe(); err == nil {
+			go consume(ctx, wsc, broken, job.activity, job.Subscription, sub, job.OnSlot)
+		}
+	}
 	if err != nil {
-		wsc.updateStatus(fmt.Sprintf("Failed to subscribe to logs: %v", err), ERR)
-		return err
+		canceled := ctx.Err() != nil
+		job.stop()
+		if canceled {
+			// se quitó o se cerró la conexión mientras tanto: el error no importa
+			return nil
+		}
+		if job.Kind == SubscriptionSignature {
+			wsc.dropSignature(job.Name, err)
+		} else {
+			wsc.updateStatus(fmt.Sprintf("Failed to subscribe to %s: %v", job.Name, err), ERR)
+		}
+		return fmt.Errorf("subscribing to %s: %w", job.Name, err)
 	}
+	return nil
+}
 
-	go func() {
-		defer sub.Unsubscribe()
-		wsc.updateStatus("Start monitoring...", INFO)
-		for {
-			time.Sleep(1 * time.Second)
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				msg, err := sub.Recv(ctx)
-				if err != nil {
-					wsc.updateStatus(fmt.Sprintf("WebSocket error: %v", err), ERR)
-					return
+// dropSignature olvida una suscripción a una firma que falló para no volver a pedirla en
+// cada reconexión (p. ej. si el nodo llegó a su límite de suscripciones).
+func (wsc *WebSocketClient) dropSignature(name string, err error) {
+	wsc.Remove(name)
+	wsc.updateStatus(fmt.Sprintf("Failed to watch %s, polling its status instead: %v", name, err), WARN)
+}
+
+// stream es lo que tienen en común las suscripciones de ws.
+type stream[T any] interface {
+	Recv(ctx context.Context) (T, error)
+	Unsubscribe()
+}
+
+// consume entrega los mensajes de la suscripción a handle hasta que se corte ctx o falle
+// el stream; en ese caso avisa por broken para que Run reconecte.
+func consume[T any](ctx context.Context, wsc *WebSocketClient, broken chan<- error, activity *streamActivity, s Subscription, sub stream[T], handle func(T)) {
+	defer sub.Unsubscribe()
+	// las de firmas se abren por cada detección: anunciarlas solo llenaría el log
+	if s.Kind != SubscriptionSignature {
+		wsc.updateStatus(fmt.Sprintf("Start monitoring %s...", s.Name), INFO)
+	}
+	for {
+		msg, err := sub.Recv(ctx)
+		if err != nil {
+			// una firma que se corta sola no justifica reconectar: queda registrada para la
+			// próxima conexión y mientras tanto el CommitmentTracker consulta su estado en Check;
+			// si se cayó la conexión, los demás streams lo avisan
+			if ctx.Err() == nil && s.Kind != SubscriptionSignature {
+				wsc.updateStatus(fmt.Sprintf("WebSocket error on %s: %v", s.Name, err), ERR)
+				select {
+				case broken <- fmt.Errorf("%s stream ended: %w", s.Name, err):
+				default:
 				}
-				wsc.logCh <- msg
+			}
+			return
+		}
+		now := time.Now()
+		wsc.lastMessage.Store(now.UnixNano())
+		if activity != nil {
+			activity.observe(now)
+		}
+		handle(msg)
+	}
+}
+
+// Reconnect conecta y suscribe, reintentando con backoff hasta lograrlo o que se cancele ctx.
+func (wsc *WebSocketClient) Reconnect(ctx context.Context) {
+	backoff := wsc.cfg.Reconnect.BackoffBase
+	maxBackoff := max(wsc.cfg.Reconnect.BackoffMax, backoff)
+	if backoff <= 0 {
+		backoff, maxBackoff = time.Second, 30*time.Second
+	}
+
+	for ctx.Err() == nil {
+		wsc.setState(ConnectionConnecting)
+		err := wsc.Connect(ctx)
+		if err == nil {
+			if err = wsc.Subscribe(ctx); err != nil {
+				err = fmt.Errorf("subscription failed: %w", err)
 			}
 		}
+		if err == nil {
+			wsc.updateStatus("Successfully reconnected and subscribed.", INFO)
+			wsc.setState(ConnectionLive)
+			return
+		}
+
+		wsc.setState(ConnectionDown)
+		wsc.updateStatus(fmt.Sprintf("Connection failed: %v. Retrying in %s...", err, backoff), ERR)
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// Run mantiene la conexión hasta que se cancele ctx: conecta, vigila que los streams sigan
+// vivos y, si se cortan o dejan de recibir mensajes, vuelve a conectar y suscribir todo.
+func (wsc *WebSocketClient) Run(ctx context.Context) {
+	defer func() {
+		wsc.mu.Lock()
+		wsc.disconnect()
+		wsc.mu.Unlock()
+		wsc.setState(ConnectionDown)
 	}()
 
-	return nil
+	wsc.Reconnect(ctx)
+	for {
+		reason := wsc.watch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		wsc.reconnects.Add(1)
+		wsc.setState(ConnectionDown)
+		wsc.updateStatus(fmt.Sprintf("WebSocket down: %v. Reconnecting...", reason), WARN)
+
+		wsc.Reconnect(ctx)
+		if ctx.Err() == nil && wsc.OnReconnect != nil {
+			wsc.OnReconnect()
+		}
+	}
 }
 
-func (wsc *WebSocketClient) Reconnect(ctx context.Context) {
-	backoff := 1 * time.Second
-	maxBackoff := 30 * time.Second
+// watch espera hasta que la conexión actual deje de servir y devuelve el motivo.
+func (wsc *WebSocketClient) watch(ctx context.Context) error {
+	wsc.mu.Lock()
+	broken := wsc.broken
+	wsc.mu.Unlock()
+
+	rc := wsc.cfg.Reconnect
+	check := time.Second
+	if rc.DegradedAfter > 0 {
+		check = min(check, rc.DegradedAfter/2)
+	}
+	ticker := time.NewTicker(check)
+	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			return
-		default:
-			if err := wsc.Connect(ctx); err != nil {
-				wsc.updateStatus(fmt.Sprintf("Connection failed: %v. Retrying...", err), ERR)
-				time.Sleep(backoff)
-				backoff *= 2
-				if backoff > maxBackoff {
-					backoff = maxBackoff
-				}
+			return ctx.Err()
+		case err := <-broken:
+			return err
+		case <-ticker.C:
+			if rc.StaleAfter <= 0 {
 				continue
 			}
-
-			if err := wsc.Subscribe(ctx); err != nil {
-				wsc.updateStatus(fmt.Sprintf("Subscription failed: %v. Retrying...", err), ERR)
-				time.Sleep(backoff)
-				backoff *= 2
-				if backoff > maxBackoff {
-					backoff = maxBackoff
+			idle := time.Since(wsc.LastMessage())
+			switch {
+			case idle > rc.StaleAfter:
+				return fmt.Errorf("no messages for %s", idle.Round(time.Second))
+			case idle > rc.DegradedAfter:
+				wsc.degrade(fmt.Sprintf("no messages since %s", wsc.LastMessage().Format("15:04:05")))
+			default:
+				if quiet := wsc.quietStream(rc.DegradedAfter); quiet != "" {
+					wsc.degrade(quiet)
+				} else {
+					wsc.setState(ConnectionLive)
 				}
-				continue
 			}
+		}
+	}
+}
 
-			wsc.updateStatus("Successfully reconnected and subscribed.", INFO)
-			backoff = 1 * time.Second // Reset backoff after a successful connection
-			return
+// quietStream devuelve por qué un stream parece sordo: lleva mucho más que su ritmo habitual
+// (y más que minQuiet) sin mensajes. "" si todos están bien.
+func (wsc *WebSocketClient) quietStream(minQuiet time.Duration) string {
+	wsc.mu.Lock()
+	defer wsc.mu.Unlock()
+	now := time.Now()
+	for _, name := range wsc.order {
+		a := wsc.activity[name]
+		if a == nil {
+			continue
+		}
+		if last, quiet := a.quiet(now, minQuiet); quiet {
+			return fmt.Sprintf("no messages on %s since %s", name, last.Format("15:04:05"))
 		}
 	}
+	return ""
+}
+
+func (wsc *WebSocketClient) setState(state ConnectionState) {
+	wsc.mu.Lock()
+	wsc.state = state
+	wsc.mu.Unlock()
+}
+
+// degrade marca la conexión como degradada y avisa el motivo al entrar en ese estado.
+func (wsc *WebSocketClient) degrade(reason string) {
+	wsc.mu.Lock()
+	prev := wsc.state
+	wsc.state = ConnectionDegraded
+	wsc.mu.Unlock()
+
+	if prev != ConnectionDegraded {
+		wsc.updateStatus("WebSocket degraded: "+reason, WARN)
+	}
+}
+
+// State devuelve el estado de la conexión.
+func (wsc *WebSocketClient) State() ConnectionState {
+	wsc.mu.Lock()
+	defer wsc.mu.Unlock()
+	return wsc.state
+}
+
+// LastMessage es cuándo llegó el último mensaje de cualquier suscripción.
+func (wsc *WebSocketClient) LastMessage() time.Time {
+	return time.Unix(0, wsc.lastMessage.Load())
+}
+
+// Slot es el último slot recibido por el heartbeat (0 si no hay heartbeat).
+func (wsc *WebSocketClient) Slot() uint64 {
+	return wsc.lastSlot.Load()
+}
+
+// Reconnects es la cantidad de veces que se reconectó por una caída.
+func (wsc *WebSocketClient) Reconnects() int64 {
+	return wsc.reconnects.Load()
 }
 
 func (wsc *WebSocketClient) updateStatus(message string, level LogLevel) {
 	wsc.statusUpdates <- StatusMessage{Level: level, Message: message}
 }
+
+// Un stream se considera sordo cuando lleva streamQuietFactor veces su intervalo promedio sin
+// mensajes; el promedio se aprende después de streamMinSamples mensajes.
+const (
+	streamQuietFactor = 10
+	streamMinSamples  = 20
+)
+
+// streamActivity aprende el ritmo de un stream (promedio móvil del intervalo entre mensajes)
+// para detectar cuando deja de recibir aunque la conexión siga viva. Sobrevive a las
+// reconexiones.
+type streamActivity struct {
+	mu      sync.Mutex
+	last    time.Time
+	avgGap  time.Duration
+	samples int
+	// fresh indica que el próximo mensaje es el primero de una conexión nueva: el intervalo
+	// hasta él incluye la caída y no cuenta.
+	fresh bool
+}
+
+func newStreamActivity(now time.Time) *streamActivity {
+	return &streamActivity{last: now, fresh: true}
+}
+
+func (a *streamActivity) observe(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.fresh {
+		gap := now.Sub(a.last)
+		if a.samples == 0 {
+			a.avgGap = gap
+		} else {
+			a.avgGap += (gap - a.avgGap) / 5
+		}
+		a.samples++
+	}
+	a.last, a.fresh = now, false
+}
+
+func (a *streamActivity) reset(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.last, a.fresh = now, true
+}
+
+// quiet indica si el stream lleva mucho más que su ritmo habitual y que minQuiet sin mensajes.
+func (a *streamActivity) quiet(now time.Time, minQuiet time.Duration) (time.Time, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.samples < streamMinSamples {
+		return a.last, false
+	}
+	idle := now.Sub(a.last)
+	return a.last, idle > minQuiet && idle > streamQuietFactor*a.avgGap
+}
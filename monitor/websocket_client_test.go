@@ -2,62 +2,416 @@ package monitor_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"gosol/monitor"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"gosol/config"
+	"gosol/monitor"
+
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc/ws"
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestWebSocketMessageFlow(t *testing.T) {
-	// Crear un canal para los logs
-	logCh := make(chan *ws.LogResult, 1)
-	statusCh := make(chan monitor.StatusMessage, 1)
+// fakeWS es un nodo de Solana mínimo: acepta suscripciones, les asigna un id y permite
+// mandar notificaciones y cortar la conexión.
+type fakeWS struct {
+	t        *testing.T
+	srv      *httptest.Server
+	mu       sync.Mutex
+	conn     *websocket.Conn
+	nextSub  uint64
+	requests chan wsRequest
+}
+
+type wsRequest struct {
+	Method string
+	Params []json.RawMessage
+	SubID  uint64
+}
+
+func newFakeWS(t *testing.T) *fakeWS {
+	f := &fakeWS{t: t, requests: make(chan wsRequest, 100)}
+	upgrader := websocket.Upgrader{}
+	f.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.conn = conn
+		f.mu.Unlock()
+
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var req struct {
+				ID     json.RawMessage   `json:"id"`
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			if json.Unmarshal(data, &req) != nil {
+				continue
+			}
+			got := wsRequest{Method: req.Method, Params: req.Params}
+			f.mu.Lock()
+			f.nextSub++
+			got.SubID = f.nextSub
+			conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "result": got.SubID, "id": req.ID})
+			f.mu.Unlock()
+			f.requests <- got
+		}
+	}))
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+func (f *fakeWS) url() string {
+	return "ws" + strings.TrimPrefix(f.srv.URL, "http") + "/?api-key="
+}
+
+// expect espera el próximo pedido, que tiene que ser method.
+func (f *fakeWS) expect(method string) wsRequest {
+	f.t.Helper()
+	select {
+	case req := <-f.requests:
+		require.Equal(f.t, method, req.Method)
+		return req
+	case <-time.After(5 * time.Second):
+		f.t.Fatalf("timeout waiting for %s", method)
+		return wsRequest{}
+	}
+}
 
-	done := make(chan struct{})
+func (f *fakeWS) notify(method string, subID uint64, result string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	msg := `{"jsonrpc":"2.0","method":"` + method + `","params":{"result":` + result + `,"subscription":` + jsonNumber(subID) + `}}`
+	require.NoError(f.t, f.conn.WriteMessage(websocket.TextMessage, []byte(msg)))
+}
+
+func (f *fakeWS) drop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.conn.Close()
+}
+
+func jsonNumber(n uint64) string {
+	b, _ := json.Marshal(n)
+	return string(b)
+}
 
-	// Crear una instancia de App con el canal de logs
+func logsNotification(sig solana.Signature) string {
+	return `{"context":{"slot":5},"value":{"signature":"` + sig.String() + `","err":null,"logs":["Program log: hi"]}}`
+}
+
+func drainStatus(ctx context.Context) chan monitor.StatusMessage {
+	ch := make(chan monitor.StatusMessage, 100)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+			}
+		}
+	}()
+	return ch
+}
+
+func newTestWebSocketClient(t *testing.T, ctx context.Context, f *fakeWS, logCh chan *ws.LogResult, reconnect config.ReconnectConfig) *monitor.WebSocketClient {
+	cfg := config.SolanaConfig{
+		WebsocketURL: f.url(),
+		RayFeePubkey: solana.NewWallet().PublicKey().String(),
+		Commitment:   config.CommitmentConfirmed,
+		Reconnect:    reconnect,
+	}
+	wsc := monitor.NewWebSocketClient(cfg, drainStatus(ctx))
+	require.NoError(t, wsc.Add(monitor.LogsSubscription("raydium", solana.MustPublicKeyFromBase58(cfg.RayFeePubkey), logCh)))
+	return wsc
+}
+
+func TestWebSocketMessageFlow(t *testing.T) {
+	// Crear una instancia de App con la cola de logs
 	app := &monitor.App{
-		LogCh:         logCh,
-		StatusUpdates: statusCh,
+		Ingest:        monitor.NewIngestQueue("logs", config.Default().Ingest),
+		StatusUpdates: make(chan monitor.StatusMessage, 1),
 		Ctx:           context.Background(),
 	}
 
 	// Simular un mensaje de log
 	expectedSignature := solana.MustSignatureFromBase58("g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z5g5Z") // Ensure this is a valid 64-byte signature
-	logMsg := &ws.LogResult{
-		Value: struct {
-			Signature solana.Signature `json:"signature"`
-			Err       interface{}      `json:"err"`
-			Logs      []string         `json:"logs"`
-		}{
-			Signature: solana.Signature(expectedSignature),
-			Err:       nil,
-			Logs:      nil,
-		},
-	}
-
-	// Iniciar una goroutine para simular el procesamiento de logs en app
-	go func() {
-		select {
-		case logMsg := <-app.LogCh:
-			// Aquí puedes verificar que el mensaje se procesa correctamente
-			fmt.Printf("Received log message: %v\n", logMsg)
-			assert.Equal(t, expectedSignature, logMsg.Value.Signature)
-			close(done)
-		case <-time.After(1 * time.Second):
-			t.Fatal("No se recibió el mensaje de log a tiempo")
-		}
-	}()
+	logMsg := &ws.LogResult{}
+	logMsg.Value.Signature = expectedSignature
+
+	// Enviar el mensaje simulado a la cola de logs
+	require.True(t, app.Ingest.Push(logMsg))
+
+	ctx, cancel := context.WithTimeout(app.Ctx, 1*time.Second)
+	defer cancel()
+	received, ok := app.Ingest.Pop(ctx)
+	if !ok {
+		t.Fatal("No se recibió el mensaje de log a tiempo")
+	}
+	assert.Equal(t, expectedSignature, received.Value.Signature)
+}
+
+func TestWebSocketClientRoutesSubscriptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := newFakeWS(t)
+	logCh := make(chan *ws.LogResult, 10)
+	wsc := newTestWebSocketClient(t, ctx, f, logCh, config.ReconnectConfig{})
+
+	accounts := make(chan *ws.AccountResult, 1)
+	watched := solana.NewWallet().PublicKey()
+	require.NoError(t, wsc.Add(monitor.Subscription{
+		Name:      "vault",
+		Kind:      monitor.SubscriptionAccount,
+		Account:   watched,
+		OnAccount: func(msg *ws.AccountResult) { accounts <- msg },
+	}))
+	assert.Error(t, wsc.Add(monitor.Subscription{Name: "vault", Kind: monitor.SubscriptionAccount, OnAccount: func(*ws.AccountResult) {}}))
+	assert.ErrorContains(t, wsc.Add(monitor.Subscription{Name: "x", Kind: monitor.SubscriptionProgram}), "missing program handler")
+
+	require.NoError(t, wsc.Connect(ctx))
+	require.NoError(t, wsc.Subscribe(ctx))
 
-	// Enviar el mensaje simulado al canal de logs
-	logCh <- logMsg
+	logsReq := f.expect("logsSubscribe")
+	assert.Contains(t, string(logsReq.Params[1]), `"confirmed"`)
+	accountReq := f.expect("accountSubscribe")
+	assert.Contains(t, string(accountReq.Params[0]), watched.String())
 
-	<-done
+	sig := solana.Signature{1, 2, 3}
+	f.notify("logsNotification", logsReq.SubID, logsNotification(sig))
+	select {
+	case msg := <-logCh:
+		assert.Equal(t, sig, msg.Value.Signature)
+	case <-time.After(5 * time.Second):
+		t.Fatal("log notification not routed")
+	}
+
+	f.notify("accountNotification", accountReq.SubID, `{"context":{"slot":6},"value":{"lamports":42,"owner":"11111111111111111111111111111111","data":["","base64"],"executable":false,"rentEpoch":0}}`)
+	select {
+	case msg := <-accounts:
+		assert.Equal(t, uint64(42), msg.Value.Lamports)
+	case <-time.After(5 * time.Second):
+		t.Fatal("account notification not routed")
+	}
+
+	// las suscripciones agregadas con la conexión abierta se activan enseguida, y las de
+	// firma se quitan solas después de notificar
+	confirmed := make(chan *ws.SignatureResult, 1)
+	require.NoError(t, wsc.Add(monitor.Subscription{
+		Name:        "confirm",
+		Kind:        monitor.SubscriptionSignature,
+		Signature:   sig,
+		Commitment:  "finalized",
+		OnSignature: func(msg *ws.SignatureResult) { confirmed <- msg },
+	}))
+	sigReq := f.expect("signatureSubscribe")
+	assert.Contains(t, string(sigReq.Params[1]), `"finalized"`)
+	f.notify("signatureNotification", sigReq.SubID, `{"context":{"slot":7},"value":{"err":null}}`)
+	select {
+	case msg := <-confirmed:
+		assert.Equal(t, uint64(7), msg.Context.Slot)
+	case <-time.After(5 * time.Second):
+		t.Fatal("signature notification not routed")
+	}
+	f.expect("signatureUnsubscribe")
+	assert.Equal(t, []string{"raydium", "vault"}, wsc.Subscriptions())
+
+	assert.True(t, wsc.Remove("vault"))
+	f.expect("accountUnsubscribe")
+	assert.False(t, wsc.Remove("vault"))
+}
+
+func TestWebSocketClientResubscribesAfterReconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := newFakeWS(t)
+	logCh := make(chan *ws.LogResult, 10)
+	wsc := newTestWebSocketClient(t, ctx, f, logCh, config.ReconnectConfig{})
+
+	wsc.Reconnect(ctx)
+	f.expect("logsSubscribe")
+
+	pumpCh := make(chan *ws.LogResult, 10)
+	pump := solana.NewWallet().PublicKey()
+	require.NoError(t, wsc.AddLogSubscription("pump.fun", pump, pumpCh))
+	f.expect("logsSubscribe")
+
+	f.drop()
+	wsc.Reconnect(ctx)
+	f.expect("logsSubscribe")
+	pumpReq := f.expect("logsSubscribe")
+	assert.Contains(t, string(pumpReq.Params[0]), pump.String())
 
-	// Esperar un momento para que el mensaje sea procesado
+	sig := solana.Signature{9}
+	f.notify("logsNotification", pumpReq.SubID, logsNotification(sig))
+	select {
+	case msg := <-pumpCh:
+		assert.Equal(t, sig, msg.Value.Signature)
+	case <-time.After(5 * time.Second):
+		t.Fatal("log notification not routed after reconnect")
+	}
+}
+
+func TestWebSocketClientRunReconnects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := newFakeWS(t)
+	wsc := newTestWebSocketClient(t, ctx, f, make(chan *ws.LogResult, 10), config.ReconnectConfig{
+		BackoffBase:   10 * time.Millisecond,
+		BackoffMax:    50 * time.Millisecond,
+		DegradedAfter: 150 * time.Millisecond,
+		StaleAfter:    600 * time.Millisecond,
+	})
+	go wsc.Run(ctx)
+
+	slotReq := f.expect("slotSubscribe")
+	f.expect("logsSubscribe")
+	f.notify("slotNotification", slotReq.SubID, `{"parent":99,"root":68,"slot":100}`)
+	assert.Eventually(t, func() bool { return wsc.Slot() == 100 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, monitor.ConnectionLive, wsc.State())
+
+	// el stream se corta a mitad de sesión: se reconecta y se vuelve a suscribir todo
+	f.drop()
+	f.expect("slotSubscribe")
+	f.expect("logsSubscribe")
+	assert.Equal(t, int64(1), wsc.Reconnects())
+
+	// la conexión sigue abierta pero no llega nada: primero degradada, después se reconecta
+	assert.Eventually(t, func() bool { return wsc.State() == monitor.ConnectionDegraded }, 5*time.Second, 10*time.Millisecond)
+	f.expect("slotSubscribe")
+	f.expect("logsSubscribe")
+	assert.Equal(t, int64(2), wsc.Reconnects())
+
+	cancel()
+	assert.Eventually(t, func() bool { return wsc.State() == monitor.ConnectionDown }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWebSocketClientDegradesWhenALogStreamGoesQuiet(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := newFakeWS(t)
+	logCh := make(chan *ws.LogResult, 100)
+	wsc := newTestWebSocketClient(t, ctx, f, logCh, config.ReconnectConfig{
+		BackoffBase:   10 * time.Millisecond,
+		BackoffMax:    50 * time.Millisecond,
+		DegradedAfter: 100 * time.Millisecond,
+		StaleAfter:    5 * time.Second,
+	})
+	go wsc.Run(ctx)
+
+	slotReq := f.expect("slotSubscribe")
+	logsReq := f.expect("logsSubscribe")
+	for i := range 30 {
+		f.notify("slotNotification", slotReq.SubID, `{"parent":99,"root":68,"slot":`+strconv.Itoa(100+i)+`}`)
+		f.notify("logsNotification", logsReq.SubID, logsNotification(solana.Signature{}))
+		<-logCh
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, monitor.ConnectionLive, wsc.State())
+
+	// los logs dejan de llegar pero el heartbeat sigue: la conexión no está vencida, el stream sí
+	degraded := false
+	for i := 0; i < 100 && !degraded; i++ {
+		f.notify("slotNotification", slotReq.SubID, `{"parent":99,"root":68,"slot":`+strconv.Itoa(200+i)+`}`)
+		time.Sleep(10 * time.Millisecond)
+		degraded = wsc.State() == monitor.ConnectionDegraded
+	}
+	assert.True(t, degraded, "a quiet log stream must degrade the connection")
+	assert.Zero(t, wsc.Reconnects())
+
+	// vuelven los logs: la conexión vuelve a estar viva
+	f.notify("logsNotification", logsReq.SubID, logsNotification(solana.Signature{}))
+	<-logCh
+	assert.Eventually(t, func() bool { return wsc.State() == monitor.ConnectionLive }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWebSocketClientKeepsTheConnectionWhenASignatureStreamFails(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := newFakeWS(t)
+	logCh := make(chan *ws.LogResult, 10)
+	wsc := newTestWebSocketClient(t, ctx, f, logCh, config.ReconnectConfig{BackoffBase: 10 * time.Millisecond})
+	go wsc.Run(ctx)
+	logsReq := f.expect("logsSubscribe")
+
+	require.NoError(t, wsc.Add(monitor.Subscription{
+		Name:        "commitment/finalized/x",
+		Kind:        monitor.SubscriptionSignature,
+		Signature:   solana.Signature{7},
+		OnSignature: func(*ws.SignatureResult) {},
+	}))
+	sigReq := f.expect("signatureSubscribe")
+
+	// una notificación que no se puede decodificar corta solo ese stream
+	f.notify("signatureNotification", sigReq.SubID, `"garbage"`)
+	time.Sleep(200 * time.Millisecond)
+	assert.Zero(t, wsc.Reconnects())
+	for len(f.requests) > 0 {
+		assert.NotEqual(t, "logsSubscribe", (<-f.requests).Method, "the logs stream was resubscribed")
+	}
+
+	sig := solana.Signature{1}
+	f.notify("logsNotification", logsReq.SubID, logsNotification(sig))
+	select {
+	case msg := <-logCh:
+		assert.Equal(t, sig, msg.Value.Signature)
+	case <-time.After(5 * time.Second):
+		t.Fatal("logs stream stopped after a signature stream failed")
+	}
+}
+
+func TestWebSocketClientSubscribesWithoutHoldingTheLock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := newFakeWS(t)
+	// nadie lee los avisos, como cuando el loop de la UI está ocupado
+	status := make(chan monitor.StatusMessage)
+	wsc := monitor.NewWebSocketClient(config.SolanaConfig{WebsocketURL: f.url(), Commitment: config.CommitmentConfirmed}, status)
+	require.NoError(t, wsc.Connect(ctx))
+	require.NoError(t, wsc.Subscribe(ctx))
+	f.drop()
 	time.Sleep(100 * time.Millisecond)
+
+	// suscribir sobre la conexión caída falla y el aviso del error queda bloqueado
+	for i := 0; i < 5; i++ {
+		go wsc.Add(monitor.LogsSubscription(fmt.Sprintf("late-%d", i), solana.NewWallet().PublicKey(), make(chan *ws.LogResult, 1)))
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// la UI lee el estado desde el mismo loop que vacía los avisos: no puede quedar trabada
+	state := make(chan monitor.ConnectionState, 1)
+	go func() { state <- wsc.State() }()
+	select {
+	case <-state:
+	case <-time.After(time.Second):
+		t.Fatal("State blocked behind a subscription")
+	}
+	assert.Len(t, wsc.Subscriptions(), 5)
+
+	// liberar los avisos pendientes
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-status:
+			}
+		}
+	}()
 }
@@ -0,0 +1,212 @@
+package rpcpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gosol/config"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+// Pool reparte las llamadas RPC entre varios endpoints usando round-robin ponderado
+// y pasa al siguiente endpoint cuando uno responde 429, 5xx o falla la conexión.
+// Implementa rpc.JSONRPCClient, así que se usa con rpc.NewWithCustomRPCClient.
+type Pool struct {
+	mu        sync.Mutex
+	endpoints []*endpoint
+	cooldown  time.Duration
+	// OnFailover se llama cada vez que un endpoint falla y se prueba con otro.
+	OnFailover func(endpoint string, err error)
+}
+
+type endpoint struct {
+	name          string
+	client        jsonrpc.RPCClient
+	weight        int
+	current       int
+	cooldownUntil time.Time
+}
+
+var _ rpc.JSONRPCClient = (*Pool)(nil)
+
+// New arma un pool a partir de la configuración de endpoints (ver config.RPCConfig).
+func New(cfg config.RPCConfig) (*Pool, error) {
+	if len(cfg.Endpoints) == 0 {
+		return nil, errors.New("rpcpool: no endpoints configured")
+	}
+
+	pool := &Pool{cooldown: cfg.Cooldown}
+	for _, epCfg := range cfg.Endpoints {
+		endpointURL, headers, err := authenticate(epCfg)
+		if err != nil {
+			return nil, fmt.Errorf("rpcpool: endpoint %s: %w", epCfg.Name, err)
+		}
+		client := jsonrpc.NewClientWithOpts(endpointURL, &jsonrpc.RPCClientOpts{
+			HTTPClient:    &http.Client{Timeout: epCfg.Timeout},
+			CustomHeaders: headers,
+		})
+		pool.endpoints = append(pool.endpoints, &endpoint{
+			name:   epCfg.Name,
+			client: client,
+			weight: max(epCfg.Weight, 1),
+		})
+	}
+	return pool, nil
+}
+
+// NewClient es un atajo para obtener un *rpc.Client respaldado por el pool.
+func NewClient(cfg config.RPCConfig) (*rpc.Client, *Pool, error) {
+	pool, err := New(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rpc.NewWithCustomRPCClient(pool), pool, nil
+}
+
+// authenticate aplica el estilo de autenticación del endpoint a la URL o a los headers.
+func authenticate(cfg config.RPCEndpointConfig) (string, map[string]string, error) {
+	headers := make(map[string]string, len(cfg.Headers)+1)
+	for k, v := range cfg.Headers {
+		headers[k] = v
+	}
+
+	switch cfg.Auth {
+	case config.AuthQuery:
+		u, err := url.Parse(cfg.URL)
+		if err != nil {
+			return "", nil, err
+		}
+		param := cfg.AuthParam
+		if param == "" {
+			param = "api-key"
+		}
+		q := u.Query()
+		q.Set(param, cfg.APIKey)
+		u.RawQuery = q.Encode()
+		return u.String(), headers, nil
+	case config.AuthHeader:
+		if cfg.AuthParam == "" {
+			headers["Authorization"] = "Bearer " + cfg.APIKey
+		} else {
+			headers[cfg.AuthParam] = cfg.APIKey
+		}
+	}
+	return cfg.URL, headers, nil
+}
+
+// next elige el próximo endpoint con smooth weighted round-robin, salteando los que ya
+// se probaron en esta llamada y los que están en cooldown (salvo que no quede otro).
+func (p *Pool) next(tried map[*endpoint]bool) *endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	pick := func(allowCooldown bool) *endpoint {
+		var best *endpoint
+		total := 0
+		for _, ep := range p.endpoints {
+			if tried[ep] || (!allowCooldown && now.Before(ep.cooldownUntil)) {
+				continue
+			}
+			ep.current += ep.weight
+			total += ep.weight
+			if best == nil || ep.current > best.current {
+				best = ep
+			}
+		}
+		if best != nil {
+			best.current -= total
+		}
+		return best
+	}
+
+	if ep := pick(false); ep != nil {
+		return ep
+	}
+	return pick(true)
+}
+
+func (p *Pool) markFailed(ep *endpoint, err error) {
+	p.mu.Lock()
+	ep.cooldownUntil = time.Now().Add(p.cooldown)
+	p.mu.Unlock()
+
+	if p.OnFailover != nil {
+		p.OnFailover(ep.name, err)
+	}
+}
+
+// do ejecuta call contra los endpoints hasta que uno responda o el error no amerite reintentar.
+func (p *Pool) do(ctx context.Context, call func(jsonrpc.RPCClient) error) error {
+	tried := make(map[*endpoint]bool, len(p.endpoints))
+	var lastErr error
+	for ep := p.next(tried); ep != nil; ep = p.next(tried) {
+		tried[ep] = true
+		err := call(ep.client)
+		if err == nil || !shouldFailover(ctx, err) {
+			return err
+		}
+		lastErr = err
+		p.markFailed(ep, err)
+	}
+	return fmt.Errorf("rpcpool: all endpoints failed: %w", lastErr)
+}
+
+// shouldFailover indica si el error es del endpoint (rate limit, caída, red) y no de la consulta.
+func shouldFailover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var httpErr *jsonrpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code == http.StatusTooManyRequests || httpErr.Code >= 500
+	}
+
+	var rpcErr *jsonrpc.RPCError
+	if errors.As(err, &rpcErr) {
+		// algunos proveedores devuelven el rate limit como error JSON-RPC, y un 5xx con cuerpo
+		// JSON-RPC llega como RPCError con un código de error de servidor (-32000…-32099)
+		return rpcErr.Code == http.StatusTooManyRequests || rpcErr.Code == -32429 ||
+			(rpcErr.Code <= -32000 && rpcErr.Code >= -32099) ||
+			strings.Contains(strings.ToLower(rpcErr.Message), "too many requests")
+	}
+
+	// errores de red y timeouts del http.Client (Do los devuelve como *url.Error) o un
+	// cuerpo cortado a la mitad; un JSON que no se puede decodificar es de la consulta
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func (p *Pool) CallForInto(ctx context.Context, out interface{}, method string, params []interface{}) error {
+	return p.do(ctx, func(c jsonrpc.RPCClient) error {
+		return c.CallForInto(ctx, out, method, params)
+	})
+}
+
+func (p *Pool) CallWithCallback(ctx context.Context, method string, params []interface{}, callback func(*http.Request, *http.Response) error) error {
+	return p.do(ctx, func(c jsonrpc.RPCClient) error {
+		return c.CallWithCallback(ctx, method, params, callback)
+	})
+}
+
+func (p *Pool) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	var responses jsonrpc.RPCResponses
+	err := p.do(ctx, func(c jsonrpc.RPCClient) error {
+		var err error
+		responses, err = c.CallBatch(ctx, requests)
+		return err
+	})
+	return responses, err
+}
@@ -0,0 +1,186 @@
+package rpcpool_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gosol/config"
+	"gosol/rpcpool"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slotServer responde getSlot con el slot indicado y cuenta las llamadas recibidas.
+func slotServer(t *testing.T, status int, calls *int32, check func(*http.Request)) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		if check != nil {
+			check(r)
+		}
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":42}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPoolFailsOverOnRateLimit(t *testing.T) {
+	var limitedCalls, okCalls int32
+	limited := slotServer(t, http.StatusTooManyRequests, &limitedCalls, nil)
+	ok := slotServer(t, http.StatusOK, &okCalls, func(r *http.Request) {
+		assert.Equal(t, "secret", r.URL.Query().Get("api-key"))
+		assert.Equal(t, "bar", r.Header.Get("X-Foo"))
+	})
+
+	client, pool, err := rpcpool.NewClient(config.RPCConfig{
+		Cooldown: time.Minute,
+		Endpoints: []config.RPCEndpointConfig{
+			{Name: "limited", URL: limited.URL, Weight: 10, Auth: config.AuthNone},
+			{Name: "ok", URL: ok.URL, Weight: 1, Auth: config.AuthQuery, APIKey: "secret", Headers: map[string]string{"X-Foo": "bar"}},
+		},
+	})
+	require.NoError(t, err)
+
+	var failovers []string
+	pool.OnFailover = func(endpoint string, err error) { failovers = append(failovers, endpoint) }
+
+	for i := 0; i < 3; i++ {
+		slot, err := client.GetSlot(context.Background(), "")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(42), slot)
+	}
+
+	// el endpoint limitado queda en cooldown después del primer 429
+	assert.Equal(t, int32(1), atomic.LoadInt32(&limitedCalls))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&okCalls))
+	assert.Equal(t, []string{"limited"}, failovers)
+}
+
+func TestPoolWeightedRoundRobin(t *testing.T) {
+	var heavyCalls, lightCalls int32
+	heavy := slotServer(t, http.StatusOK, &heavyCalls, nil)
+	light := slotServer(t, http.StatusOK, &lightCalls, func(r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+	})
+
+	client, _, err := rpcpool.NewClient(config.RPCConfig{
+		Endpoints: []config.RPCEndpointConfig{
+			{Name: "heavy", URL: heavy.URL, Weight: 3, Auth: config.AuthNone},
+			{Name: "light", URL: light.URL, Weight: 1, Auth: config.AuthHeader, APIKey: "secret"},
+		},
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 8; i++ {
+		_, err := client.GetSlot(context.Background(), "")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(6), atomic.LoadInt32(&heavyCalls))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&lightCalls))
+}
+
+func TestPoolDoesNotFailOverOnQueryErrors(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"Invalid params"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, _, err := rpcpool.NewClient(config.RPCConfig{
+		Endpoints: []config.RPCEndpointConfig{
+			{Name: "a", URL: srv.URL, Weight: 1},
+			{Name: "b", URL: srv.URL, Weight: 1},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = client.GetSlot(context.Background(), "")
+	assert.ErrorContains(t, err, "Invalid params")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestPoolFailsOverOnServerErrorBodies(t *testing.T) {
+	var behindCalls, okCalls int32
+	behind := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&behindCalls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"Node is behind"}}`))
+	}))
+	t.Cleanup(behind.Close)
+	ok := slotServer(t, http.StatusOK, &okCalls, nil)
+
+	client, pool, err := rpcpool.NewClient(config.RPCConfig{
+		Cooldown: time.Minute,
+		Endpoints: []config.RPCEndpointConfig{
+			{Name: "behind", URL: behind.URL, Weight: 10},
+			{Name: "ok", URL: ok.URL, Weight: 1},
+		},
+	})
+	require.NoError(t, err)
+
+	var failovers []string
+	pool.OnFailover = func(endpoint string, err error) { failovers = append(failovers, endpoint) }
+
+	slot, err := client.GetSlot(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), slot)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&behindCalls))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&okCalls))
+	assert.Equal(t, []string{"behind"}, failovers)
+}
+
+func TestPoolDoesNotFailOverOnDecodeErrors(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"not a slot"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, pool, err := rpcpool.NewClient(config.RPCConfig{
+		Endpoints: []config.RPCEndpointConfig{
+			{Name: "a", URL: srv.URL, Weight: 1},
+			{Name: "b", URL: srv.URL, Weight: 1},
+		},
+	})
+	require.NoError(t, err)
+	pool.OnFailover = func(endpoint string, err error) { t.Errorf("unexpected failover from %s: %v", endpoint, err) }
+
+	_, err = client.GetSlot(context.Background(), "")
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestPoolFailsOverOnNetworkErrors(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	var okCalls int32
+	ok := slotServer(t, http.StatusOK, &okCalls, nil)
+
+	client, pool, err := rpcpool.NewClient(config.RPCConfig{
+		Cooldown: time.Minute,
+		Endpoints: []config.RPCEndpointConfig{
+			{Name: "down", URL: down.URL, Weight: 10},
+			{Name: "ok", URL: ok.URL, Weight: 1},
+		},
+	})
+	require.NoError(t, err)
+	var failovers []string
+	pool.OnFailover = func(endpoint string, err error) { failovers = append(failovers, endpoint) }
+
+	slot, err := client.GetSlot(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), slot)
+	assert.Equal(t, []string{"down"}, failovers)
+}
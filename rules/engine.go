@@ -0,0 +1,141 @@
+// Package rules evalúa los reportes de riesgo con las reglas de config.ScoringConfig.
+// El mismo veredicto decide qué tokens se descartan y cómo se muestran en la UI.
+package rules
+
+import (
+	"gosol/config"
+	"gosol/types"
+	"math"
+	"strings"
+)
+
+// Result es la evaluación de un reporte.
+type Result struct {
+	Score   int
+	Verdict string
+	Color   string
+	// Matched son los nombres de las reglas que aplicaron, en orden.
+	Matched []string
+}
+
+type Engine struct {
+	cfg config.ScoringConfig
+}
+
+func NewEngine(cfg config.ScoringConfig) *Engine {
+	return &Engine{cfg: cfg}
+}
+
+// Evaluate calcula el score ponderado del reporte, el veredicto y el color. mentions son las
+// fuentes que nombraron el mint (ver RuleMatch.SourcesAbove).
+func (e *Engine) Evaluate(report types.Report, mentions ...types.Mention) Result {
+	score := int(math.Round(e.cfg.ReportWeight * float64(report.Score)))
+
+	var result Result
+	var ruleVerdict string
+	for _, rule := range e.cfg.Rules {
+		if !matches(rule.When, report, mentions) {
+			continue
+		}
+		score += rule.Score
+		result.Matched = append(result.Matched, rule.Name)
+		if ruleVerdict == "" {
+			ruleVerdict = rule.Verdict
+		}
+	}
+
+	level := e.Level(score)
+	result.Score = score
+	result.Verdict = level.Verdict
+	result.Color = level.Color
+	if ruleVerdict != "" {
+		result.Verdict = ruleVerdict
+	}
+	return result
+}
+
+// Level devuelve el nivel que corresponde a un score. Por encima del último tope se usa el último nivel.
+func (e *Engine) Level(score int) config.ScoringLevel {
+	for i, level := range e.cfg.Levels {
+		if score <= level.MaxScore || (level.MaxScore == 0 && i == len(e.cfg.Levels)-1) {
+			return level
+		}
+	}
+	if n := len(e.cfg.Levels); n > 0 {
+		return e.cfg.Levels[n-1]
+	}
+	return config.ScoringLevel{Verdict: config.VerdictWatch}
+}
+
+// matches indica si el reporte cumple todas las condiciones definidas.
+func matches(m config.RuleMatch, report types.Report, mentions []types.Mention) bool {
+	if len(m.Risks) > 0 && !hasAnyRisk(report, m.Risks) {
+		return false
+	}
+	if m.RiskLevel != "" && !hasRiskLevel(report, m.RiskLevel) {
+		return false
+	}
+	if m.Rugged != nil && report.Rugged != *m.Rugged {
+		return false
+	}
+	if m.FreezeAuthority != nil && (report.FreezeAuthority != "") != *m.FreezeAuthority {
+		return false
+	}
+	if m.MintAuthority != nil && (report.MintAuthority != "") != *m.MintAuthority {
+		return false
+	}
+	if m.LiquidityBelow != nil && report.TotalMarketLiquidity >= *m.LiquidityBelow {
+		return false
+	}
+
+	var top, top10 float64
+	var insiders bool
+	for i, h := range report.TopHolders {
+		top = max(top, h.Pct)
+		if i < 10 {
+			top10 += h.Pct
+		}
+		insiders = insiders || h.Insider
+	}
+	if m.TopHolderPctAbove != nil && top <= *m.TopHolderPctAbove {
+		return false
+	}
+	if m.Top10PctAbove != nil && top10 <= *m.Top10PctAbove {
+		return false
+	}
+	if m.Insiders != nil && insiders != *m.Insiders {
+		return false
+	}
+
+	var weight float64
+	for _, mention := range mentions {
+		weight += mention.Weight
+	}
+	if m.SourcesAbove != nil && len(mentions) <= *m.SourcesAbove {
+		return false
+	}
+	if m.SourceWeightAbove != nil && weight <= *m.SourceWeightAbove {
+		return false
+	}
+	return true
+}
+
+func hasAnyRisk(report types.Report, names []string) bool {
+	for _, risk := range report.Risks {
+		for _, name := range names {
+			if strings.EqualFold(risk.Name, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasRiskLevel(report types.Report, level string) bool {
+	for _, risk := range report.Risks {
+		if strings.EqualFold(risk.Level, level) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package rules_test
+
+import (
+	"testing"
+
+	"gosol/config"
+	"gosol/rules"
+	"gosol/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultLevelsKeepPreviousThresholds(t *testing.T) {
+	engine := rules.NewEngine(config.Default().Scoring)
+
+	cases := []struct {
+		score   int
+		verdict string
+		color   string
+	}{
+		{500, config.VerdictAlert, "🟢"},
+		{2000, config.VerdictAlert, "🟢"},
+		{2001, config.VerdictWatch, "🟡"},
+		{3500, config.VerdictWatch, "🟠"},
+		{8000, config.VerdictWatch, "🔴"},
+		{8001, config.VerdictIgnore, "💩"},
+	}
+	for _, c := range cases {
+		result := engine.Evaluate(types.Report{Score: c.score})
+		assert.Equal(t, c.verdict, result.Verdict, "score %d", c.score)
+		assert.Equal(t, c.color, result.Color, "score %d", c.score)
+	}
+}
+
+func TestRulesAddWeightedScores(t *testing.T) {
+	yes := true
+	lowLiquidity := 1000.0
+	concentrated := 30.0
+
+	cfg := config.Default().Scoring
+	cfg.ReportWeight = 0.5
+	cfg.Rules = []config.ScoringRule{
+		{Name: "freeze", When: config.RuleMatch{FreezeAuthority: &yes}, Score: 3000},
+		{Name: "thin pool", When: config.RuleMatch{LiquidityBelow: &lowLiquidity, Risks: []string{"low liquidity"}}, Score: 1500},
+		{Name: "whale", When: config.RuleMatch{TopHolderPctAbove: &concentrated}, Score: 1000},
+		{Name: "rugged", When: config.RuleMatch{Rugged: &yes}, Verdict: config.VerdictIgnore},
+	}
+	engine := rules.NewEngine(cfg)
+
+	report := types.Report{
+		Score:                2000,
+		FreezeAuthority:      "auth",
+		TotalMarketLiquidity: 500,
+		Risks:                []types.Risk{{Name: "Low Liquidity", Level: "danger"}},
+		TopHolders:           []types.Holder{{Pct: 12}},
+	}
+	result := engine.Evaluate(report)
+	assert.Equal(t, 1000+3000+1500, result.Score)
+	assert.Equal(t, []string{"freeze", "thin pool"}, result.Matched)
+	assert.Equal(t, config.VerdictWatch, result.Verdict)
+
+	// un veredicto de regla manda sobre el del nivel
+	report.FreezeAuthority = ""
+	report.Rugged = true
+	result = engine.Evaluate(report)
+	assert.Equal(t, 2500, result.Score)
+	assert.Equal(t, "🟡", result.Color)
+	assert.Equal(t, config.VerdictIgnore, result.Verdict)
+}
+
+func TestRulesUseCrossSourceConfirmation(t *testing.T) {
+	one, confirmed := 1, 1.5
+	cfg := config.Default().Scoring
+	cfg.Rules = []config.ScoringRule{
+		{Name: "two channels", When: config.RuleMatch{SourcesAbove: &one}, Score: -300},
+		{Name: "trusted", When: config.RuleMatch{SourceWeightAbove: &confirmed}, Score: -500},
+	}
+	engine := rules.NewEngine(cfg)
+	report := types.Report{Score: 2500}
+
+	result := engine.Evaluate(report, types.Mention{Source: "calls", Weight: 2})
+	assert.Equal(t, 2000, result.Score)
+	assert.Equal(t, []string{"trusted"}, result.Matched)
+
+	result = engine.Evaluate(report, types.Mention{Source: "calls", Weight: 0.5}, types.Mention{Source: "alerts", Weight: 0.5})
+	assert.Equal(t, 2200, result.Score)
+	assert.Equal(t, []string{"two channels"}, result.Matched)
+
+	assert.Empty(t, engine.Evaluate(report).Matched)
+}
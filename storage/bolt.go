@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	mintsBucket    = []byte("mints")
+	reportsBucket  = []byte("reports")
+	statusesBucket = []byte("statuses")
+)
+
+// compactTxMaxSize limita el tamaño de cada transacción durante la compactación.
+const compactTxMaxSize = 1 << 20
+
+// BoltStore persiste el estado en un archivo bbolt. Los reportes y estados se guardan
+// con clave mint + 0x00 + timestamp (big endian), así quedan ordenados por fecha.
+type BoltStore struct {
+	mu   sync.RWMutex
+	path string
+	db   *bolt.DB
+}
+
+var _ Store = (*BoltStore)(nil)
+
+func OpenBolt(path string) (*BoltStore, error) {
+	db, err := openBoltDB(path)
+	if err != nil {
+		return nil, err
+	}
+	return &BoltStore{path: path, db: db}, nil
+}
+
+func openBoltDB(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening store %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{mintsBucket, reportsBucket, statusesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initializing store %s: %w", path, err)
+	}
+	return db, nil
+}
+
+func timedKey(mint string, at time.Time) []byte {
+	key := make([]byte, len(mint)+1+8)
+	copy(key, mint)
+	binary.BigEndian.PutUint64(key[len(mint)+1:], uint64(at.UnixNano()))
+	return key
+}
+
+func keyTime(key []byte) time.Time {
+	if len(key) < 8 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key[len(key)-8:])))
+}
+
+func (s *BoltStore) put(bucket, key []byte, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put(key, data)
+	})
+}
+
+func (s *BoltStore) SaveMint(rec MintRecord) error {
+	return s.put(mintsBucket, []byte(rec.Mint), rec)
+}
+
+func (s *BoltStore) SaveReport(rec ReportRecord) error {
+	return s.put(reportsBucket, timedKey(rec.Mint, rec.RecordedAt), rec)
+}
+
+func (s *BoltStore) SaveStatus(rec StatusRecord) error {
+	return s.put(statusesBucket, timedKey(rec.Mint, rec.At), rec)
+}
+
+func (s *BoltStore) Load() (Snapshot, error) {
+	var snap Snapshot
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(mintsBucket).ForEach(func(_, v []byte) error {
+			var rec MintRecord
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return err
+			}
+			snap.Mints = append(snap.Mints, rec)
+			return nil
+		}); err != nil {
+			return fmt.Errorf("loading mints: %w", err)
+		}
+		if err := tx.Bucket(reportsBucket).ForEach(func(_, v []byte) error {
+			var rec ReportRecord
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return err
+			}
+			snap.Reports = append(snap.Reports, rec)
+			return nil
+		}); err != nil {
+			return fmt.Errorf("loading reports: %w", err)
+		}
+		if err := tx.Bucket(statusesBucket).ForEach(func(_, v []byte) error {
+			var rec StatusRecord
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return err
+			}
+			snap.Statuses = append(snap.Statuses, rec)
+			return nil
+		}); err != nil {
+			return fmt.Errorf("loading statuses: %w", err)
+		}
+		return nil
+	})
+	return snap, err
+}
+
+func (s *BoltStore) Prune(cutoff time.Time) (int, error) {
+	deleted := 0
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		expired := make(map[string]bool)
+		mints := tx.Bucket(mintsBucket)
+		if err := mints.ForEach(func(k, v []byte) error {
+			var rec MintRecord
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return err
+			}
+			if rec.DetectedAt.Before(cutoff) {
+				expired[string(k)] = true
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		for mint := range expired {
+			if err := mints.Delete([]byte(mint)); err != nil {
+				return err
+			}
+			deleted++
+		}
+
+		for _, name := range [][]byte{reportsBucket, statusesBucket} {
+			bucket := tx.Bucket(name)
+			// borrar mientras se itera con el cursor saltea elementos, así que primero se juntan las claves
+			var keys [][]byte
+			if err := bucket.ForEach(func(k, _ []byte) error {
+				mint := string(k[:max(bytes.IndexByte(k, 0), 0)])
+				if expired[mint] || keyTime(k).Before(cutoff) {
+					keys = append(keys, append([]byte(nil), k...))
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			for _, k := range keys {
+				if err := bucket.Delete(k); err != nil {
+					return err
+				}
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// Compact copia la base a un archivo nuevo (bbolt no achica el archivo al borrar) y lo
+// reemplaza. La base original sigue abierta hasta que la copia se abrió y ocupó su lugar: si
+// algo falla antes se sigue usando el original y se devuelve el error.
+func (s *BoltStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmpPath := s.path + ".compact"
+	_ = os.Remove(tmpPath)
+	dst, err := bolt.Open(tmpPath, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("compacting store: %w", err)
+	}
+	if err := bolt.Compact(dst, s.db, compactTxMaxSize); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("compacting store: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("compacting store: %w", err)
+	}
+	compacted, err := openBoltDB(tmpPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("compacting store: %w", err)
+	}
+	// la copia abierta sigue valiendo después de renombrar el archivo, y el original hasta
+	// que se cierre
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		compacted.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("compacting store: %w", err)
+	}
+
+	old := s.db
+	s.db = compacted
+	if err := old.Close(); err != nil {
+		return fmt.Errorf("compacting store: closing the old file: %w", err)
+	}
+	return nil
+}
+
+func (s *BoltStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.Close()
+}
@@ -0,0 +1,101 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gosol/storage"
+	"gosol/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoltStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	store, err := storage.OpenBolt(path)
+	require.NoError(t, err)
+
+	base := time.Date(2024, 11, 20, 14, 2, 0, 0, time.UTC)
+	require.NoError(t, store.SaveMint(storage.MintRecord{Mint: "mintA", DetectedAt: base}))
+	require.NoError(t, store.SaveReport(storage.ReportRecord{Mint: "mintA", RecordedAt: base.Add(2 * time.Minute), Report: types.Report{Mint: "mintA", Score: 800}}))
+	require.NoError(t, store.SaveReport(storage.ReportRecord{Mint: "mintA", RecordedAt: base.Add(time.Minute), Report: types.Report{Mint: "mintA", Score: 3400}}))
+	require.NoError(t, store.SaveStatus(storage.StatusRecord{Mint: "mintA", At: base, Status: storage.StatusDetected}))
+	require.NoError(t, store.Close())
+
+	// reabrir: los datos sobreviven y los reportes vuelven ordenados por fecha
+	store, err = storage.OpenBolt(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	snap, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, snap.Mints, 1)
+	assert.True(t, base.Equal(snap.Mints[0].DetectedAt))
+	require.Len(t, snap.Reports, 2)
+	assert.Equal(t, 3400, snap.Reports[0].Report.Score)
+	assert.Equal(t, 800, snap.Reports[1].Report.Score)
+	require.Len(t, snap.Statuses, 1)
+	assert.Equal(t, storage.StatusDetected, snap.Statuses[0].Status)
+}
+
+func TestBoltStorePruneAndCompact(t *testing.T) {
+	store, err := storage.OpenBolt(filepath.Join(t.TempDir(), "state.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	now := time.Now()
+	old, recent := now.Add(-10*24*time.Hour), now.Add(-time.Hour)
+
+	require.NoError(t, store.SaveMint(storage.MintRecord{Mint: "old", DetectedAt: old}))
+	require.NoError(t, store.SaveReport(storage.ReportRecord{Mint: "old", RecordedAt: recent, Report: types.Report{Mint: "old"}}))
+	require.NoError(t, store.SaveMint(storage.MintRecord{Mint: "new", DetectedAt: recent}))
+	require.NoError(t, store.SaveReport(storage.ReportRecord{Mint: "new", RecordedAt: recent, Report: types.Report{Mint: "new"}}))
+	require.NoError(t, store.SaveStatus(storage.StatusRecord{Mint: "new", At: old, Status: storage.StatusDetected}))
+
+	deleted, err := store.Prune(now.Add(-7 * 24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 3, deleted)
+
+	require.NoError(t, store.Compact())
+
+	snap, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, snap.Mints, 1)
+	assert.Equal(t, "new", snap.Mints[0].Mint)
+	require.Len(t, snap.Reports, 1)
+	assert.Equal(t, "new", snap.Reports[0].Mint)
+	assert.Empty(t, snap.Statuses)
+
+	// después de compactar el store sigue aceptando escrituras
+	require.NoError(t, store.SaveMint(storage.MintRecord{Mint: "later", DetectedAt: now}))
+}
+
+func TestBoltStoreCompactFailureKeepsTheStoreOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	store, err := storage.OpenBolt(path)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveMint(storage.MintRecord{Mint: "mint", DetectedAt: time.Now()}))
+
+	// el archivo temporal no se puede crear: la compactación falla antes de tocar la base
+	require.NoError(t, os.MkdirAll(filepath.Join(path+".compact", "busy"), 0o700))
+	assert.Error(t, store.Compact())
+	require.NoError(t, store.SaveMint(storage.MintRecord{Mint: "after-failure", DetectedAt: time.Now()}))
+
+	// una vez liberado compacta, y lo escrito después queda en el archivo nuevo
+	require.NoError(t, os.RemoveAll(path+".compact"))
+	require.NoError(t, store.Compact())
+	require.NoError(t, store.SaveMint(storage.MintRecord{Mint: "after-compact", DetectedAt: time.Now()}))
+	require.NoError(t, store.Close())
+	_, err = os.Stat(path + ".compact")
+	assert.True(t, os.IsNotExist(err))
+
+	reopened, err := storage.OpenBolt(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+	snap, err := reopened.Load()
+	require.NoError(t, err)
+	assert.Len(t, snap.Mints, 3)
+}
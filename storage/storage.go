@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"gosol/types"
+	"time"
+)
+
+// Estados por los que pasa un mint. Se guardan como historial junto a los reportes.
+const (
+	StatusDetected  = "detected"
+	StatusReported  = "reported"
+	StatusDiscarded = "discarded"
+	StatusError     = "error"
+	// StatusDropped es un token cuya transacción de creación se perdió (ver CommitmentDropped).
+	StatusDropped = "dropped"
+	// StatusCurveComplete indica que la bonding curve de pump.fun se completó.
+	StatusCurveComplete = "curve_complete"
+)
+
+type MintRecord struct {
+	Mint       string    `json:"mint"`
+	DetectedAt time.Time `json:"detectedAt"`
+	// Pool es el pool con el que se detectó el mint, si se detectó on-chain.
+	Pool *types.PoolCreated `json:"pool,omitempty"`
+	// Curve es la bonding curve de pump.fun del mint, si se lanzó ahí.
+	Curve *types.BondingCurve `json:"curve,omitempty"`
+	// Mentions son las fuentes que nombraron el mint, en orden.
+	Mentions []types.Mention `json:"mentions,omitempty"`
+}
+
+// ReportRecord es una foto de un reporte en el momento en que se recibió.
+type ReportRecord struct {
+	Mint       string       `json:"mint"`
+	RecordedAt time.Time    `json:"recordedAt"`
+	Report     types.Report `json:"report"`
+	// Discarded indica que el reporte se descartó (veredicto ignore) y no se mostró.
+	Discarded bool `json:"discarded,omitempty"`
+}
+
+type StatusRecord struct {
+	Mint   string    `json:"mint"`
+	At     time.Time `json:"at"`
+	Status string    `json:"status"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// Snapshot es todo lo persistido, ordenado por fecha dentro de cada mint.
+type Snapshot struct {
+	Mints    []MintRecord
+	Reports  []ReportRecord
+	Statuses []StatusRecord
+}
+
+// Store es el almacenamiento del StateManager. Las implementaciones deben ser seguras
+// para uso concurrente.
+type Store interface {
+	SaveMint(MintRecord) error
+	SaveReport(ReportRecord) error
+	SaveStatus(StatusRecord) error
+	Load() (Snapshot, error)
+	// Prune borra los mints detectados antes de cutoff (con todo su historial) y los
+	// reportes y estados más viejos que cutoff. Devuelve la cantidad de registros borrados.
+	Prune(cutoff time.Time) (int, error)
+	// Compact recupera el espacio liberado por Prune.
+	Compact() error
+	Close() error
+}
+
+// NopStore no persiste nada; se usa cuando el almacenamiento está deshabilitado.
+type NopStore struct{}
+
+func (NopStore) SaveMint(MintRecord) error           { return nil }
+func (NopStore) SaveReport(ReportRecord) error       { return nil }
+func (NopStore) SaveStatus(StatusRecord) error       { return nil }
+func (NopStore) Load() (Snapshot, error)             { return Snapshot{}, nil }
+func (NopStore) Prune(cutoff time.Time) (int, error) { return 0, nil }
+func (NopStore) Compact() error                      { return nil }
+func (NopStore) Close() error                        { return nil }
@@ -0,0 +1,143 @@
+package telegramadapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// BotAPI es un cliente mínimo de la Bot API de Telegram (https://core.telegram.org/bots/api).
+type BotAPI struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// BotAPIError es un error devuelto por la Bot API. RetryAfter viene con los 429.
+type BotAPIError struct {
+	Method      string
+	Code        int
+	Description string
+	RetryAfter  time.Duration
+}
+
+func (e *BotAPIError) Error() string {
+	return fmt.Sprintf("bot api %s: %d %s", e.Method, e.Code, e.Description)
+}
+
+func NewBotAPI(baseURL, token string, httpClient *http.Client) *BotAPI {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &BotAPI{baseURL: strings.TrimSuffix(baseURL, "/"), token: token, httpClient: httpClient}
+}
+
+// botMessage es el Message de la Bot API, con los campos que se usan.
+type botMessage struct {
+	MessageID int      `json:"message_id"`
+	Chat      botChat  `json:"chat"`
+	From      *botUser `json:"from"`
+	Text      string   `json:"text"`
+}
+
+type botChat struct {
+	ID int64 `json:"id"`
+}
+
+type botUser struct {
+	ID int64 `json:"id"`
+}
+
+// botUpdate es un Update de la Bot API; solo se piden mensajes.
+type botUpdate struct {
+	UpdateID int         `json:"update_id"`
+	Message  *botMessage `json:"message"`
+}
+
+// GetUpdates espera hasta timeout (long polling) los mensajes nuevos a partir de offset.
+func (b *BotAPI) GetUpdates(ctx context.Context, offset int, timeout time.Duration) ([]botUpdate, error) {
+	var updates []botUpdate
+	err := b.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// SendMessage publica un mensaje con formato HTML y devuelve su id.
+func (b *BotAPI) SendMessage(ctx context.Context, chatID int64, text string) (int, error) {
+	var msg botMessage
+	err := b.call(ctx, "sendMessage", map[string]any{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}, &msg)
+	return msg.MessageID, err
+}
+
+// EditMessageText reemplaza el texto de un mensaje publicado.
+func (b *BotAPI) EditMessageText(ctx context.Context, chatID int64, messageID int, text string) error {
+	return b.call(ctx, "editMessageText", map[string]any{
+		"chat_id":                  chatID,
+		"message_id":               messageID,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}, nil)
+}
+
+func (b *BotAPI) call(ctx context.Context, method string, params any, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.baseURL+"/bot"+b.token+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		// la URL lleva el token: no repetirla en el error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("bot api %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("bot api %s: decoding response (status %d): %w", method, resp.StatusCode, err)
+	}
+	if !out.OK {
+		return &BotAPIError{
+			Method:      method,
+			Code:        out.ErrorCode,
+			Description: out.Description,
+			RetryAfter:  time.Duration(out.Parameters.RetryAfter) * time.Second,
+		}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(out.Result, result)
+}
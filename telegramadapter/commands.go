@@ -0,0 +1,336 @@
+package telegramadapter
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gosol/config"
+	"gosol/monitor"
+	"gosol/rules"
+	"gosol/types"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// pollTimeout es la espera de cada getUpdates (long polling).
+	pollTimeout = 20 * time.Second
+	// scanTimeout es cuánto se espera el reporte de un /scan antes de avisar que no llegó.
+	scanTimeout = 2 * time.Minute
+	// maxListedTokens acota la respuesta de /list.
+	maxListedTokens = 20
+)
+
+// Commands atiende los comandos del bot de los usuarios de cfg.AllowedUsers para controlar
+// el monitor de forma remota.
+type Commands struct {
+	cfg      config.TelegramBotConfig
+	bot      *BotAPI
+	app      *monitor.App
+	notifier *Notifier
+
+	mu sync.Mutex
+	// scans son los chats que esperan el reporte de un /scan, por mint.
+	scans map[string][]pendingScan
+	// outbox son las respuestas que envía sendReplies, con el límite de cada chat.
+	outbox   []botReply
+	wake     chan struct{}
+	limiters map[int64]*rate.Limiter
+}
+
+type botReply struct {
+	chatID int64
+	text   string
+}
+
+type pendingScan struct {
+	chatID   int64
+	deadline time.Time
+}
+
+func NewCommands(cfg config.TelegramBotConfig, bot *BotAPI, app *monitor.App, notifier *Notifier) *Commands {
+	return &Commands{
+		cfg:      cfg,
+		bot:      bot,
+		app:      app,
+		notifier: notifier,
+		scans:    make(map[string][]pendingScan),
+		wake:     make(chan struct{}, 1),
+		limiters: make(map[int64]*rate.Limiter),
+	}
+}
+
+// Run lee los mensajes del bot y responde los comandos hasta que se cancele ctx.
+func (c *Commands) Run(ctx context.Context) {
+	go c.sendReplies(ctx)
+	offset := 0
+	for {
+		updates, err := c.bot.GetUpdates(ctx, offset, pollTimeout)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			c.app.StatusUpdates <- monitor.StatusMessage{Level: monitor.ERR, Message: fmt.Sprintf("Error reading Telegram bot updates: %v", err)}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			if update.Message != nil {
+				c.handle(update.Message)
+			}
+		}
+		c.expireScans(time.Now())
+	}
+}
+
+// handle responde un mensaje. Los de usuarios fuera de la lista se rechazan.
+func (c *Commands) handle(msg *botMessage) {
+	command, args := parseCommand(msg.Text)
+	if command == "" {
+		return
+	}
+	if msg.From == nil || !slices.Contains(c.cfg.AllowedUsers, msg.From.ID) {
+		var from int64
+		if msg.From != nil {
+			from = msg.From.ID
+		}
+		c.app.StatusUpdates <- monitor.StatusMessage{Level: monitor.WARN, Message: fmt.Sprintf("Telegram bot: rejected /%s from user %d", command, from)}
+		c.reply(msg.Chat.ID, "⛔ Not authorized.")
+		return
+	}
+
+	switch command {
+	case "scan":
+		c.scan(msg.Chat.ID, args)
+	case "list":
+		c.reply(msg.Chat.ID, c.list())
+	case "watch":
+		c.watch(msg.Chat.ID, args)
+	case "unwatch":
+		c.unwatch(msg.Chat.ID, args)
+	case "mute":
+		c.mute(msg.Chat.ID, args)
+	case "status":
+		c.reply(msg.Chat.ID, c.status())
+	default:
+		c.reply(msg.Chat.ID, "Commands: /scan &lt;mint&gt;, /list, /watch &lt;mint&gt;, /unwatch &lt;mint&gt;, /mute &lt;minutes&gt;, /status")
+	}
+}
+
+// parseCommand separa "/scan@bot abc" en ("scan", ["abc"]). Devuelve "" si no es un comando.
+func parseCommand(text string) (string, []string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil
+	}
+	command, _, _ := strings.Cut(strings.TrimPrefix(fields[0], "/"), "@")
+	return strings.ToLower(command), fields[1:]
+}
+
+// mintArg valida que args tenga una sola dirección de Solana.
+func mintArg(args []string) (string, bool) {
+	if len(args) != 1 || !validAddress(args[0]) {
+		return "", false
+	}
+	return args[0], true
+}
+
+func (c *Commands) scan(chatID int64, args []string) {
+	mint, ok := mintArg(args)
+	if !ok {
+		c.reply(chatID, "Usage: /scan &lt;mint&gt;")
+		return
+	}
+	c.mu.Lock()
+	c.scans[mint] = append(c.scans[mint], pendingScan{chatID: chatID, deadline: time.Now().Add(scanTimeout)})
+	c.mu.Unlock()
+
+	c.app.ApiClient.RequestReportOnDemand(mint)
+	c.reply(chatID, fmt.Sprintf("🔎 Scanning <code>%s</code>...", mint))
+}
+
+// Report responde los /scan que esperaban este reporte (ver APIClient.OnReport). Corre en
+// los workers de reportes, así que solo encola las respuestas.
+func (c *Commands) Report(report types.Report, result rules.Result) {
+	c.mu.Lock()
+	waiting := c.scans[report.Mint]
+	delete(c.scans, report.Mint)
+	c.mu.Unlock()
+
+	if len(waiting) == 0 {
+		return
+	}
+	card := RenderCard(report, result)
+	for _, scan := range waiting {
+		c.reply(scan.chatID, card)
+	}
+}
+
+// expireScans avisa de los /scan cuyo reporte no llegó a tiempo.
+func (c *Commands) expireScans(now time.Time) {
+	c.mu.Lock()
+	var expired []pendingScan
+	var mints []string
+	for mint, scans := range c.scans {
+		kept := scans[:0]
+		for _, scan := range scans {
+			if now.After(scan.deadline) {
+				expired = append(expired, scan)
+				mints = append(mints, mint)
+			} else {
+				kept = append(kept, scan)
+			}
+		}
+		if len(kept) == 0 {
+			delete(c.scans, mint)
+		} else {
+			c.scans[mint] = kept
+		}
+	}
+	c.mu.Unlock()
+
+	for i, scan := range expired {
+		c.reply(scan.chatID, fmt.Sprintf("⌛ No report for <code>%s</code> yet.", mints[i]))
+	}
+}
+
+func (c *Commands) list() string {
+	tokens := c.app.StateManager.Tokens()
+	if len(tokens) == 0 {
+		return "No tokens tracked yet."
+	}
+	lines := []string{fmt.Sprintf("<b>%d tokens</b>", len(tokens))}
+	if len(tokens) > maxListedTokens {
+		lines[0] += fmt.Sprintf(" (last %d)", maxListedTokens)
+		tokens = tokens[len(tokens)-maxListedTokens:]
+	}
+	for _, token := range tokens {
+		line := fmt.Sprintf("%s %s <b>%s</b> score %d <code>%s</code>", token.Color, token.CreatedAt, html.EscapeString(token.Symbol), token.Score, token.Address)
+		if token.Mentions > 0 {
+			line += fmt.Sprintf(" · %d ch", token.Mentions)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *Commands) watch(chatID int64, args []string) {
+	mint, ok := mintArg(args)
+	if !ok {
+		c.reply(chatID, "Usage: /watch &lt;mint&gt;")
+		return
+	}
+	c.notifier.Watch(mint)
+	c.app.StateManager.AddMint(mint)
+	// un mint ya conocido puede haber salido de los re-escaneos
+	c.app.Rescans.Track(mint, time.Now())
+	c.app.ApiClient.RequestReportOnDemand(mint)
+	c.reply(chatID, fmt.Sprintf("👀 Watching <code>%s</code>.", mint))
+}
+
+func (c *Commands) unwatch(chatID int64, args []string) {
+	mint, ok := mintArg(args)
+	if !ok {
+		c.reply(chatID, "Usage: /unwatch &lt;mint&gt;")
+		return
+	}
+	c.notifier.Unwatch(mint)
+	c.app.Rescans.Untrack(mint)
+	c.reply(chatID, fmt.Sprintf("Stopped watching <code>%s</code>.", mint))
+}
+
+func (c *Commands) mute(chatID int64, args []string) {
+	var minutes int
+	var err error
+	if len(args) == 1 {
+		minutes, err = strconv.Atoi(args[0])
+	}
+	if len(args) != 1 || err != nil || minutes < 0 {
+		c.reply(chatID, "Usage: /mute &lt;minutes&gt; (0 to unmute)")
+		return
+	}
+	c.notifier.Mute(time.Duration(minutes) * time.Minute)
+	if minutes == 0 {
+		c.reply(chatID, "🔔 Notifications on.")
+		return
+	}
+	c.reply(chatID, fmt.Sprintf("🔕 Muted until %s.", c.notifier.MutedUntil().In(time.Local).Format("15:04")))
+}
+
+func (c *Commands) status() string {
+	stats := c.app.Stats()
+	lines := []string{fmt.Sprintf("Websocket: <b>%s</b>, %d reconnects", stats.Connection, stats.Reconnects)}
+	if !stats.LastMessage.IsZero() {
+		lines[0] += fmt.Sprintf(", last message %s ago", time.Since(stats.LastMessage).Round(time.Second))
+	}
+	lines = append(lines,
+		fmt.Sprintf("Ingest: %d/%d (dropped %d)", stats.Ingest.Depth, stats.Ingest.Capacity, stats.Ingest.Dropped),
+		fmt.Sprintf("Transactions: %d/%d, %d busy", stats.Transactions.Depth, stats.Transactions.Capacity, stats.Transactions.Busy),
+		fmt.Sprintf("Reports: %d/%d, %d busy, %d dropped", stats.ReportQueueDepth, stats.ReportQueueCap, stats.ReportWorkersBusy, stats.ReportsDropped),
+		fmt.Sprintf("Tracking: %d pending, %d dropped · %d mints rescanned", stats.Tracking.Pending, stats.Tracking.Dropped, stats.TrackedMints),
+	)
+	if stats.PumpFunIngest.Capacity > 0 {
+		lines = append(lines, fmt.Sprintf("pump.fun ingest: %d/%d", stats.PumpFunIngest.Depth, stats.PumpFunIngest.Capacity))
+	}
+	if until := c.notifier.MutedUntil(); time.Now().Before(until) {
+		lines = append(lines, fmt.Sprintf("🔕 Muted until %s", until.In(time.Local).Format("15:04")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// reply encola una respuesta para sendReplies; no bloquea.
+func (c *Commands) reply(chatID int64, text string) {
+	c.mu.Lock()
+	c.outbox = append(c.outbox, botReply{chatID: chatID, text: text})
+	c.mu.Unlock()
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// sendReplies envía las respuestas encoladas respetando el límite de cada chat hasta que
+// se cancele ctx.
+func (c *Commands) sendReplies(ctx context.Context) {
+	for {
+		c.mu.Lock()
+		if len(c.outbox) == 0 {
+			c.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.wake:
+			}
+			continue
+		}
+		next := c.outbox[0]
+		c.outbox = c.outbox[1:]
+		limiter, ok := c.limiters[next.chatID]
+		if !ok {
+			limiter = rate.NewLimiter(rate.Limit(c.cfg.RateLimit.RPS), c.cfg.RateLimit.Burst)
+			c.limiters[next.chatID] = limiter
+		}
+		c.mu.Unlock()
+
+		if err := limiter.Wait(ctx); err != nil {
+			return
+		}
+		if _, err := c.bot.SendMessage(ctx, next.chatID, next.text); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.app.StatusUpdates <- monitor.StatusMessage{Level: monitor.ERR, Message: fmt.Sprintf("Error replying to Telegram chat %d: %v", next.chatID, err)}
+		}
+	}
+}
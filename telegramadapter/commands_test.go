@@ -0,0 +1,155 @@
+package telegramadapter_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gosol/config"
+	"gosol/monitor"
+	"gosol/telegramadapter"
+	"gosol/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBotChat responde getUpdates con los mensajes de send y guarda las respuestas del bot.
+type fakeBotChat struct {
+	mu      sync.Mutex
+	updates []map[string]any
+	replies []string
+}
+
+func (f *fakeBotChat) send(userID int64, text string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates = append(f.updates, map[string]any{
+		"update_id": len(f.updates) + 1,
+		"message":   map[string]any{"message_id": 1, "chat": map[string]any{"id": userID}, "from": map[string]any{"id": userID}, "text": text},
+	})
+}
+
+func (f *fakeBotChat) lastReply() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.replies) == 0 {
+		return ""
+	}
+	return f.replies[len(f.replies)-1]
+}
+
+func (f *fakeBotChat) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Offset int    `json:"offset"`
+		Text   string `json:"text"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&params)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if strings.HasSuffix(r.URL.Path, "/sendMessage") {
+		f.replies = append(f.replies, params.Text)
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": map[string]any{"message_id": len(f.replies)}})
+		return
+	}
+	result := []map[string]any{}
+	if params.Offset > 0 && params.Offset <= len(f.updates) {
+		result = f.updates[params.Offset-1:]
+	} else if params.Offset == 0 {
+		result = f.updates
+	}
+	if len(result) == 0 {
+		// sin mensajes nuevos: simular una parte del long polling
+		time.Sleep(10 * time.Millisecond)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+func TestCommandsControlTheMonitor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mint, watched := solana.NewWallet().PublicKey().String(), solana.NewWallet().PublicKey().String()
+	fixtures := t.TempDir()
+	for m, symbol := range map[string]string{mint: "SCAN", watched: "WATCH"} {
+		data, err := json.Marshal(types.Report{Mint: m, TokenMeta: types.TokenMeta{Symbol: symbol}})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(fixtures, m+".json"), data, 0o600))
+	}
+	cfg := config.Default()
+	cfg.Solana.RayFeePubkey = solana.NewWallet().PublicKey().String()
+	cfg.RPC.Endpoints = []config.RPCEndpointConfig{{Name: "local", URL: "http://127.0.0.1:1"}}
+	cfg.Report.Providers = []config.ReportProviderConfig{{Name: "fixture", Type: config.ProviderFixture, Path: fixtures}}
+	cfg.Storage.Enabled = false
+	app, err := monitor.NewApp(cfg)
+	require.NoError(t, err)
+	defer app.Cancel()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-app.StatusUpdates:
+			}
+		}
+	}()
+
+	chat := &fakeBotChat{}
+	srv := httptest.NewServer(chat)
+	t.Cleanup(srv.Close)
+	botCfg := config.TelegramBotConfig{
+		Verdicts:     []string{config.VerdictAlert},
+		RateLimit:    config.RateLimitConfig{RPS: 100, Burst: 1},
+		Commands:     true,
+		AllowedUsers: []int64{42},
+	}
+	bot := telegramadapter.NewBotAPI(srv.URL, "token", nil)
+	notifier := telegramadapter.NewNotifier(botCfg, bot, app.StatusUpdates)
+	commands := telegramadapter.NewCommands(botCfg, bot, app, notifier)
+	app.ApiClient.OnReport = commands.Report
+	app.ApiClient.Start(ctx)
+	go commands.Run(ctx)
+
+	waitReply := func(contains string) {
+		t.Helper()
+		require.Eventually(t, func() bool { return strings.Contains(chat.lastReply(), contains) }, 5*time.Second, 10*time.Millisecond, contains)
+	}
+
+	// los usuarios que no están en la lista no pueden usar los comandos
+	chat.send(7, "/list")
+	waitReply("Not authorized")
+
+	chat.send(42, "/scan notAMint")
+	waitReply("Usage: /scan")
+
+	// /scan responde con la tarjeta cuando llega el reporte
+	chat.send(42, "/scan@gosol_bot "+mint)
+	waitReply("<b>SCAN</b>")
+
+	chat.send(42, "/watch "+watched)
+	waitReply("Watching")
+	chat.send(42, "/list")
+	waitReply("tokens</b>")
+	assert.Contains(t, chat.lastReply(), watched)
+
+	chat.send(42, "/mute 30")
+	waitReply("Muted until")
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), notifier.MutedUntil(), time.Minute)
+	chat.send(42, "/mute 0")
+	waitReply("Notifications on")
+	assert.False(t, time.Now().Before(notifier.MutedUntil()))
+
+	chat.send(42, "/status")
+	waitReply("Websocket:")
+	assert.Contains(t, chat.lastReply(), "Reports: 0/100")
+	assert.Contains(t, chat.lastReply(), "Transactions: 0/1000")
+}
@@ -0,0 +1,7 @@
+package telegramadapter
+
+// Acceso a lo interno del TelegramClient para los tests de telegramadapter_test.
+
+func (t *TelegramClient) HandleMessage(peerID int64, text string) {
+	t.handleMessage(peerID, text)
+}
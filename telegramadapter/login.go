@@ -0,0 +1,123 @@
+package telegramadapter
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gosol/config"
+
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/auth"
+	"github.com/gotd/td/tg"
+	"golang.org/x/term"
+)
+
+var (
+	// ErrNotLoggedIn indica que todavía no se inició sesión.
+	ErrNotLoggedIn = errors.New("telegram: not logged in, run `gosol telegram login`")
+	// ErrSessionRevoked indica que la sesión guardada ya no es válida (se cerró desde otro
+	// dispositivo o expiró).
+	ErrSessionRevoked = errors.New("telegram: session revoked or expired, run `gosol telegram login` again")
+)
+
+// Login inicia sesión con la cuenta de cfg.Phone (o la que se ingrese) y la guarda cifrada en
+// cfg.SessionFile. El código y, si la cuenta lo tiene, el password de la verificación en dos
+// pasos se piden por in/out. Si la sesión guardada sigue siendo válida no pide nada.
+func Login(ctx context.Context, cfg config.TelegramConfig, in io.Reader, out io.Writer) error {
+	client := telegram.NewClient(cfg.APIID, cfg.APIHash, telegram.Options{
+		SessionStorage: NewSessionStorage(cfg.SessionFile, cfg.SessionKey),
+	})
+	prompt := &terminalAuth{phone: cfg.Phone, in: in, reader: bufio.NewReader(in), out: out}
+
+	err := client.Run(ctx, func(ctx context.Context) error {
+		flow := auth.NewFlow(prompt, auth.SendCodeOptions{})
+		if err := client.Auth().IfNecessary(ctx, flow); err != nil {
+			return err
+		}
+		status, err := client.Auth().Status(ctx)
+		if err != nil {
+			return err
+		}
+		if !status.Authorized {
+			return errors.New("telegram did not authorize the session")
+		}
+		fmt.Fprintf(out, "Sesión iniciada como %s; guardada en %s\n", displayName(status.User), cfg.SessionFile)
+		return nil
+	})
+	if errors.Is(err, ErrSessionKey) {
+		return fmt.Errorf("%w (use the key from the first login or delete %s)", err, cfg.SessionFile)
+	}
+	if err != nil {
+		return fmt.Errorf("telegram login: %w", err)
+	}
+	return nil
+}
+
+// sessionError traduce los errores de una sesión inválida a los de este paquete.
+func sessionError(err error) error {
+	if auth.IsUnauthorized(err) {
+		return ErrSessionRevoked
+	}
+	return err
+}
+
+func displayName(user *tg.User) string {
+	if user == nil {
+		return "?"
+	}
+	if user.Username != "" {
+		return "@" + user.Username
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
+// terminalAuth pide los datos del login por la terminal. Implementa auth.UserAuthenticator.
+type terminalAuth struct {
+	phone  string
+	in     io.Reader
+	reader *bufio.Reader
+	out    io.Writer
+}
+
+func (a *terminalAuth) Phone(_ context.Context) (string, error) {
+	if a.phone != "" {
+		return a.phone, nil
+	}
+	return a.ask("Teléfono (con código de país, ej. +54911...): ", false)
+}
+
+func (a *terminalAuth) Code(_ context.Context, _ *tg.AuthSentCode) (string, error) {
+	return a.ask("Código recibido en Telegram: ", false)
+}
+
+func (a *terminalAuth) Password(_ context.Context) (string, error) {
+	return a.ask("Password de la verificación en dos pasos: ", true)
+}
+
+func (a *terminalAuth) AcceptTermsOfService(_ context.Context, tos tg.HelpTermsOfService) error {
+	return &auth.SignUpRequired{TermsOfService: tos}
+}
+
+func (a *terminalAuth) SignUp(_ context.Context) (auth.UserInfo, error) {
+	return auth.UserInfo{}, errors.New("this phone number has no Telegram account; sign up from the app first")
+}
+
+// ask muestra la pregunta y lee una línea; si secret y la entrada es una terminal, sin eco.
+func (a *terminalAuth) ask(question string, secret bool) (string, error) {
+	fmt.Fprint(a.out, question)
+	if f, ok := a.in.(*os.File); ok && secret && term.IsTerminal(int(f.Fd())) {
+		answer, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(a.out)
+		return strings.TrimSpace(string(answer)), err
+	}
+	answer, err := a.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || answer == "") {
+		return "", err
+	}
+	return strings.TrimSpace(answer), nil
+}
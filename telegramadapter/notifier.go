@@ -0,0 +1,281 @@
+package telegramadapter
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gosol/config"
+	"gosol/monitor"
+	"gosol/rules"
+	"gosol/types"
+
+	"golang.org/x/time/rate"
+)
+
+// maxCardRisks es cuántos riesgos (los de mayor score) muestra una tarjeta.
+const maxCardRisks = 3
+
+// maxBotRetries es cuántas veces se reintenta un envío que la Bot API frenó con un 429.
+const maxBotRetries = 3
+
+// Notifier publica una tarjeta por cada token que pasa los filtros en los chats de
+// cfg.ChatIDs y la edita cuando un re-escaneo cambia lo que muestra. Las tarjetas solo se
+// recuerdan en memoria: después de reiniciar se publica una nueva.
+type Notifier struct {
+	cfg           config.TelegramBotConfig
+	bot           *BotAPI
+	statusUpdates chan<- monitor.StatusMessage
+
+	mu       sync.Mutex
+	cards    map[string]*card
+	pending  []string
+	wake     chan struct{}
+	limiters map[int64]*rate.Limiter
+	// watched son los mints que se publican con cualquier veredicto (ver Watch).
+	watched    map[string]bool
+	mutedUntil time.Time
+}
+
+// card es la tarjeta de un mint: el último texto y lo publicado en cada chat.
+type card struct {
+	text   string
+	queued bool
+	posted map[int64]postedCard
+}
+
+type postedCard struct {
+	messageID int
+	text      string
+}
+
+func NewNotifier(cfg config.TelegramBotConfig, bot *BotAPI, statusUpdates chan<- monitor.StatusMessage) *Notifier {
+	n := &Notifier{
+		cfg:           cfg,
+		bot:           bot,
+		statusUpdates: statusUpdates,
+		cards:         make(map[string]*card),
+		wake:          make(chan struct{}, 1),
+		limiters:      make(map[int64]*rate.Limiter, len(cfg.ChatIDs)),
+		watched:       make(map[string]bool),
+	}
+	for _, chatID := range cfg.ChatIDs {
+		n.limiters[chatID] = rate.NewLimiter(rate.Limit(cfg.RateLimit.RPS), cfg.RateLimit.Burst)
+	}
+	return n
+}
+
+// Notify recibe un reporte evaluado (ver APIClient.OnReport). Publica la tarjeta si el
+// veredicto está en cfg.Verdicts (o el mint está en seguimiento) o la actualiza si ya estaba
+// publicada. Mientras está silenciado no publica tarjetas nuevas. No bloquea: el envío lo
+// hace Run.
+func (n *Notifier) Notify(report types.Report, result rules.Result) {
+	text := RenderCard(report, result)
+
+	n.mu.Lock()
+	c, ok := n.cards[report.Mint]
+	if !ok {
+		if time.Now().Before(n.mutedUntil) || !(n.watched[report.Mint] || slices.Contains(n.cfg.Verdicts, result.Verdict)) {
+			n.mu.Unlock()
+			return
+		}
+		c = &card{posted: make(map[int64]postedCard)}
+		n.cards[report.Mint] = c
+	}
+	if c.text == text {
+		n.mu.Unlock()
+		return
+	}
+	c.text = text
+	if !c.queued {
+		// los cambios que lleguen mientras espera se juntan en un solo envío
+		c.queued = true
+		n.pending = append(n.pending, report.Mint)
+	}
+	n.mu.Unlock()
+	n.signal()
+}
+
+// Watch publica la tarjeta del mint con cualquier veredicto.
+func (n *Notifier) Watch(mint string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.watched[mint] = true
+}
+
+// Unwatch deja de publicar y de editar la tarjeta del mint.
+func (n *Notifier) Unwatch(mint string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.watched, mint)
+	delete(n.cards, mint)
+}
+
+// Mute silencia las tarjetas por d (0 quita el silencio). Las ediciones de las ya
+// publicadas se envían juntas al terminar.
+func (n *Notifier) Mute(d time.Duration) {
+	n.mu.Lock()
+	n.mutedUntil = time.Now().Add(d)
+	n.mu.Unlock()
+	n.signal()
+}
+
+// MutedUntil devuelve hasta cuándo está silenciado (cero o pasado si no lo está).
+func (n *Notifier) MutedUntil() time.Time {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.mutedUntil
+}
+
+func (n *Notifier) signal() {
+	select {
+	case n.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run envía las tarjetas pendientes respetando el límite de cada chat hasta que se cancele ctx.
+func (n *Notifier) Run(ctx context.Context) {
+	for {
+		n.mu.Lock()
+		muted := time.Until(n.mutedUntil)
+		if len(n.pending) == 0 || muted > 0 {
+			n.mu.Unlock()
+			var unmuted <-chan time.Time
+			if muted > 0 {
+				unmuted = time.After(muted)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-n.wake:
+			case <-unmuted:
+			}
+			continue
+		}
+		mint := n.pending[0]
+		n.pending = n.pending[1:]
+		c, ok := n.cards[mint]
+		if !ok {
+			n.mu.Unlock()
+			continue
+		}
+		c.queued = false
+		text := c.text
+		n.mu.Unlock()
+
+		for _, chatID := range n.cfg.ChatIDs {
+			if err := n.post(ctx, chatID, c, text); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				n.statusUpdates <- monitor.StatusMessage{Level: monitor.ERR, Message: fmt.Sprintf("Error posting %s to Telegram chat %d: %v", mint, chatID, err)}
+			}
+		}
+	}
+}
+
+// post publica o edita la tarjeta en un chat. Solo lo llama Run.
+func (n *Notifier) post(ctx context.Context, chatID int64, c *card, text string) error {
+	n.mu.Lock()
+	posted := c.posted[chatID]
+	n.mu.Unlock()
+	if posted.text == text {
+		return nil
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := n.limiters[chatID].Wait(ctx); err != nil {
+			return err
+		}
+		var err error
+		if posted.messageID == 0 {
+			posted.messageID, err = n.bot.SendMessage(ctx, chatID, text)
+		} else {
+			err = n.bot.EditMessageText(ctx, chatID, posted.messageID, text)
+		}
+
+		var apiErr *BotAPIError
+		if errors.As(err, &apiErr) {
+			if apiErr.RetryAfter > 0 && attempt < maxBotRetries {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(apiErr.RetryAfter):
+				}
+				continue
+			}
+			if strings.Contains(apiErr.Description, "message is not modified") {
+				err = nil
+			}
+		}
+		if err != nil {
+			return err
+		}
+		break
+	}
+
+	n.mu.Lock()
+	c.posted[chatID] = postedCard{messageID: posted.messageID, text: text}
+	n.mu.Unlock()
+	return nil
+}
+
+// RenderCard arma la tarjeta (HTML de la Bot API) de un reporte.
+func RenderCard(report types.Report, result rules.Result) string {
+	name := report.TokenMeta.Symbol
+	if name == "" {
+		name = report.Mint
+	}
+	lines := []string{fmt.Sprintf("%s <b>%s</b>", result.Color, html.EscapeString(name))}
+	if report.TokenMeta.Name != "" {
+		lines[0] += " · " + html.EscapeString(report.TokenMeta.Name)
+	}
+	lines = append(lines,
+		fmt.Sprintf("Score: <b>%d</b> (%s)", result.Score, result.Verdict),
+		fmt.Sprintf("Liquidity: %s", formatUSD(report.TotalMarketLiquidity)),
+	)
+
+	if len(report.TopHolders) > 0 {
+		var top, top10 float64
+		for i, h := range report.TopHolders {
+			top = max(top, h.Pct)
+			if i < 10 {
+				top10 += h.Pct
+			}
+		}
+		lines = append(lines, fmt.Sprintf("Top holder: %.1f%% · top 10: %.1f%%", top, top10))
+	}
+
+	risks := slices.Clone(report.Risks)
+	slices.SortStableFunc(risks, func(a, b types.Risk) int { return cmp.Compare(b.Score, a.Score) })
+	if len(risks) > 0 {
+		lines = append(lines, "Risks:")
+		for _, risk := range risks[:min(len(risks), maxCardRisks)] {
+			lines = append(lines, fmt.Sprintf("• %s (%s)", html.EscapeString(risk.Name), html.EscapeString(risk.Level)))
+		}
+		if len(risks) > maxCardRisks {
+			lines = append(lines, fmt.Sprintf("• +%d more", len(risks)-maxCardRisks))
+		}
+	}
+
+	lines = append(lines, fmt.Sprintf("<code>%s</code>", report.Mint))
+	lines = append(lines, fmt.Sprintf(`<a href="https://rugcheck.xyz/tokens/%s">rugcheck</a>`, report.Mint))
+	return strings.Join(lines, "\n")
+}
+
+func formatUSD(v float64) string {
+	switch {
+	case v >= 1_000_000:
+		return fmt.Sprintf("$%.1fM", v/1_000_000)
+	case v >= 1_000:
+		return fmt.Sprintf("$%.1fK", v/1_000)
+	}
+	return fmt.Sprintf("$%.0f", v)
+}
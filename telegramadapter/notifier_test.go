@@ -0,0 +1,120 @@
+package telegramadapter_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gosol/config"
+	"gosol/monitor"
+	"gosol/rules"
+	"gosol/telegramadapter"
+	"gosol/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// botCall es un pedido recibido por fakeBotAPI.
+type botCall struct {
+	Method    string
+	ChatID    int64  `json:"chat_id"`
+	MessageID int    `json:"message_id"`
+	Text      string `json:"text"`
+}
+
+// fakeBotAPI responde como la Bot API; el primer pedido recibe un 429 si throttle es true.
+func fakeBotAPI(t *testing.T, throttle bool) (*telegramadapter.BotAPI, func() []botCall) {
+	var mu sync.Mutex
+	var calls []botCall
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var call botCall
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&call))
+		call.Method = r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		assert.True(t, strings.HasPrefix(r.URL.Path, "/bottoken/"))
+
+		mu.Lock()
+		defer mu.Unlock()
+		if throttle {
+			throttle = false
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error_code": 429, "description": "Too Many Requests", "parameters": map[string]any{"retry_after": 1}})
+			return
+		}
+		calls = append(calls, call)
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": map[string]any{"message_id": len(calls)}})
+	}))
+	t.Cleanup(srv.Close)
+	return telegramadapter.NewBotAPI(srv.URL, "token", nil), func() []botCall {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]botCall(nil), calls...)
+	}
+}
+
+func TestNotifierPostsAndEditsCards(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bot, calls := fakeBotAPI(t, true)
+	cfg := config.TelegramBotConfig{
+		ChatIDs:   []int64{-1001, -1002},
+		Verdicts:  []string{config.VerdictAlert},
+		RateLimit: config.RateLimitConfig{RPS: 100, Burst: 1},
+	}
+	notifier := telegramadapter.NewNotifier(cfg, bot, drainStatus(ctx))
+	go notifier.Run(ctx)
+
+	report := types.Report{
+		Mint:                 "Mint1111111111111111111111111111111111111111",
+		TokenMeta:            types.TokenMeta{Symbol: "ABC", Name: "A <b>C"},
+		TotalMarketLiquidity: 45500,
+		TopHolders:           []types.Holder{{Pct: 12.5}, {Pct: 7.5}},
+		Risks:                []types.Risk{{Name: "Low Liquidity", Level: "danger", Score: 500}, {Name: "Mutable metadata", Level: "warn", Score: 100}},
+	}
+	alert := rules.Result{Score: 1800, Verdict: config.VerdictAlert, Color: "🟢"}
+
+	// watch no publica; alert publica en los dos chats (el 429 se reintenta)
+	notifier.Notify(types.Report{Mint: "other"}, rules.Result{Verdict: config.VerdictWatch})
+	notifier.Notify(report, alert)
+	require.Eventually(t, func() bool { return len(calls()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	first := calls()
+	assert.Equal(t, "sendMessage", first[0].Method)
+	assert.Equal(t, []int64{-1001, -1002}, []int64{first[0].ChatID, first[1].ChatID})
+	assert.Contains(t, first[0].Text, "🟢 <b>ABC</b> · A &lt;b&gt;C")
+	assert.Contains(t, first[0].Text, "Liquidity: $45.5K")
+	assert.Contains(t, first[0].Text, "Top holder: 12.5% · top 10: 20.0%")
+	assert.Contains(t, first[0].Text, "• Low Liquidity (danger)\n• Mutable metadata (warn)")
+
+	// el mismo reporte no se reenvía; un re-escaneo que baja el veredicto edita la tarjeta
+	notifier.Notify(report, alert)
+	notifier.Notify(report, rules.Result{Score: 3500, Verdict: config.VerdictWatch, Color: "🟠"})
+	require.Eventually(t, func() bool { return len(calls()) == 4 }, 5*time.Second, 10*time.Millisecond)
+	edits := calls()[2:]
+	for i, edit := range edits {
+		assert.Equal(t, "editMessageText", edit.Method)
+		assert.Equal(t, first[i].ChatID, edit.ChatID)
+		assert.Equal(t, i+1, edit.MessageID)
+		assert.Contains(t, edit.Text, "Score: <b>3500</b> (watch)")
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, calls(), 4)
+}
+
+// drainStatus descarta los mensajes de estado hasta que se cancele ctx.
+func drainStatus(ctx context.Context) chan<- monitor.StatusMessage {
+	ch := make(chan monitor.StatusMessage, 10)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,143 @@
+package telegramadapter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gosol/config"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// ParsedMessage es lo que un parser extrae de un mensaje de un canal de alertas.
+type ParsedMessage struct {
+	Mint     string
+	Platform string
+	Pool     string
+	// Metrics son los demás datos citados en el mensaje (grupos con nombre de los patrones).
+	Metrics map[string]string
+}
+
+// Parser extrae el token de un mensaje; ok es false si el mensaje no menciona ninguno.
+type Parser interface {
+	Parse(text string) (msg ParsedMessage, ok bool)
+}
+
+// ParserRegistry tiene los parsers disponibles por nombre (ver config.TelegramConfig.ParserDefinitions).
+type ParserRegistry struct {
+	parsers map[string]Parser
+}
+
+func NewParserRegistry(definitions []config.TelegramParserConfig) (*ParserRegistry, error) {
+	r := &ParserRegistry{parsers: make(map[string]Parser, len(definitions))}
+	for _, def := range definitions {
+		parser, err := newParser(def)
+		if err != nil {
+			return nil, fmt.Errorf("telegram parser %q: %w", def.Name, err)
+		}
+		r.parsers[def.Name] = parser
+	}
+	return r, nil
+}
+
+// Get devuelve el parser con ese nombre.
+func (r *ParserRegistry) Get(name string) (Parser, bool) {
+	parser, ok := r.parsers[name]
+	return parser, ok
+}
+
+func newParser(def config.TelegramParserConfig) (Parser, error) {
+	switch def.Kind {
+	case config.ParserKindBase58:
+		return base58Parser{}, nil
+	case config.ParserKindRegex:
+		p := regexParser{}
+		for _, pattern := range def.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, err
+			}
+			p.patterns = append(p.patterns, re)
+		}
+		return p, nil
+	}
+	return nil, fmt.Errorf("unknown kind %q", def.Kind)
+}
+
+// regexParser junta los grupos con nombre de todos sus patrones (el primero que captura
+// cada grupo gana). Las direcciones se validan: un mint inválido descarta el mensaje y un
+// pool inválido se ignora.
+type regexParser struct {
+	patterns []*regexp.Regexp
+}
+
+func (p regexParser) Parse(text string) (ParsedMessage, bool) {
+	var msg ParsedMessage
+	for _, re := range p.patterns {
+		match := re.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		for i, name := range re.SubexpNames() {
+			value := strings.TrimSpace(match[i])
+			if name == "" || value == "" {
+				continue
+			}
+			switch name {
+			case "mint":
+				if msg.Mint == "" {
+					msg.Mint = value
+				}
+			case "platform":
+				if msg.Platform == "" {
+					msg.Platform = value
+				}
+			case "pool":
+				if msg.Pool == "" {
+					msg.Pool = value
+				}
+			default:
+				if msg.Metrics == nil {
+					msg.Metrics = make(map[string]string)
+				}
+				if _, ok := msg.Metrics[name]; !ok {
+					msg.Metrics[name] = value
+				}
+			}
+		}
+	}
+
+	if !validAddress(msg.Mint) {
+		return ParsedMessage{}, false
+	}
+	if msg.Pool != "" && !validAddress(msg.Pool) {
+		msg.Pool = ""
+	}
+	return msg, true
+}
+
+// base58Parser toma como mint la primera dirección válida del mensaje que no sea wrapped SOL.
+type base58Parser struct{}
+
+// base58Run son las tiras completas de caracteres base58: una dirección tiene que estar entre
+// caracteres que no lo sean, para no tomar 32-44 caracteres del medio de una firma (88) o de
+// una URL. RE2 no tiene lookarounds, así que se filtra el largo después.
+var base58Run = regexp.MustCompile(`[1-9A-HJ-NP-Za-km-z]+`)
+
+func (base58Parser) Parse(text string) (ParsedMessage, bool) {
+	for _, candidate := range base58Run.FindAllString(text, -1) {
+		if len(candidate) < 32 || len(candidate) > 44 {
+			continue
+		}
+		if candidate != solana.SolMint.String() && validAddress(candidate) {
+			return ParsedMessage{Mint: candidate}, true
+		}
+	}
+	return ParsedMessage{}, false
+}
+
+func validAddress(address string) bool {
+	_, err := solana.PublicKeyFromBase58(address)
+	return err == nil
+}
@@ -0,0 +1,72 @@
+package telegramadapter_test
+
+import (
+	"testing"
+
+	"gosol/config"
+	"gosol/telegramadapter"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsersExtractTokens(t *testing.T) {
+	mint, pool := solana.NewWallet().PublicKey().String(), solana.NewWallet().PublicKey().String()
+	cfg := config.TelegramConfig{Parsers: []config.TelegramParserConfig{{
+		Name: "pools",
+		Kind: config.ParserKindRegex,
+		Patterns: []string{
+			`CA: (?P<mint>\w+)`,
+			`Pool: (?P<pool>\w+)`,
+			`Liquidity: \$(?P<liquidity>[\d.,]+[KM]?)`,
+			`MC: \$(?P<market_cap>[\d.,]+[KM]?)`,
+		},
+	}}}
+	registry, err := telegramadapter.NewParserRegistry(cfg.ParserDefinitions())
+	require.NoError(t, err)
+	parse := func(name, text string) (telegramadapter.ParsedMessage, bool) {
+		t.Helper()
+		parser, ok := registry.Get(name)
+		require.True(t, ok, name)
+		return parser.Parse(text)
+	}
+
+	msg, ok := parse(config.ParserDefault, "🚀 New pool\nPlatform: Raydium || Pump Fun\nBase: "+mint+"\nQuote: So11111111111111111111111111111111111111112")
+	require.True(t, ok)
+	assert.Equal(t, telegramadapter.ParsedMessage{Mint: mint, Platform: "Raydium || Pump Fun"}, msg)
+
+	msg, ok = parse("pools", "CA: "+mint+"\nMC: $120K\nLiquidity: $45.5K\nPool: "+pool)
+	require.True(t, ok)
+	assert.Equal(t, telegramadapter.ParsedMessage{
+		Mint:    mint,
+		Pool:    pool,
+		Metrics: map[string]string{"liquidity": "45.5K", "market_cap": "120K"},
+	}, msg)
+
+	// un mint que no es una dirección válida descarta el mensaje
+	_, ok = parse("pools", "CA: notAnAddress")
+	assert.False(t, ok)
+
+	// base58 saltea wrapped SOL y lo que no es una clave válida
+	msg, ok = parse(config.ParserBase58, "swap So11111111111111111111111111111111111111112 -> "+mint+" 🔥")
+	require.True(t, ok)
+	assert.Equal(t, mint, msg.Mint)
+	_, ok = parse(config.ParserBase58, "no addresses here")
+	assert.False(t, ok)
+
+	// no toma una dirección del medio de una firma (88 caracteres)
+	sig, err := solana.NewWallet().PrivateKey.Sign([]byte("tx"))
+	require.NoError(t, err)
+	_, ok = parse(config.ParserBase58, "tx "+sig.String())
+	assert.False(t, ok)
+	decoy := solana.NewWallet().PublicKey().String()
+	for len(decoy) != 44 {
+		decoy = solana.NewWallet().PublicKey().String()
+	}
+	// los primeros 44 caracteres de la tira son una dirección válida
+	run := (decoy + sig.String())[:88]
+	msg, ok = parse(config.ParserBase58, "https://solscan.io/tx/"+run+" buy "+mint)
+	require.True(t, ok)
+	assert.Equal(t, mint, msg.Mint)
+}
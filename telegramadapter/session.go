@@ -0,0 +1,122 @@
+package telegramadapter
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/gotd/td/session"
+	"golang.org/x/crypto/scrypt"
+)
+
+// ErrSessionKey indica que la sesión guardada no se pudo descifrar: la clave no es la que
+// se usó en el login o el archivo está dañado.
+var ErrSessionKey = errors.New("telegram session: wrong session key or corrupted session file")
+
+// sessionMagic encabeza el archivo de sesión y versiona su formato.
+var sessionMagic = []byte("gosol-tg-session/1")
+
+const sessionSaltSize = 16
+
+// SessionStorage guarda la sesión de gotd en un archivo cifrado con AES-GCM. La clave se
+// deriva de la passphrase con scrypt y una sal nueva en cada escritura.
+// Implementa session.Storage.
+type SessionStorage struct {
+	path       string
+	passphrase string
+
+	mu sync.Mutex
+}
+
+func NewSessionStorage(path, passphrase string) *SessionStorage {
+	return &SessionStorage{path: path, passphrase: passphrase}
+}
+
+// LoadSession devuelve la sesión descifrada o session.ErrNotFound si todavía no hay.
+func (s *SessionStorage) LoadSession(_ context.Context) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, session.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading telegram session: %w", err)
+	}
+	if !bytes.HasPrefix(raw, sessionMagic) || len(raw) < len(sessionMagic)+sessionSaltSize {
+		return nil, ErrSessionKey
+	}
+	raw = raw[len(sessionMagic):]
+	gcm, err := s.cipher(raw[:sessionSaltSize])
+	if err != nil {
+		return nil, err
+	}
+	raw = raw[sessionSaltSize:]
+	if len(raw) < gcm.NonceSize() {
+		return nil, ErrSessionKey
+	}
+	data, err := gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], sessionMagic)
+	if err != nil {
+		return nil, ErrSessionKey
+	}
+	return data, nil
+}
+
+// StoreSession cifra y reemplaza la sesión guardada (escribe aparte y renombra, para no
+// dejar un archivo a medias).
+func (s *SessionStorage) StoreSession(_ context.Context, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	salt := make([]byte, sessionSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return err
+	}
+	gcm, err := s.cipher(salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	out := append(append(append([]byte(nil), sessionMagic...), salt...), nonce...)
+	out = gcm.Seal(out, nonce, data, sessionMagic)
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, out, 0o600); err != nil {
+		return fmt.Errorf("writing telegram session: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("writing telegram session: %w", err)
+	}
+	return nil
+}
+
+// Exists indica si ya hay una sesión guardada.
+func (s *SessionStorage) Exists() bool {
+	_, err := os.Stat(s.path)
+	return err == nil
+}
+
+func (s *SessionStorage) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(s.passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,48 @@
+package telegramadapter_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gosol/telegramadapter"
+
+	"github.com/gotd/td/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionStorageEncryptsSession(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "telegram.session")
+	storage := telegramadapter.NewSessionStorage(path, "a very secret passphrase")
+
+	assert.False(t, storage.Exists())
+	_, err := storage.LoadSession(ctx)
+	assert.ErrorIs(t, err, session.ErrNotFound)
+
+	data := []byte(`{"Version":1,"Data":{"AuthKey":"secret-auth-key"}}`)
+	require.NoError(t, storage.StoreSession(ctx, data))
+	assert.True(t, storage.Exists())
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "secret-auth-key")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// se reutiliza al arrancar
+	loaded, err := telegramadapter.NewSessionStorage(path, "a very secret passphrase").LoadSession(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, data, loaded)
+
+	_, err = telegramadapter.NewSessionStorage(path, "another passphrase!!").LoadSession(ctx)
+	assert.ErrorIs(t, err, telegramadapter.ErrSessionKey)
+
+	raw[len(raw)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(path, raw, 0o600))
+	_, err = storage.LoadSession(ctx)
+	assert.ErrorIs(t, err, telegramadapter.ErrSessionKey)
+}
@@ -2,126 +2,171 @@ package telegramadapter
 
 import (
 	"context"
-	"log"
-	"os"
-	"regexp"
-	"strconv"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"gosol/config"
 	"gosol/monitor"
+	"gosol/types"
 
 	"github.com/gotd/td/telegram"
 	"github.com/gotd/td/tg"
-	"github.com/joho/godotenv"
 )
 
 type TelegramClient struct {
+	cfg     config.TelegramConfig
 	monitor *monitor.App
+	// sources son los canales seguidos por su id en MTProto (ver config.PeerID).
+	sources map[int64]source
 }
 
-func NewTelegramClient(monitor *monitor.App) *TelegramClient {
-	return &TelegramClient{
-		monitor: monitor,
-	}
+// source es un canal o grupo seguido con su parser.
+type source struct {
+	config.TelegramChannelConfig
+	parser Parser
 }
 
-func (t *TelegramClient) Run() {
-	// Cargar variables de entorno
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
-
-	apiID := os.Getenv("API_ID")
-	apiIDInt, err := strconv.Atoi(apiID)
+func NewTelegramClient(monitor *monitor.App, cfg config.TelegramConfig) (*TelegramClient, error) {
+	parsers, err := NewParserRegistry(cfg.ParserDefinitions())
 	if err != nil {
-		log.Fatal("Error converting API_ID to int:", err)
+		return nil, err
 	}
-	apiHash := os.Getenv("API_HASH")
-	tchannelID := os.Getenv("TELEGRAM_CHANNEL_ID")
-	channelID, err := strconv.Atoi(tchannelID)
-	if err != nil {
-		log.Fatal("Error converting API_ID to int:", err)
+	sources := make(map[int64]source)
+	for _, ch := range cfg.Sources() {
+		parser, ok := parsers.Get(ch.Parser)
+		if !ok {
+			return nil, fmt.Errorf("telegram channel %s: unknown parser %q", ch.Label, ch.Parser)
+		}
+		sources[config.PeerID(ch.ID)] = source{TelegramChannelConfig: ch, parser: parser}
 	}
+	return &TelegramClient{
+		cfg:     cfg,
+		monitor: monitor,
+		sources: sources,
+	}, nil
+}
 
-	// Crear cliente de Telegram
-	client := telegram.NewClient(apiIDInt, apiHash, telegram.Options{})
-
-	// Conectar al cliente
-	if err := client.Run(context.Background(), func(ctx context.Context) error {
-
-		t.monitor.StatusUpdates <- monitor.StatusMessage{Level: monitor.INFO, Message: "Connected to Telegram"}
-
-		dispatcher := tg.NewUpdateDispatcher()
-
-		dispatcher.OnNewChannelMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateNewChannelMessage) error {
-			t.monitor.StatusUpdates <- monitor.StatusMessage{Level: monitor.INFO, Message: "New message received"}
+// Run se conecta con la sesión guardada por `gosol telegram login` y procesa los mensajes del
+// canal hasta que se cancele ctx. Si no hay sesión o fue revocada lo informa y termina.
+func (t *TelegramClient) Run(ctx context.Context) {
+	if err := t.run(ctx); err != nil && ctx.Err() == nil {
+		t.monitor.StatusUpdates <- monitor.StatusMessage{Level: monitor.ERR, Message: err.Error()}
+	}
+}
 
-			msg, ok := update.Message.(*tg.Message)
-			if !ok {
-				return nil
-			}
+func (t *TelegramClient) run(ctx context.Context) error {
+	storage := NewSessionStorage(t.cfg.SessionFile, t.cfg.SessionKey)
+	if !storage.Exists() {
+		return ErrNotLoggedIn
+	}
 
-			t.monitor.StatusUpdates <- monitor.StatusMessage{Level: monitor.INFO, Message: msg.Message[:10]}
+	dispatcher := tg.NewUpdateDispatcher()
+	dispatcher.OnNewChannelMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateNewChannelMessage) error {
+		msg, ok := update.Message.(*tg.Message)
+		if !ok {
+			return nil
+		}
 
-			if msg.Replies.ChannelID == int64(channelID) {
-				t.processMessage(msg)
-			}
+		if peer, ok := msg.PeerID.(*tg.PeerChannel); ok {
+			t.handleMessage(peer.ChannelID, msg.Message)
+		}
 
-			return nil // Return nil if no error occurs
-		})
+		return nil // Return nil if no error occurs
+	})
+	// los grupos comunes (no supergrupos) llegan como mensajes normales
+	dispatcher.OnNewMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateNewMessage) error {
+		msg, ok := update.Message.(*tg.Message)
+		if !ok {
+			return nil
+		}
+		if peer, ok := msg.PeerID.(*tg.PeerChat); ok {
+			t.handleMessage(peer.ChatID, msg.Message)
+		}
+		return nil
+	})
+
+	client := telegram.NewClient(t.cfg.APIID, t.cfg.APIHash, telegram.Options{
+		SessionStorage: storage,
+		UpdateHandler:  dispatcher,
+	})
+	return client.Run(ctx, func(ctx context.Context) error {
+		status, err := client.Auth().Status(ctx)
+		if err != nil {
+			return sessionError(err)
+		}
+		if !status.Authorized {
+			return ErrSessionRevoked
+		}
+		t.monitor.StatusUpdates <- monitor.StatusMessage{Level: monitor.INFO, Message: "Connected to Telegram as " + displayName(status.User)}
 
 		// Mantener la ejecución
 		<-ctx.Done()
-		return nil
-	}); err != nil {
-		log.Fatal(err)
-	}
+		return ctx.Err()
+	})
 }
 
-func (t *TelegramClient) processMessage(msg *tg.Message) {
-	// Filtrar mensajes que contienen "Platform: Raydium || Pump Fun"
-	if containsPlatformKeyword(msg.Message) {
-		// Extraer dirección del token
-		token := extractToken(msg.Message)
-		if token != "" {
-			t.monitor.StatusUpdates <- monitor.StatusMessage{Level: monitor.INFO, Message: "New Token Found: " + token}
-			// Enviar token al StateManager
-			t.monitor.StateManager.AddMint(token)
-		}
+func (t *TelegramClient) handleMessage(peerID int64, text string) {
+	if src, ok := t.sources[peerID]; ok {
+		t.processMessage(src, text)
 	}
 }
 
-func containsPlatformKeyword(message string) bool {
-	// Obtener el valor de la variable de entorno PLATFORM_KEYWORD
-	platformKeyword := os.Getenv("PLATFORM_KEYWORD")
-	if platformKeyword == "" {
-		// Valor por defecto si la variable de entorno no está configurada
-		platformKeyword = "Raydium"
+// processMessage registra la mención del token en el canal y, si es la primera vez que se
+// ve, lo agrega como detectado y pide su reporte.
+func (t *TelegramClient) processMessage(src source, text string) {
+	parsed, ok := src.parser.Parse(text)
+	if !ok || !matchesPlatform(parsed.Platform, t.cfg.PlatformKeyword) {
+		return
 	}
 
-	// Asegurarse de que el valor de platformKeyword siempre comience con "Platform: "
-	fullKeyword := "Platform: " + platformKeyword
-
-	// Verificar si el mensaje contiene el valor de fullKeyword
-	return regexp.MustCompile(regexp.QuoteMeta(fullKeyword)).MatchString(message)
+	// que sea nuevo lo decide el StateManager, que no olvida los mints; el deduper solo lo
+	// registra para que un pool del mismo mint que llegue enseguida no pida otro reporte
+	t.monitor.Dedupe.FirstMint(parsed.Mint)
+	first := t.monitor.StateManager.AddMint(parsed.Mint)
+	if first {
+		t.monitor.StatusUpdates <- monitor.StatusMessage{Level: monitor.INFO, Message: fmt.Sprintf("New Token Found in %s: %s", src.Label, describe(parsed))}
+	}
+	mention := types.Mention{Source: src.Label, Weight: src.Weight, At: time.Now()}
+	if !t.monitor.StateManager.RecordMention(parsed.Mint, mention) {
+		return
+	}
+	if first {
+		t.monitor.ApiClient.FetchAndProcessReport(parsed.Mint)
+		return
+	}
+	if mentions := t.monitor.StateManager.Mentions(parsed.Mint); len(mentions) > 1 {
+		t.monitor.StatusUpdates <- monitor.StatusMessage{Level: monitor.INFO, Message: fmt.Sprintf("%s also seen in %s (%d channels)", parsed.Mint, src.Label, len(mentions))}
+	}
+	t.monitor.StateManager.SendTokenUpdates(t.monitor.TokenUpdates)
 }
 
-func extractToken(message string) string {
-	// Verificar si el mensaje contiene "Platform: Raydium"
-	if !containsPlatformKeyword(message) {
-		return ""
+// matchesPlatform filtra por plataforma (p. ej. "Raydium" acepta "Raydium || Pump Fun"). Si
+// el parser no la extrae, o no hay filtro, se acepta.
+func matchesPlatform(platform, keyword string) bool {
+	if platform == "" || keyword == "" {
+		return true
 	}
+	return strings.Contains(strings.ToLower(platform), strings.ToLower(keyword))
+}
 
-	// Dividir el mensaje en partes usando "Base: " como separador
-	parts := regexp.MustCompile(`Base: `).Split(message, 2)
-	if len(parts) > 1 {
-		// Extraer dirección antes de "\nQuote:"
-		addressParts := regexp.MustCompile(`\nQuote:`).Split(parts[1], 2)
-		if len(addressParts) > 0 {
-			address := addressParts[0]
-			return address
-		}
+// describe arma el resumen del mensaje para la barra de estado.
+func describe(msg ParsedMessage) string {
+	parts := []string{msg.Mint}
+	if msg.Platform != "" {
+		parts = append(parts, "on "+msg.Platform)
 	}
-
-	return ""
+	if msg.Pool != "" {
+		parts = append(parts, "pool "+msg.Pool)
+	}
+	metrics := make([]string, 0, len(msg.Metrics))
+	for name, value := range msg.Metrics {
+		metrics = append(metrics, name+"="+value)
+	}
+	slices.Sort(metrics)
+	if len(metrics) > 0 {
+		parts = append(parts, "("+strings.Join(metrics, ", ")+")")
+	}
+	return strings.Join(parts, " ")
 }
@@ -0,0 +1,60 @@
+package telegramadapter_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gosol/config"
+	"gosol/monitor"
+	"gosol/telegramadapter"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegramClientReportsAMintOnlyTheFirstTimeItIsSeen(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.Default()
+	cfg.Solana.RayFeePubkey = solana.NewWallet().PublicKey().String()
+	cfg.RPC.Endpoints = []config.RPCEndpointConfig{{Name: "local", URL: "http://127.0.0.1:1"}}
+	cfg.Storage.Enabled = false
+	// el deduper olvida el mint enseguida: no debe bastar para darlo por nuevo
+	cfg.Dedupe.Mints.TTL = time.Millisecond
+	app, err := monitor.NewApp(cfg)
+	require.NoError(t, err)
+	defer app.Cancel()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-app.StatusUpdates:
+			}
+		}
+	}()
+
+	cfg.Telegram.Parser = config.ParserBase58
+	cfg.Telegram.Channels = []config.TelegramChannelConfig{
+		{ID: -1001234567890, Label: "alpha"},
+		{ID: -1009876543210, Label: "beta"},
+	}
+	client, err := telegramadapter.NewTelegramClient(app, cfg.Telegram)
+	require.NoError(t, err)
+
+	mint := solana.NewWallet().PublicKey().String()
+	client.HandleMessage(1234567890, "🚀 "+mint)
+	time.Sleep(10 * time.Millisecond)
+	client.HandleMessage(9876543210, "again "+mint)
+	client.HandleMessage(1234567890, "and again "+mint)
+
+	depth, _, _, _ := app.ApiClient.QueueStats()
+	assert.Equal(t, 1, depth, "the report is requested once")
+	mentions := app.StateManager.Mentions(mint)
+	require.Len(t, mentions, 2)
+	assert.Equal(t, "alpha", mentions[0].Source)
+	assert.Equal(t, "beta", mentions[1].Source)
+}
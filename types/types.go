@@ -7,6 +7,18 @@ type TokenInfo struct {
 	Address   string
 	CreatedAt string
 	Score     int64
+	// Verdict y Color salen de evaluar el reporte con las reglas de scoring.
+	Verdict string
+	Color   string
+	// Curve es el avance de la bonding curve de pump.fun ("" si no se lanzó ahí).
+	Curve string
+	// DEX es donde se detectó el pool del token ("pump.fun" si solo se vio su curva).
+	DEX string
+	// Commitment es el nivel que alcanzó la transacción que creó el pool.
+	Commitment string
+	// Mentions es cuántos canales nombraron el token y FirstMention cuándo ("15:04").
+	Mentions     int
+	FirstMention string
 }
 
 type TokenMeta struct {
@@ -47,6 +59,8 @@ type Report struct {
 	MintAuthority        string        `json:"mintAuthority"`
 	TopHolders           []Holder      `json:"topHolders"`
 	DetectedAt           time.Time     `json:"detectedAt"`
+	// Source es el proveedor que generó el reporte (o varios separados por "+").
+	Source string `json:"source,omitempty"`
 }
 
 type MintInfo struct {
@@ -55,7 +69,68 @@ type MintInfo struct {
 }
 
 type Risk struct {
-	Name  string
-	Score int64
-	Level string
+	Name   string
+	Score  int64
+	Level  string
+	Source string `json:",omitempty"`
+}
+
+// PoolCreated es la creación de un pool de liquidez decodificada de una transacción.
+// Base es el token "coin" del pool y Quote el "pc" (normalmente SOL o un stable).
+type PoolCreated struct {
+	DEX             string    `json:"dex"`
+	Signature       string    `json:"signature"`
+	Slot            uint64    `json:"slot"`
+	BlockTime       time.Time `json:"blockTime"`
+	Pool            string    `json:"pool"`
+	BaseMint        string    `json:"baseMint"`
+	QuoteMint       string    `json:"quoteMint"`
+	LPMint          string    `json:"lpMint,omitempty"`
+	BaseVault       string    `json:"baseVault"`
+	QuoteVault      string    `json:"quoteVault"`
+	Market          string    `json:"market,omitempty"`
+	Creator         string    `json:"creator,omitempty"`
+	OpenTime        time.Time `json:"openTime"`
+	InitBaseAmount  uint64    `json:"initBaseAmount"`
+	InitQuoteAmount uint64    `json:"initQuoteAmount"`
+	// Commitment es hasta dónde se confirmó la transacción (o "dropped" si se perdió).
+	Commitment string `json:"commitment,omitempty"`
+}
+
+// quoteMints son los mints que se usan como contraparte de un pool y no son el token nuevo.
+var quoteMints = map[string]bool{
+	"So11111111111111111111111111111111111111112":  true, // wrapped SOL
+	"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v": true, // USDC
+	"Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB": true, // USDT
+}
+
+// Token devuelve el mint nuevo del pool: el lado que no es SOL ni un stable.
+func (p PoolCreated) Token() string {
+	if quoteMints[p.BaseMint] && !quoteMints[p.QuoteMint] {
+		return p.QuoteMint
+	}
+	return p.BaseMint
+}
+
+// Mention es la primera vez que una fuente (un canal de Telegram) nombró un mint.
+type Mention struct {
+	Source string    `json:"source"`
+	Weight float64   `json:"weight"`
+	At     time.Time `json:"at"`
+}
+
+// BondingCurve es el seguimiento de un token lanzado en pump.fun hasta que completa su curva.
+type BondingCurve struct {
+	Mint         string    `json:"mint"`
+	BondingCurve string    `json:"bondingCurve"`
+	Name         string    `json:"name"`
+	Symbol       string    `json:"symbol"`
+	URI          string    `json:"uri"`
+	Creator      string    `json:"creator"`
+	CreatedAt    time.Time `json:"createdAt"`
+	// Progress es el avance de la curva (0 a 100) según el último trade visto.
+	Progress    float64   `json:"progress"`
+	Complete    bool      `json:"complete"`
+	CompletedAt time.Time `json:"completedAt,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt"`
 }
@@ -25,7 +25,7 @@ func NewStatusListModel(messages []monitor.StatusMessage) StatusListModel {
 	// s.Spinner = spinner.Dot
 	// s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 
-	l := list.New(items, list.NewDefaultDelegate(), 180, 1) // Ajusta el tamaño según sea necesario
+	l := list.New(items, list.NewDefaultDelegate(), 180, 12) // Ajusta el tamaño según sea necesario
 	l.Title = "StatusMessages:"
 	l.SetShowTitle(true)
 	l.SetShowStatusBar(false)
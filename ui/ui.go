@@ -2,12 +2,17 @@ package ui
 
 import (
 	"fmt"
+	"gosol/config"
 	"gosol/monitor"
+	"gosol/rules"
+	"gosol/storage"
 	"gosol/types"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
@@ -23,26 +28,55 @@ var helpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render
 
 type TokenUpdateMsg []types.TokenInfo
 type StatusBarUpdateMsg monitor.StatusMessage
+type statsTickMsg time.Time
+
+// statsRefreshInterval es cada cuánto se refrescan las métricas de la barra de estado.
+const statsRefreshInterval = time.Second
+
+// statusHistorySize es la cantidad de mensajes de estado que se muestran.
+const statusHistorySize = 10
 
 type Model struct {
 	activeView int
 	table      table.Model
+	tokens     []types.TokenInfo
 	// statusBar      string
-	statusBar      StatusListModel
-	selectedToken  *types.Report
-	currentMonitor *monitor.Monitor
+	statusBar     StatusListModel
+	statusHistory []monitor.StatusMessage
+	selectedToken *types.Report
+	stats         monitor.Stats
+	app           *monitor.App
 }
 
-func NewModel(tokens []types.TokenInfo) Model {
+func NewModel(app *monitor.App) Model {
 	columns := []table.Column{
 		{Title: "", Width: 2},
 		{Title: "CREATED AT", Width: 10},
 		{Title: "SYMBOL", Width: 10},
 		{Title: "SCORE", Width: 10},
+		{Title: "CURVE", Width: 6},
+		{Title: "DEX", Width: 15},
+		{Title: "COMMITMENT", Width: 12},
+		{Title: "SEEN IN", Width: 14},
 		{Title: "ADDRESS", Width: 10},
 		// {Title: "URL", Width: 100},
 	}
 
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows([]table.Row{}),
+		table.WithFocused(true),
+	)
+
+	return Model{
+		app:        app,
+		table:      t,
+		statusBar:  NewStatusListModel(nil),
+		activeView: 1,
+	}
+}
+
+func buildRows(tokens []types.TokenInfo) []table.Row {
 	rows := []table.Row{}
 	for _, token := range tokens {
 		if token.Address == "" && token.Symbol == "" && token.CreatedAt == "" && token.Score == 0 {
@@ -50,56 +84,33 @@ func NewModel(tokens []types.TokenInfo) Model {
 		}
 		address := token.Address[:7] + "..."
 		// url := fmt.Sprintf("https://rugcheck.xyz/tokens/%s", token.Address)
-		scoreColor := "🟢" // Green
-		if token.Score > 2000 {
-			scoreColor = "🟡" // Yellow
-		}
-		if token.Score > 3000 {
-			scoreColor = "🟠" // Yellow
-		}
-		if token.Score > 4000 {
-			scoreColor = "🔴" // Red
-		}
 		row := table.Row{
-			scoreColor,
+			token.Color,
 			token.CreatedAt,
 			token.Symbol,
 			fmt.Sprintf("%d", token.Score),
+			token.Curve,
+			token.DEX,
+			formatCommitment(token.Commitment),
+			formatSeen(token),
 			address,
 			// url,
 		}
 		rows = append(rows, row)
 	}
-
-	t := table.New(
-		table.WithColumns(columns),
-		table.WithRows(rows),
-		table.WithFocused(true),
-	)
-
-	return Model{table: t}
+	return rows
 }
 
-func InitProject(monitor *monitor.Monitor) (tea.Model, tea.Cmd) {
-	// Inicializar el modelo de la tabla con tokens vacíos
-	m := NewModel([]types.TokenInfo{})
-	m.currentMonitor = monitor
-	m.activeView = 1
-
-	// Obtener el historial de mensajes de estado y crear el modelo de lista
-	messages := monitor.GetStatusHistory()
-	m.statusBar = NewStatusListModel(messages)
-
-	// Comando inicial para Bubble Tea, si es necesario
-	cmd := tea.Batch(
-	// Aquí puedes agregar comandos iniciales si los necesitas
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(
+		m.listenOnStatusUpdates(m.app.StatusUpdates),
+		m.listenOnTokenUpdates(m.app.TokenUpdates),
+		tickStats(),
 	)
-
-	return m, cmd
 }
 
-func (m Model) Init() tea.Cmd {
-	return nil
+func tickStats() tea.Cmd {
+	return tea.Tick(statsRefreshInterval, func(t time.Time) tea.Msg { return statsTickMsg(t) })
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -131,18 +142,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "enter":
 			// Obtener el token seleccionado de la tabla
-			selectedRow := m.table.SelectedRow()
-			if selectedRow != nil {
-				// Asume que el primer campo es el símbolo del token
-				symbol := selectedRow[2]
-
-				mintState := m.currentMonitor.GetMintState()
-
-				for _, row := range mintState {
-					if row[0].TokenMeta.Symbol == symbol { // Accede al índice correcto
-						m.selectedToken = &row[0]
-						break
-					}
+			if token, ok := m.selectedTokenInfo(); ok {
+				if report, found := m.app.StateManager.GetReport(token.Address); found {
+					m.selectedToken = &report
+					// pedir un reporte actualizado
+					m.app.ApiClient.RequestReportOnDemand(token.Address)
 				}
 			}
 		case "esc":
@@ -152,16 +156,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case TokenUpdateMsg:
 		// m.statusBar.list.NewStatusMessage("Received token update for: " + msg[0].Symbol)
 		m.updateTokenTable(msg)
-		// case StatusBarUpdateMsg:
-		// 	messages := m.stateManager.GetStatusHistory()
-		// 	// Limitar los mensajes a los últimos 10
-		// 	if len(messages) > 10 {
-		// 		messages = messages[len(messages)-10:]
-		// 	}
-		// 	items := make([]list.Item, len(messages))
-		// 	for i, msg := range messages {
-		// 		items[i] = listItem{message: msg}
-		// 	}
+		// refrescar el detalle abierto con el reporte más reciente
+		if m.selectedToken != nil {
+			if report, found := m.app.StateManager.GetReport(m.selectedToken.Mint); found {
+				m.selectedToken = &report
+			}
+		}
+		cmds = append(cmds, m.listenOnTokenUpdates(m.app.TokenUpdates))
+	case statsTickMsg:
+		m.stats = m.app.Stats()
+		cmds = append(cmds, tickStats())
+	case StatusBarUpdateMsg:
+		m.statusHistory = append(m.statusHistory, monitor.StatusMessage(msg))
+		// Limitar los mensajes a los últimos 10
+		if len(m.statusHistory) > statusHistorySize {
+			m.statusHistory = m.statusHistory[len(m.statusHistory)-statusHistorySize:]
+		}
+		items := make([]list.Item, len(m.statusHistory))
+		for i, msg := range m.statusHistory {
+			items[i] = listItem{message: msg}
+		}
 
 		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
 			items[i], items[j] = items[j], items[i]
@@ -169,6 +183,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Actualizar el modelo de la lista con los nuevos elementos
 		m.statusBar.list.SetItems(items)
+		cmds = append(cmds, m.listenOnStatusUpdates(m.app.StatusUpdates))
 	}
 
 	// Actualizar el spinner
@@ -179,15 +194,31 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// spinnerCmd := m.statusBar.spinner.Tick
 	// cmds = append(cmds, spinnerCmd)
 
-	// Escuchar en los canales y enviar mensajes recibidos al modelo
-	cmds = append(cmds,
-		m.listenOnStatusUpdates(m.statusUpdates),
-		m.listenOnTokenUpdates(m.tokenUpdates),
-	)
-
 	return m, tea.Batch(cmds...)
 }
 
+func (m *Model) updateTokenTable(tokens []types.TokenInfo) {
+	m.tokens = tokens
+	m.table.SetRows(buildRows(tokens))
+}
+
+// selectedTokenInfo devuelve el token de la fila seleccionada. Las filas se arman en el
+// mismo orden que m.tokens (salteando las vacías), así que se recorre con el mismo criterio.
+func (m Model) selectedTokenInfo() (types.TokenInfo, bool) {
+	cursor := m.table.Cursor()
+	row := 0
+	for _, token := range m.tokens {
+		if token.Address == "" {
+			continue
+		}
+		if row == cursor {
+			return token, true
+		}
+		row++
+	}
+	return types.TokenInfo{}, false
+}
+
 // esto envia un StatusMessage al Update verificar que lo reciba correctamente y ejecutar el Update
 func (m Model) listenOnStatusUpdates(ch <-chan monitor.StatusMessage) tea.Cmd {
 	return func() tea.Msg {
@@ -196,7 +227,7 @@ func (m Model) listenOnStatusUpdates(ch <-chan monitor.StatusMessage) tea.Cmd {
 			m.statusBar.list.NewStatusMessage("Status update channel closed")
 			return nil
 		}
-		return msg
+		return StatusBarUpdateMsg(msg)
 	}
 }
 
@@ -208,7 +239,6 @@ func (m Model) listenOnTokenUpdates(ch <-chan []types.TokenInfo) tea.Cmd {
 			m.statusBar.list.NewStatusMessage("Token update channel closed")
 			return nil
 		}
-		m.statusBar.list.NewStatusMessage("Received token update for: " + tokens[0].Symbol)
 		return TokenUpdateMsg(tokens)
 	}
 }
@@ -227,23 +257,104 @@ func (m Model) View() string {
 		tableView = inactiveBorderStyle.Render(m.table.View())
 		statusBarView = activeBorderStyle.Render(m.statusBar.View())
 	}
-	return fmt.Sprintf("\n%s\n%s", statusBarView, tableView)
+	return fmt.Sprintf("\n%s\n%s\n%s", statusBarView, tableView, helpStyle(formatStats(m.stats)))
+}
+
+var connectionIcons = map[monitor.ConnectionState]string{
+	monitor.ConnectionConnecting: "🔄",
+	monitor.ConnectionLive:       "🟢",
+	monitor.ConnectionDegraded:   "🟡",
+	monitor.ConnectionDown:       "🔴",
+}
+
+func formatIngest(name string, stats monitor.IngestStats) string {
+	line := fmt.Sprintf("%s: %d/%d lag %s", name, stats.Depth, stats.Capacity, stats.Lag.Round(time.Millisecond))
+	if stats.Spilled > 0 {
+		line += fmt.Sprintf(" +%d on disk", stats.Spilled)
+	}
+	if stats.Dropped > 0 {
+		line += fmt.Sprintf(" dropped %d", stats.Dropped)
+	}
+	return line
+}
+
+func formatCommitment(commitment string) string {
+	switch commitment {
+	case config.CommitmentFinalized:
+		return "✅ " + commitment
+	case config.CommitmentDropped:
+		return "⚠️ " + commitment
+	}
+	return commitment
+}
+
+// formatSeen resume las menciones en Telegram: "3 ch · 14:02" (cuántos canales y el primero).
+func formatSeen(token types.TokenInfo) string {
+	if token.Mentions == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d ch · %s", token.Mentions, token.FirstMention)
+}
+
+func formatTransactions(stats monitor.TransactionStats) string {
+	line := fmt.Sprintf("tx: %d/%d busy %d avg %s", stats.Depth, stats.Capacity, stats.Busy, stats.AvgLatency.Round(time.Millisecond))
+	reasons := make([]string, 0, len(stats.Failures))
+	for reason := range stats.Failures {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		line += fmt.Sprintf(" %s %d", reason, stats.Failures[reason])
+	}
+	return line
+}
+
+func formatStats(stats monitor.Stats) string {
+	line := fmt.Sprintf("ws: %s %s", connectionIcons[stats.Connection], stats.Connection)
+	if stats.Connection != monitor.ConnectionLive && !stats.LastMessage.IsZero() {
+		line += fmt.Sprintf(" (last message %s ago)", time.Since(stats.LastMessage).Round(time.Second))
+	}
+	if stats.Reconnects > 0 {
+		line += fmt.Sprintf(" · reconnects: %d", stats.Reconnects)
+	}
+	line += " · " + formatIngest("ingest", stats.Ingest)
+	if stats.SlotLag > 0 {
+		line += fmt.Sprintf(" (%d slots behind)", stats.SlotLag)
+	}
+	if stats.PumpFunIngest.Capacity > 0 {
+		line += " · " + formatIngest("pump.fun", stats.PumpFunIngest)
+	}
+	line += " · " + formatTransactions(stats.Transactions)
+	line += fmt.Sprintf(" · tracking: %d pending", stats.Tracking.Pending)
+	if stats.Tracking.Dropped > 0 {
+		line += fmt.Sprintf(" / %d dropped", stats.Tracking.Dropped)
+	}
+	line += fmt.Sprintf(" · report queue: %d/%d · fetching: %d · tracked: %d",
+		stats.ReportQueueDepth, stats.ReportQueueCap, stats.ReportWorkersBusy, stats.TrackedMints)
+	if stats.ReportsDropped > 0 {
+		line += fmt.Sprintf(" · dropped: %d", stats.ReportsDropped)
+	}
+	if stats.DuplicateSignatures > 0 || stats.DuplicateMints > 0 {
+		line += fmt.Sprintf(" · duplicates: %d tx / %d mints", stats.DuplicateSignatures, stats.DuplicateMints)
+	}
+	return line
 }
 
 func (m Model) tokenDetailView() string {
 	if m.selectedToken == nil {
 		return ""
 	}
-	if m.currentMonitor == nil {
+	if m.app == nil {
 		return "Error: Monitor not initialized."
 	}
 
-	// request update
-	go m.currentMonitor.CheckMintAddress(m.selectedToken.Mint)
-
-	time.Sleep(1 * time.Second)
-
-	markdownContent := formatReportAsMarkdown(*m.selectedToken)
+	markdownContent := formatReportAsMarkdown(*m.selectedToken) +
+		formatScoring(m.app.StateManager.Evaluate(*m.selectedToken)) +
+		formatPool(m.app.StateManager.Pool(m.selectedToken.Mint)) +
+		formatCurve(m.app.StateManager.Curve(m.selectedToken.Mint)) +
+		formatMentions(m.app.StateManager.Mentions(m.selectedToken.Mint)) +
+		formatTrend(m.app.StateManager.Deltas(m.selectedToken.Mint)) +
+		formatStatusHistory(m.app.StateManager.StatusHistory(m.selectedToken.Mint))
 
 	// Usar glamour para renderizar el Markdown
 	renderedContent, err := glamour.Render(markdownContent, "dark")
@@ -257,7 +368,11 @@ func (m Model) tokenDetailView() string {
 func formatReportAsMarkdown(report types.Report) string {
 	var risks []string
 	for _, risk := range report.Risks {
-		risks = append(risks, fmt.Sprintf("- **%s**: %s (Score: %d)", risk.Name, risk.Level, risk.Score))
+		line := fmt.Sprintf("- **%s**: %s (Score: %d)", risk.Name, risk.Level, risk.Score)
+		if risk.Source != "" {
+			line += fmt.Sprintf(" _[%s]_", risk.Source)
+		}
+		risks = append(risks, line)
 	}
 
 	return fmt.Sprintf(`                                                                                                                                                 
@@ -268,6 +383,7 @@ func formatReportAsMarkdown(report types.Report) string {
 **Score**: %d                                                                                                                                                            
 **Rugged**: %t                                                                                                                                                           
 **Verification**: %s                                                                                                                                                     
+**Source**: %s                                                                                                                                                           
 																																										 
 ## Known Accounts                                                                                                                                                        
 %s                                                                                                                                                                       
@@ -284,12 +400,69 @@ func formatReportAsMarkdown(report types.Report) string {
 		report.Score,
 		report.Rugged,
 		report.Verification,
+		report.Source,
 		formatKnownAccounts(report.KnownAccounts),
 		strings.Join(risks, "\n"),
 		formatTopHolders(report.TopHolders),
 	)
 }
 
+func formatScoring(result rules.Result) string {
+	lines := []string{
+		"\n## Scoring",
+		fmt.Sprintf("**Verdict**: %s %s (score %d)", result.Color, result.Verdict, result.Score),
+	}
+	for _, name := range result.Matched {
+		lines = append(lines, "- "+name)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func formatPool(pool types.PoolCreated, ok bool) string {
+	if !ok {
+		return ""
+	}
+	lines := []string{
+		"\n## Pool",
+		fmt.Sprintf("**DEX**: %s", pool.DEX),
+		fmt.Sprintf("**Pool**: %s", pool.Pool),
+		fmt.Sprintf("**Base**: %s (initial %d)", pool.BaseMint, pool.InitBaseAmount),
+		fmt.Sprintf("**Quote**: %s (initial %d)", pool.QuoteMint, pool.InitQuoteAmount),
+		fmt.Sprintf("**Open time**: %s", pool.OpenTime.In(time.Local).Format("2006-01-02 15:04:05")),
+	}
+	if pool.LPMint != "" {
+		lines = append(lines, fmt.Sprintf("**LP mint**: %s", pool.LPMint))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func formatCurve(curve types.BondingCurve, ok bool) string {
+	if !ok {
+		return ""
+	}
+	lines := []string{
+		"\n## pump.fun",
+		fmt.Sprintf("**Bonding curve**: %s", curve.BondingCurve),
+		fmt.Sprintf("**Creator**: %s", curve.Creator),
+		fmt.Sprintf("**Progress**: %.1f%%", curve.Progress),
+	}
+	if curve.Complete {
+		lines = append(lines, fmt.Sprintf("**Completed at**: %s", curve.CompletedAt.In(time.Local).Format("15:04:05")))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func formatMentions(mentions []types.Mention) string {
+	if len(mentions) == 0 {
+		return ""
+	}
+	lines := []string{fmt.Sprintf("\n## Seen in %d channels, first at %s", len(mentions), mentions[0].At.In(time.Local).Format("15:04"))}
+	for _, mention := range mentions {
+		lines = append(lines, fmt.Sprintf("- %s **%s** (weight %g)", mention.At.In(time.Local).Format("15:04:05"), mention.Source, mention.Weight))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
 func formatKnownAccounts(accounts types.KnownAccounts) string {
 	var result []string
 	for address, account := range accounts {
@@ -306,6 +479,35 @@ func formatTopHolders(holders []types.Holder) string {
 	return strings.Join(result, "\n")
 }
 
+func formatTrend(deltas []monitor.ReportDelta) string {
+	result := []string{"\n## Trend"}
+	for _, delta := range deltas {
+		if !delta.Changed() {
+			continue
+		}
+		result = append(result, fmt.Sprintf("- %s %s", delta.To.In(time.Local).Format("15:04:05"), delta))
+	}
+	if len(result) == 1 {
+		return ""
+	}
+	return strings.Join(result, "\n")
+}
+
+func formatStatusHistory(history []storage.StatusRecord) string {
+	if len(history) == 0 {
+		return ""
+	}
+	result := []string{"\n## History"}
+	for _, rec := range history {
+		line := fmt.Sprintf("- %s **%s**", rec.At.In(time.Local).Format("01/02 15:04:05"), rec.Status)
+		if rec.Detail != "" {
+			line += ": " + rec.Detail
+		}
+		result = append(result, line)
+	}
+	return strings.Join(result, "\n")
+}
+
 // func formatStatusBar(msg StatusBarUpdateMsg) string {
 // 	var color lipgloss.Color
 // 	switch msg.Level {